@@ -13,10 +13,18 @@ import (
 	"gorm.io/gorm/schema"
 
 	"portolio-backend/configs"
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/jobs"
+	"portolio-backend/internal/mailer"
 	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/notify"
 	"portolio-backend/internal/routes"
+	"portolio-backend/internal/scanner"
+	"portolio-backend/internal/storage"
 	"portolio-backend/internal/seed"
 	"portolio-backend/internal/util"
+	"portolio-backend/internal/webhook"
+	"portolio-backend/internal/webpush"
 )
 
 func main() {
@@ -68,6 +76,29 @@ func main() {
 		&db.SupportMessage{},
 		&db.AdminSession{},
 		&db.AdminLog{},
+		&db.ProductImportJob{},
+		&db.ProductImportError{},
+		&db.ProductPriceHistory{},
+		&db.ProductPriceSchedule{},
+		&db.Coupon{},
+		&db.CouponRedemption{},
+		&db.WishlistItem{},
+		&db.RestockSubscription{},
+		&db.StockReservation{},
+		&db.InventoryMovement{},
+		&db.ProductVisibilityLog{},
+		&db.Upload{},
+		&db.NotificationPreference{},
+		&db.NotificationSetting{},
+		&db.NotificationDigestPreference{},
+		&db.NotificationDigestItem{},
+		&db.NotificationArchive{},
+		&db.EmailOutbox{},
+		&db.OutboxEvent{},
+		&db.EventReceipt{},
+		&db.Webhook{},
+		&db.WebhookDelivery{},
+		&db.PushSubscription{},
 	)
 	if err != nil {
 		log.Fatalf("❌ Gagal melakukan auto migrate: %v", err)
@@ -83,18 +114,90 @@ func main() {
 		}
 	}
 
+	mediaStorage, err := storage.New(storage.Config{
+		Driver:           configs.GetEnv("STORAGE_DRIVER", "local"),
+		LocalRoot:        configs.GetEnv("STORAGE_LOCAL_ROOT", "."),
+		S3Endpoint:       configs.GetEnv("S3_ENDPOINT", ""),
+		S3PublicEndpoint: configs.GetEnv("S3_PUBLIC_ENDPOINT", ""),
+		S3Region:         configs.GetEnv("S3_REGION", "us-east-1"),
+		S3Bucket:         configs.GetEnv("S3_BUCKET", ""),
+		S3AccessKey:      configs.GetEnv("S3_ACCESS_KEY_ID", ""),
+		S3SecretKey:      configs.GetEnv("S3_SECRET_ACCESS_KEY", ""),
+		S3UsePathStyle:   configs.GetEnv("S3_USE_PATH_STYLE", "true") == "true",
+	})
+	if err != nil {
+		log.Fatalf("❌ Gagal menyiapkan storage media: %v", err)
+	}
+	util.MediaStorage = mediaStorage
+	log.Printf("🗄️ Storage media: %s", util.MediaStorage.Name())
+
+	util.UploadScanTimeout = configs.GetEnvDuration("SCANNER_TIMEOUT", constants.DefaultScannerTimeout)
+	util.UploadScanner = scanner.New(configs.GetEnv("SCANNER_DRIVER", "noop"), configs.GetEnv("SCANNER_ADDRESS", "tcp://127.0.0.1:3310"), util.UploadScanTimeout)
+	log.Printf("🛡️ Pemindai upload: %s", util.UploadScanner.Name())
+
+	emailMailer := mailer.New(mailer.Config{
+		Driver:       configs.GetEnv("MAIL_DRIVER", "noop"),
+		From:         configs.GetEnv("MAIL_FROM", "Portolio <no-reply@portolio.local>"),
+		SMTPHost:     configs.GetEnv("MAIL_SMTP_HOST", "localhost"),
+		SMTPPort:     configs.GetEnvInt("MAIL_SMTP_PORT", 1025),
+		SMTPUsername: configs.GetEnv("MAIL_SMTP_USERNAME", ""),
+		SMTPPassword: configs.GetEnv("MAIL_SMTP_PASSWORD", ""),
+		SMTPTLS:      configs.GetEnv("MAIL_SMTP_TLS", "none"),
+		Timeout:      configs.GetEnvDuration("MAIL_TIMEOUT", constants.DefaultMailTimeout),
+		DevDir:       configs.GetEnv("MAIL_DEV_DIR", constants.DefaultMailDevDir),
+	})
+	log.Printf("✉️ Pengirim email: %s", emailMailer.Name())
+	if emailMailer.Name() != "noop" {
+		emailDeliverer := notify.EmailDeliverer{
+			AppName: configs.GetEnv("MAIL_APP_NAME", "Portolio"),
+			AppURL:  configs.GetEnv("MAIL_APP_URL", ""),
+		}
+		notify.Register(constants.NotificationChannelEmail, emailDeliverer)
+		notify.SubscribeEmail(dbConn, emailDeliverer)
+		go jobs.RunEmailOutbox(dbConn, emailMailer, configs.GetEnvDuration("EMAIL_OUTBOX_INTERVAL", constants.DefaultEmailOutboxInterval), configs.GetEnvInt("EMAIL_MAX_ATTEMPTS", constants.DefaultEmailMaxAttempts), configs.GetEnvDuration("MAIL_TIMEOUT", constants.DefaultMailTimeout))
+	}
+
+	if vapidPublicKey := configs.GetEnv("WEBPUSH_VAPID_PUBLIC_KEY", ""); vapidPublicKey != "" {
+		sender, err := webpush.NewSender(webpush.Config{
+			VAPIDPublicKey:  vapidPublicKey,
+			VAPIDPrivateKey: configs.GetEnv("WEBPUSH_VAPID_PRIVATE_KEY", ""),
+			Subject:         configs.GetEnv("WEBPUSH_SUBJECT", "mailto:admin@portolio.local"),
+			TTL:             configs.GetEnvDuration("WEBPUSH_TTL", constants.DefaultWebPushTTL),
+			Timeout:         configs.GetEnvDuration("WEBPUSH_TIMEOUT", constants.DefaultWebPushTimeout),
+			AllowPrivate:    configs.GetEnv("WEBPUSH_ALLOW_PRIVATE", "false") == "true",
+		})
+		if err != nil {
+			log.Fatalf("❌ Konfigurasi web push tidak valid: %v", err)
+		}
+		webpush.DefaultSender = sender
+		notify.RegisterAfterCommit(constants.NotificationChannelWebPush, notify.WebPushDeliverer{Sender: sender})
+		log.Println("🔔 Web push aktif")
+	}
+	go jobs.RunPushSubscriptionPruner(dbConn, configs.GetEnvDuration("WEBPUSH_PRUNE_INTERVAL", constants.DefaultPushPruneInterval))
+
+	webhook.DefaultClient = webhook.NewClient(webhook.Config{
+		Timeout:      configs.GetEnvDuration("WEBHOOK_TIMEOUT", constants.DefaultWebhookTimeout),
+		AllowPrivate: configs.GetEnv("WEBHOOK_ALLOW_PRIVATE", "false") == "true",
+	})
+	notify.Subscribe(dbConn)
+	webhook.Subscribe(dbConn)
+	go jobs.RunWebhookDispatcher(dbConn, webhook.DefaultClient, configs.GetEnvDuration("WEBHOOK_INTERVAL", constants.DefaultWebhookInterval), configs.GetEnvInt("WEBHOOK_MAX_ATTEMPTS", constants.DefaultWebhookMaxAttempts))
+
+	go jobs.RunEventRelay(dbConn, configs.GetEnvDuration("EVENT_RELAY_INTERVAL", constants.DefaultEventRelayInterval), configs.GetEnvInt("EVENT_MAX_ATTEMPTS", constants.DefaultEventMaxAttempts))
+
 	util.WebsocketHub = util.NewHub()
 	go util.WebsocketHub.Run()
 
+	go jobs.RunPriceScheduler(dbConn, configs.GetEnvDuration("PRICE_SCHEDULER_INTERVAL", constants.DefaultPriceSchedulerInterval))
+	go jobs.RunReservationSweeper(dbConn, configs.GetEnvDuration("RESERVATION_SWEEP_INTERVAL", constants.DefaultReservationSweepInterval))
+	go jobs.RunUploadGC(dbConn, configs.GetEnvDuration("UPLOAD_GC_INTERVAL", constants.DefaultUploadGCInterval), configs.GetEnvDuration("UPLOAD_GC_GRACE_PERIOD", constants.DefaultUploadGCGracePeriod))
+	go jobs.RunImageFetcher(dbConn, configs.GetEnvDuration("IMAGE_FETCH_INTERVAL", constants.DefaultImageFetchInterval), configs.GetEnvInt("IMAGE_FETCH_WORKERS", constants.DefaultImageFetchWorkers))
+	go jobs.RunNotificationMaintenance(dbConn, configs.GetEnvDuration("NOTIFICATION_MAINTENANCE_INTERVAL", constants.DefaultNotificationMaintenanceInterval), configs.GetEnvInt("NOTIFICATION_RETENTION_DAYS", constants.DefaultNotificationRetentionDays), constants.NotificationRetentionMode(configs.GetEnv("NOTIFICATION_RETENTION_MODE", string(constants.DefaultNotificationRetentionMode))))
+
 	seed.Shop(dbConn)
 
 	routes.SetupRoutes(r, dbConn, util.WebsocketHub)
 
-	// Hapus baris ini: r.Static("/media", "./media")
-
-	// Tambahkan ini untuk akses publik ke gambar produk
-	r.Static("api/media/products", "./media/products")
-
 	port := configs.GetEnv("PORT", "8080")
 	log.Printf("🚀 Server berjalan di port :%s", port)
 	r.Run(":" + port)
@@ -1,5 +1,7 @@
 package constants
 
+import "time"
+
 type UserRole string
 const (
 	RoleAdmin UserRole = "admin"
@@ -54,6 +56,77 @@ const (
 	NotifTypeChat       NotificationType = "chat"
 	NotifTypeSupport    NotificationType = "support"
 	NotifTypeAccount    NotificationType = "account_status"
+	NotifTypeRestock    NotificationType = "restock"
+	NotifTypeLowStock   NotificationType = "low_stock"
+	NotifTypeShipment   NotificationType = "shipment"
+)
+
+// NotificationTypes berisi seluruh tipe notifikasi yang valid
+var NotificationTypes = []NotificationType{
+	NotifTypePurchase, NotifTypeSale, NotifTypeTopUp, NotifTypeWithdraw, NotifTypeChat,
+	NotifTypeSupport, NotifTypeAccount, NotifTypeRestock, NotifTypeLowStock, NotifTypeShipment,
+}
+
+type NotificationChannel string
+const (
+	NotificationChannelInApp     NotificationChannel = "in_app"
+	NotificationChannelWebsocket NotificationChannel = "websocket"
+	NotificationChannelEmail     NotificationChannel = "email"
+	NotificationChannelWebPush   NotificationChannel = "web_push"
+)
+
+// NotificationChannels berisi seluruh channel pengiriman notifikasi
+var NotificationChannels = []NotificationChannel{
+	NotificationChannelInApp, NotificationChannelWebsocket, NotificationChannelEmail, NotificationChannelWebPush,
+}
+
+
+type EventType string
+const (
+	EventPurchaseCreated       EventType = "purchase.created"
+	EventTransactionShipped    EventType = "transaction.shipped"
+	EventTransactionCompleted  EventType = "transaction.completed"
+	EventTransactionCanceled   EventType = "transaction.canceled"
+	EventUserSuspended         EventType = "user.suspended"
+	EventUserBanned            EventType = "user.banned"
+	EventUserUnbanned          EventType = "user.unbanned"
+	EventUserDeleted           EventType = "user.deleted"
+	EventSupportTicketCreated  EventType = "support_ticket.created"
+	EventSupportTicketReplied  EventType = "support_ticket.replied"
+	EventSupportTicketClaimed  EventType = "support_ticket.claimed"
+	EventSupportTicketCanceled EventType = "support_ticket.canceled"
+	EventReviewCreated         EventType = "review.created"
+	// EventWebhookTest hanya dipakai untuk uji coba webhook dan tidak ditulis ke outbox
+	EventWebhookTest EventType = "webhook.test"
+)
+
+// EventTypes berisi seluruh tipe event domain yang ditulis ke outbox
+var EventTypes = []EventType{
+	EventPurchaseCreated, EventTransactionShipped, EventTransactionCompleted, EventTransactionCanceled,
+	EventUserSuspended, EventUserBanned, EventUserUnbanned, EventUserDeleted,
+	EventSupportTicketCreated, EventSupportTicketReplied, EventSupportTicketClaimed, EventSupportTicketCanceled,
+	EventReviewCreated,
+}
+
+// WebhookEventTypes berisi event yang dapat dilanggan webhook penjual
+var WebhookEventTypes = []EventType{
+	EventPurchaseCreated, EventTransactionCanceled, EventTransactionCompleted, EventReviewCreated,
+}
+
+type WebhookDeliveryStatus string
+const (
+	WebhookDeliveryPending WebhookDeliveryStatus = "pending"
+	WebhookDeliverySending WebhookDeliveryStatus = "sending"
+	WebhookDeliverySuccess WebhookDeliveryStatus = "success"
+	WebhookDeliveryFailed  WebhookDeliveryStatus = "failed"
+)
+
+type OutboxStatus string
+const (
+	OutboxPending    OutboxStatus = "pending"
+	OutboxProcessing OutboxStatus = "processing"
+	OutboxPublished  OutboxStatus = "published"
+	OutboxFailed     OutboxStatus = "failed"
 )
 
 type ChatMessageType string
@@ -73,6 +146,130 @@ const (
 	TicketStatusCanceled    SupportTicketStatus = "canceled"
 )
 
+type ImportFormat string
+const (
+	ImportFormatCSV       ImportFormat = "csv"
+	ImportFormatJSONLines ImportFormat = "jsonl"
+)
+
+type ImportJobStatus string
+const (
+	ImportStatusPending    ImportJobStatus = "pending"
+	ImportStatusProcessing ImportJobStatus = "processing"
+	ImportStatusCompleted  ImportJobStatus = "completed"
+	ImportStatusFailed     ImportJobStatus = "failed"
+)
+
+type PriceChangeReason string
+const (
+	PriceChangeManual         PriceChangeReason = "manual"
+	PriceChangeAdmin          PriceChangeReason = "admin"
+	PriceChangeScheduleStart  PriceChangeReason = "schedule_start"
+	PriceChangeScheduleEnd    PriceChangeReason = "schedule_end"
+	PriceChangeScheduleCancel PriceChangeReason = "schedule_cancel"
+)
+
+type CouponDiscountType string
+const (
+	CouponDiscountPercentage CouponDiscountType = "percentage"
+	CouponDiscountFixed      CouponDiscountType = "fixed"
+)
+
+type CouponFundedBy string
+const (
+	CouponFundedByPlatform CouponFundedBy = "platform"
+	CouponFundedBySeller   CouponFundedBy = "seller"
+)
+
+type CouponRedemptionStatus string
+const (
+	CouponRedemptionApplied  CouponRedemptionStatus = "applied"
+	CouponRedemptionReleased CouponRedemptionStatus = "released"
+)
+
+type ReservationStatus string
+const (
+	ReservationActive    ReservationStatus = "active"
+	ReservationConverted ReservationStatus = "converted"
+	ReservationReleased  ReservationStatus = "released"
+	ReservationExpired   ReservationStatus = "expired"
+)
+
+type InventoryCause string
+const (
+	InventoryCauseInitial       InventoryCause = "initial"
+	InventoryCauseSale          InventoryCause = "sale"
+	InventoryCauseCancelRestock InventoryCause = "cancel_restock"
+	InventoryCauseManualEdit    InventoryCause = "manual_edit"
+	InventoryCauseAdjustment    InventoryCause = "adjustment"
+	InventoryCauseImport        InventoryCause = "import"
+)
+
+type VisibilityReason string
+const (
+	VisibilityReasonSoldOut   VisibilityReason = "sold_out"
+	VisibilityReasonRestocked VisibilityReason = "restocked"
+	VisibilityReasonSeller    VisibilityReason = "seller"
+	VisibilityReasonAdmin     VisibilityReason = "admin"
+)
+
+type PriceScheduleStatus string
+const (
+	PriceScheduleScheduled PriceScheduleStatus = "scheduled"
+	PriceScheduleActive    PriceScheduleStatus = "active"
+	PriceScheduleCompleted PriceScheduleStatus = "completed"
+	PriceScheduleCanceled  PriceScheduleStatus = "canceled"
+)
+
+type ProductImageStatus string
+const (
+	ProductImagePending    ProductImageStatus = "pending"
+	ProductImageProcessing ProductImageStatus = "processing"
+	ProductImageReady      ProductImageStatus = "ready"
+	ProductImageFailed     ProductImageStatus = "failed"
+)
+
+type EmailStatus string
+const (
+	EmailPending EmailStatus = "pending"
+	EmailSending EmailStatus = "sending"
+	EmailSent    EmailStatus = "sent"
+	EmailFailed  EmailStatus = "failed"
+)
+
+type DigestFrequency string
+const (
+	DigestImmediate DigestFrequency = "immediate"
+	DigestHourly    DigestFrequency = "hourly"
+	DigestDaily     DigestFrequency = "daily"
+)
+
+// DigestFrequencies berisi seluruh frekuensi ringkasan notifikasi yang valid
+var DigestFrequencies = []DigestFrequency{DigestImmediate, DigestHourly, DigestDaily}
+
+type NotificationRetentionMode string
+const (
+	RetentionArchive NotificationRetentionMode = "archive"
+	RetentionDelete  NotificationRetentionMode = "delete"
+)
+
+type Language string
+const (
+	LanguageIndonesian Language = "id"
+	LanguageEnglish    Language = "en"
+)
+
+// Languages berisi seluruh bahasa yang memiliki katalog pesan
+var Languages = []Language{LanguageIndonesian, LanguageEnglish}
+
+type UploadPurpose string
+const (
+	UploadPurposeGeneral UploadPurpose = "general"
+	UploadPurposeChat    UploadPurpose = "chat"
+	UploadPurposeSupport UploadPurpose = "support"
+	UploadPurposeProduct UploadPurpose = "product"
+)
+
 const (
 	DefaultGovtTaxPercent      = 0.05
 	DefaultEcommerceTaxPercent = 0.02
@@ -81,8 +278,82 @@ const (
 const (
 	MaxImageSizeMB    = 5
 	MaxDocumentSizeMB = 10
+	MaxImportSizeMB   = 5
+)
+
+const (
+	MaxImportRows = 1000
+)
+
+const (
+	DefaultMaxProductImages = 8
+)
+
+const (
+	ImageThumbnailMaxSide = 200
+	ImageMediumMaxSide    = 600
+	ImageFullMaxSide      = 1600
+	ImageJPEGQuality      = 85
+	MaxImagePixels        = 40000000
 )
 
 const (
 	DefaultPenaltyWarningLimit = 3
+)
+
+const (
+	DefaultPriceSchedulerInterval = time.Minute
+	DefaultReservationTTLMinutes  = 15
+	DefaultReservationSweepInterval = time.Minute
+	DefaultScannerTimeout           = 10 * time.Second
+	DefaultImageFetchTimeout        = 15 * time.Second
+	DefaultImageFetchInterval       = 5 * time.Second
+	DefaultSignedURLTTL             = 15 * time.Minute
+	DefaultMediaURLTTL              = time.Hour
+	DefaultUploadGCInterval         = time.Hour
+	DefaultUploadGCGracePeriod      = 24 * time.Hour
+	DefaultEmailOutboxInterval      = 30 * time.Second
+	DefaultMailTimeout              = 15 * time.Second
+	DefaultEventRelayInterval       = 2 * time.Second
+	DefaultNotificationMaintenanceInterval = 5 * time.Minute
+	DefaultWebhookInterval                 = 5 * time.Second
+	DefaultWebhookTimeout                  = 10 * time.Second
+	DefaultWebPushTTL                      = 24 * time.Hour
+	DefaultWebPushTimeout                  = 10 * time.Second
+	DefaultPushPruneInterval               = time.Hour
+)
+
+const (
+	DefaultImageFetchMaxRedirects = 3
+	DefaultImageFetchWorkers      = 2
+)
+
+const (
+	DefaultStorageQuotaMBUser  = 500
+	DefaultStorageQuotaMBAdmin = 2048
+	DefaultUploadRateLimit     = 20
+	DefaultUploadRateWindow    = time.Minute
+)
+
+const (
+	DefaultNotificationTimezone = "Asia/Jakarta"
+	DefaultEmailMaxAttempts     = 5
+	DefaultMailDevDir           = "tmp/mail"
+	DefaultEventMaxAttempts     = 8
+	DefaultLanguage             = LanguageIndonesian
+	DefaultNotificationRetentionDays = 90
+	DefaultNotificationRetentionMode = RetentionArchive
+	// DefaultDigestDailyHour adalah jam lokal pengiriman ringkasan harian
+	DefaultDigestDailyHour = 8
+	DefaultWebhookMaxAttempts = 8
+	MaxWebhooksPerUser        = 10
+	// DefaultWebPushMaxFailures adalah jumlah kegagalan berturut-turut sebelum langganan
+	// web push dihapus
+	DefaultWebPushMaxFailures   = 5
+	MaxPushSubscriptionsPerUser = 20
+)
+
+const (
+	QuarantineDir = "media/quarantine"
+	StagingDir    = "media/temp"
 )
\ No newline at end of file
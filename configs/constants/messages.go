@@ -25,6 +25,37 @@ const (
 	MsgSuccessVisibilityUpdated = "Visibilitas produk berhasil diperbarui!"
 	MsgSuccessAdminLogin        = "Login admin berhasil! Selamat datang di panel admin."
 	MsgSuccessFileUpload        = "File berhasil diunggah!"
+	MsgSuccessPriceScheduled    = "Jadwal harga berhasil dibuat!"
+	MsgSuccessPriceScheduleCanceled = "Jadwal harga berhasil dibatalkan."
+	MsgSuccessImportQueued      = "File impor diterima! Produk sedang diproses di latar belakang."
+	MsgSuccessCouponCreated     = "Kupon berhasil dibuat!"
+	MsgSuccessCouponUpdated     = "Kupon berhasil diperbarui!"
+	MsgSuccessWishlistAdded     = "Produk berhasil ditambahkan ke wishlist!"
+	MsgSuccessWishlistRemoved   = "Produk berhasil dihapus dari wishlist."
+	MsgSuccessRestockSubscribed = "Anda akan diberi tahu saat produk ini kembali tersedia."
+	MsgSuccessRestockUnsubscribed = "Langganan notifikasi stok berhasil dibatalkan."
+	MsgSuccessStockReserved     = "Stok berhasil direservasi! Selesaikan pembayaran sebelum reservasi berakhir."
+	MsgSuccessReservationReleased = "Reservasi stok berhasil dibatalkan."
+	MsgSuccessStockAdjusted     = "Stok produk berhasil disesuaikan!"
+	MsgSuccessProductImagesAdded = "Gambar produk berhasil ditambahkan!"
+	MsgSuccessProductImageUpdated = "Gambar produk berhasil diperbarui!"
+	MsgSuccessProductImageDeleted = "Gambar produk berhasil dihapus!"
+	MsgSuccessProductImagesReordered = "Urutan gambar produk berhasil diperbarui!"
+	MsgSuccessStorageQuotaUpdated = "Kuota penyimpanan pengguna berhasil diperbarui!"
+	MsgSuccessNotificationsRead   = "Notifikasi berhasil ditandai sudah dibaca!"
+	MsgSuccessNotificationDeleted = "Notifikasi berhasil dihapus!"
+	MsgSuccessNotificationPreferencesUpdated = "Preferensi notifikasi berhasil diperbarui!"
+	MsgSuccessOutboxEventReplayed            = "Event dijadwalkan untuk dikirim ulang."
+	MsgSuccessAdminDashboard                 = "Selamat datang di Dashboard Admin!"
+	MsgSuccessWebhookCreated                 = "Webhook berhasil dibuat. Simpan secret ini, secret tidak ditampilkan lagi."
+	MsgSuccessWebhookUpdated                 = "Webhook berhasil diperbarui!"
+	MsgSuccessWebhookDeleted                 = "Webhook berhasil dihapus!"
+	MsgSuccessWebhookSecretRotated           = "Secret webhook berhasil diganti. Simpan secret ini, secret tidak ditampilkan lagi."
+	MsgSuccessWebhookTested                  = "Uji coba webhook selesai."
+	MsgSuccessWebhookRedelivered             = "Pengiriman webhook dijadwalkan ulang."
+	MsgSuccessPushSubscribed                 = "Perangkat berhasil didaftarkan untuk web push!"
+	MsgSuccessPushUnsubscribed               = "Langganan web push berhasil dihapus!"
+	MsgSuccessChatMarkedRead                 = "Pesan chat ditandai sudah dibaca."
 )
 
 const (
@@ -72,4 +103,100 @@ const (
 	ErrMsgProductAlreadyHidden     = "Produk sudah tidak terlihat publik."
 	ErrMsgProductAlreadyVisible    = "Produk sudah terlihat publik."
 	ErrMsgNoFieldsToUpdate         = "Tidak ada bidang yang perlu diperbarui."
+	ErrMsgPriceScheduleNotFound    = "Jadwal harga tidak ditemukan."
+	ErrMsgPriceScheduleInvalidTime = "Waktu jadwal harga tidak valid. Waktu mulai harus di masa depan dan sebelum waktu berakhir."
+	ErrMsgPriceScheduleOverlap     = "Jadwal harga bertabrakan dengan jadwal lain untuk produk ini."
+	ErrMsgPriceScheduleNotCancellable = "Jadwal harga ini sudah selesai atau dibatalkan."
+	ErrMsgImportJobNotFound        = "Pekerjaan impor produk tidak ditemukan."
+	ErrMsgImportFormatInvalid      = "Format impor tidak valid. Hanya CSV (.csv) atau JSON Lines (.jsonl) yang diizinkan."
+	ErrMsgImportEmpty              = "File impor tidak berisi baris produk."
+	ErrMsgImportTooManyRows        = "File impor melebihi batas jumlah baris yang diizinkan."
+	ErrMsgCouponNotFound           = "Kupon tidak ditemukan atau sudah tidak aktif."
+	ErrMsgCouponCodeTaken          = "Kode kupon sudah digunakan."
+	ErrMsgCouponExpired            = "Kupon belum berlaku atau sudah kedaluwarsa."
+	ErrMsgCouponUsageExceeded      = "Kuota penggunaan kupon sudah habis."
+	ErrMsgCouponUserLimitExceeded  = "Anda sudah mencapai batas penggunaan kupon ini."
+	ErrMsgCouponMinSpend           = "Total belanja belum memenuhi minimum pembelian kupon."
+	ErrMsgCouponNotApplicable      = "Kupon tidak berlaku untuk produk ini."
+	ErrMsgCouponInvalidValue       = "Nilai diskon kupon tidak valid. Persentase harus antara 1 dan 100."
+	ErrMsgWishlistItemNotFound     = "Produk tidak ada di wishlist Anda."
+	ErrMsgRestockSubscriptionNotFound = "Anda tidak berlangganan notifikasi stok untuk produk ini."
+	ErrMsgProductStillInStock      = "Produk masih tersedia, tidak perlu berlangganan notifikasi stok."
+	ErrMsgReservationNotFound      = "Reservasi stok tidak ditemukan atau sudah tidak aktif."
+	ErrMsgReservationExpired       = "Reservasi stok sudah kedaluwarsa. Silakan mulai checkout kembali."
+	ErrMsgReservationMismatch      = "Reservasi stok tidak sesuai dengan produk atau jumlah yang dibeli."
+	ErrMsgStockAdjustmentInvalid   = "Penyesuaian stok tidak valid. Perubahan tidak boleh 0 dan stok tidak boleh menjadi negatif."
+	ErrMsgProductImageNotFound     = "Gambar produk tidak ditemukan."
+	ErrMsgProductImageLimit        = "Jumlah gambar produk melebihi batas"
+	ErrMsgProductImageOrderInvalid = "Urutan gambar tidak valid. Sertakan semua ID gambar produk tepat satu kali."
+	ErrMsgProductImageRequired     = "Tidak ada gambar yang dikirim."
+	ErrMsgInvalidImage             = "File bukan gambar yang valid atau formatnya tidak didukung."
+	ErrMsgImageDimensionsTooLarge  = "Dimensi gambar terlalu besar."
+	ErrMsgFileContentMismatch      = "Isi file tidak sesuai dengan ekstensinya."
+	ErrMsgFileInfected             = "File ditolak karena terdeteksi mengandung malware."
+	ErrMsgFileScanFailed           = "File tidak dapat dipindai saat ini, silakan coba lagi nanti."
+	ErrMsgRemoteURLBlocked         = "URL gambar tidak diizinkan."
+	ErrMsgRemoteURLTooManyRedirects = "URL gambar terlalu banyak melakukan redirect."
+	ErrMsgMediaSignatureInvalid    = "Tautan media tidak valid atau sudah kedaluwarsa."
+	ErrMsgUploadNotOwned           = "File tidak ditemukan atau bukan unggahan Anda."
+	ErrMsgStorageQuotaExceeded     = "Kuota penyimpanan Anda tidak mencukupi untuk file ini."
+	ErrMsgUploadRateLimited        = "Terlalu banyak unggahan dalam waktu singkat, silakan coba lagi nanti."
+	ErrMsgNotificationNotFound     = "Notifikasi tidak ditemukan."
+	ErrMsgInvalidNotificationType  = "Tipe notifikasi tidak valid."
+	ErrMsgInvalidNotificationChannel = "Channel notifikasi tidak valid."
+	ErrMsgNotificationChannelRequired = "Notifikasi akun tidak dapat dinonaktifkan dari kotak masuk."
+	ErrMsgInvalidQuietHours        = "Jam tenang tidak valid. Gunakan format HH:MM dan zona waktu IANA, misalnya Asia/Jakarta."
+	ErrMsgOutboxEventNotFound      = "Event tidak ditemukan."
+	ErrMsgOutboxEventNotReplayable = "Event yang masih menunggu atau sedang diproses tidak dapat dikirim ulang."
+	ErrMsgAdminSelfDelete          = "Admin tidak dapat menghapus akunnya sendiri."
+	ErrMsgTicketNotAssigned        = "Anda tidak ditugaskan untuk tiket ini atau tiket belum diklaim."
+	ErrMsgPurchaseItemsEmpty       = "Daftar pembelian tidak boleh kosong."
+	ErrMsgReservationItemsEmpty    = "Daftar reservasi tidak boleh kosong."
+	ErrMsgImportFileMissing        = "File impor tidak ditemukan dalam request."
+	ErrMsgFileMissing              = "File tidak ditemukan dalam request."
+	ErrMsgImageMissing             = "Gambar tidak ditemukan dalam request."
+	ErrMsgInvalidBuyerID           = "ID pembeli tidak valid."
+	ErrMsgProductIDRequired        = "ID produk diperlukan."
+	ErrMsgInvalidTransactionID     = "ID transaksi tidak valid."
+	ErrMsgInvalidBalanceAction     = "Tipe aksi tidak valid. Harus 'topup' atau 'withdraw'."
+	ErrMsgInvalidAdminID           = "ID admin tidak valid."
+	ErrMsgInvalidProductID         = "ID produk tidak valid."
+	ErrMsgInvalidReceiptStatus     = "Status receipt tidak valid."
+	ErrMsgInvalidRole              = "Role tidak valid. Harus 'user' atau 'admin'."
+	ErrMsgInvalidUserStatus        = "Status tidak valid. Harus 'active', 'suspended', atau 'banned'."
+	ErrMsgInvalidBalanceStatus     = "Status tidak valid. Harus 'credit', 'debit', atau 'refund'."
+	ErrMsgInvalidTransactionStatus = "Status transaksi tidak valid."
+	ErrMsgInvalidUserID            = "ID pengguna tidak valid."
+	ErrMsgOldPasswordRequired      = "Kata sandi lama diperlukan untuk mengubah kata sandi."
+	ErrMsgCouponSellerRequired     = "Kupon yang ditanggung penjual wajib memiliki seller_id."
+	ErrMsgInvalidIsReadParam       = "Parameter is_read harus bernilai true atau false."
+	ErrMsgUserAlreadyActive        = "Pengguna sudah aktif."
+	ErrMsgUserAlreadyBanned        = "Pengguna sudah diblokir."
+	ErrMsgUserAlreadyDeleted       = "Pengguna sudah dihapus sebelumnya."
+	ErrMsgUserAlreadySuspended     = "Pengguna sudah ditangguhkan."
+	ErrMsgProductOutOfStockVisibility = "Produk tidak dapat ditampilkan ke publik karena stok 0. Mohon perbarui stok terlebih dahulu."
+	ErrMsgInvalidTransactionStatusFilter = "Status tidak valid. Harus 'pending', 'waiting_owner', 'waiting_users', 'success', atau 'cancel'."
+	ErrMsgTicketClosed             = "Tidak dapat membalas tiket yang sudah ditutup atau dibatalkan."
+	ErrMsgCannotBanAdmin           = "Tidak dapat memblokir akun admin."
+	ErrMsgCannotSuspendAdmin       = "Tidak dapat menangguhkan akun admin."
+	ErrMsgCannotUnbanAdmin         = "Tidak dapat mengaktifkan kembali akun admin."
+	ErrMsgInvalidEventType         = "Tipe event tidak valid."
+	ErrMsgTokenExpired             = "Token kadaluarsa, mohon login kembali."
+	ErrMsgVisibilityNotEditable    = "Visibilitas produk tidak dapat diubah melalui endpoint ini."
+	ErrMsgInvalidVisibility        = "Visibilitas tidak valid. Harus 'all' atau 'owner_admin'."
+	ErrMsgInvalidVisibilityAdmin   = "Visibilitas tidak valid. Harus 'all', 'owner_admin', atau 'admin_only'."
+	ErrMsgInvalidDigestFrequency   = "Frekuensi ringkasan notifikasi tidak valid. Harus 'immediate', 'hourly', atau 'daily'."
+	ErrMsgDigestNotAllowed         = "Notifikasi status akun tidak dapat digabung ke ringkasan."
+	ErrMsgWebhookNotFound          = "Webhook tidak ditemukan."
+	ErrMsgWebhookDeliveryNotFound  = "Riwayat pengiriman webhook tidak ditemukan."
+	ErrMsgInvalidWebhookURL        = "URL webhook tidak valid atau mengarah ke alamat yang diblokir."
+	ErrMsgInvalidWebhookEventType  = "Tipe event webhook tidak valid. Harus 'purchase.created', 'transaction.canceled', 'transaction.completed', atau 'review.created'."
+	ErrMsgWebhookLimitReached      = "Jumlah webhook sudah mencapai batas maksimum."
+	ErrMsgWebhookDeliveryPending   = "Pengiriman webhook masih dalam antrean."
+	ErrMsgWebPushNotConfigured     = "Web push belum dikonfigurasi di server."
+	ErrMsgInvalidPushSubscription  = "Langganan web push tidak valid."
+	ErrMsgPushSubscriptionNotFound = "Langganan web push tidak ditemukan."
+	ErrMsgPushSubscriptionLimit    = "Jumlah perangkat web push sudah mencapai batas maksimum."
+	ErrMsgInvalidChatCursor        = "Parameter before dan after tidak dapat dipakai bersamaan."
+	ErrMsgNotChatParticipant       = "Anda bukan bagian dari transaksi ini."
 )
\ No newline at end of file
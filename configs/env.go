@@ -3,6 +3,8 @@ package configs
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 func GetEnv(key, defaultValue string) string {
@@ -35,4 +37,31 @@ func GetEnvFloat(key string, defaultValue float64) float64 {
 		return defaultValue
 	}
 	return val
-}
\ No newline at end of file
+}
+
+func GetEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	valueStr := GetEnv(key, "")
+	if valueStr == "" {
+		return defaultValue
+	}
+	val, err := time.ParseDuration(valueStr)
+	if err != nil {
+		return defaultValue
+	}
+	return val
+}
+
+// GetEnvList membaca daftar yang dipisah koma, entri kosong diabaikan
+func GetEnvList(key string, defaultValue []string) []string {
+	valueStr := GetEnv(key, "")
+	if valueStr == "" {
+		return defaultValue
+	}
+	var result []string
+	for _, part := range strings.Split(valueStr, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
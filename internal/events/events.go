@@ -0,0 +1,223 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+)
+
+// Jenis aggregate yang menjadi sumber event
+const (
+	AggregateTransaction   = "transaction"
+	AggregateUser          = "user"
+	AggregateSupportTicket = "support_ticket"
+	AggregateReview        = "review"
+)
+
+// Event adalah event domain yang diteruskan ke subscriber
+type Event struct {
+	ID            uint
+	Type          constants.EventType
+	AggregateType string
+	AggregateID   uint
+	Payload       db.JSONB
+	OccurredAt    time.Time
+}
+
+// Handler memproses satu event. Event dikirim at-least-once sehingga handler harus
+// idempoten, misalnya dengan memakai Event.ID sebagai kunci.
+type Handler func(ctx context.Context, event Event) error
+
+type subscription struct {
+	name       string
+	eventTypes []constants.EventType
+	handler    Handler
+}
+
+var (
+	subscriptionsMu sync.RWMutex
+	subscriptions   []subscription
+)
+
+// Subscribe mendaftarkan handler untuk tipe event tertentu, atau semua tipe bila
+// eventTypes kosong. Nama dicatat di outbox untuk subscriber yang sudah berhasil, jadi
+// harus unik dan tidak berubah antar deploy.
+func Subscribe(name string, handler Handler, eventTypes ...constants.EventType) {
+	subscriptionsMu.Lock()
+	defer subscriptionsMu.Unlock()
+	subscriptions = append(subscriptions, subscription{name: name, eventTypes: eventTypes, handler: handler})
+}
+
+// Publish menulis event ke outbox di dalam transaksi tx, sehingga event hanya diteruskan
+// bila perubahan state-nya ikut di-commit
+func Publish(tx *gorm.DB, eventType constants.EventType, aggregateType string, aggregateID uint, payload db.JSONB) error {
+	event := db.OutboxEvent{
+		EventType:     eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       payload,
+		Status:        constants.OutboxPending,
+		NextAttemptAt: time.Now(),
+	}
+	if err := tx.Create(&event).Error; err != nil {
+		return fmt.Errorf("failed to write outbox event %s: %v", eventType, err)
+	}
+	return nil
+}
+
+// Dispatch menjalankan subscriber event yang belum tercatat berhasil. Mengembalikan
+// seluruh subscriber yang sudah berhasil, termasuk dari percobaan sebelumnya, beserta
+// gabungan error subscriber yang gagal.
+func Dispatch(ctx context.Context, outbox db.OutboxEvent) ([]string, error) {
+	completed := CompletedSubscribers(outbox)
+	event := Event{
+		ID:            outbox.ID,
+		Type:          outbox.EventType,
+		AggregateType: outbox.AggregateType,
+		AggregateID:   outbox.AggregateID,
+		Payload:       outbox.Payload,
+		OccurredAt:    outbox.CreatedAt,
+	}
+
+	subscriptionsMu.RLock()
+	subs := slices.Clone(subscriptions)
+	subscriptionsMu.RUnlock()
+
+	var errs []error
+	for _, sub := range subs {
+		if len(sub.eventTypes) > 0 && !slices.Contains(sub.eventTypes, event.Type) {
+			continue
+		}
+		if slices.Contains(completed, sub.name) {
+			continue
+		}
+		if err := runHandler(ctx, sub, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sub.name, err))
+			continue
+		}
+		completed = append(completed, sub.name)
+	}
+	return completed, errors.Join(errs...)
+}
+
+// CompletedSubscribers mengurai daftar subscriber yang sudah berhasil untuk event
+func CompletedSubscribers(outbox db.OutboxEvent) []string {
+	if outbox.CompletedSubscribers == "" {
+		return nil
+	}
+	return strings.Split(outbox.CompletedSubscribers, ",")
+}
+
+// Claim mencatat bahwa subscriber sudah memproses event di dalam transaksi tx. Mengembalikan
+// false bila event sudah pernah diproses, sehingga efek samping subscriber yang tidak dapat
+// memakai Event.ID sebagai kunci unik tetap hanya terjadi sekali.
+func Claim(tx *gorm.DB, subscriber string, eventID uint) (bool, error) {
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&db.EventReceipt{EventID: eventID, Subscriber: subscriber})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// PayloadUint membaca ID dari payload event, baik sebelum maupun sesudah disimpan sebagai
+// JSONB (float64)
+func PayloadUint(payload db.JSONB, key string) uint {
+	switch v := payload[key].(type) {
+	case uint:
+		return v
+	case *uint:
+		if v != nil {
+			return *v
+		}
+	case int:
+		return uint(v)
+	case int64:
+		return uint(v)
+	case float64:
+		return uint(v)
+	}
+	return 0
+}
+
+// PayloadString membaca nilai teks dari payload event
+func PayloadString(payload db.JSONB, key string) string {
+	if v, ok := payload[key]; ok && v != nil {
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+func runHandler(ctx context.Context, sub subscription, event Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return sub.handler(ctx, event)
+}
+
+// Penyebab perubahan transaksi pada payload event transaksi
+const (
+	ReasonBuyer          = "buyer"
+	ReasonSeller         = "seller"
+	ReasonAdmin          = "admin"
+	ReasonProductDeleted = "product_deleted"
+	ReasonSellerRemoved  = "seller_removed"
+)
+
+// PublishTransaction menulis event perubahan transaksi dengan payload standar. previous
+// adalah status transaksi sebelum perubahan.
+func PublishTransaction(tx *gorm.DB, eventType constants.EventType, trx db.TransactionHistory, sellerID uint, previous constants.TransactionStatus, reason string) error {
+	return Publish(tx, eventType, AggregateTransaction, trx.ID, db.JSONB{
+		"transaction_id":  trx.ID,
+		"product_id":      trx.ProductID,
+		"buyer_id":        trx.UserID,
+		"seller_id":       sellerID,
+		"quantity":        trx.Quantity,
+		"total_price":     trx.TotalPrice,
+		"govt_tax":        trx.GovtTax,
+		"discount_amount": trx.DiscountAmount,
+		"previous_status": previous,
+		"reason":          reason,
+	})
+}
+
+// PublishSupportTicket menulis event tiket dukungan. messageID diisi untuk event balasan.
+func PublishSupportTicket(tx *gorm.DB, eventType constants.EventType, ticket db.SupportTicket, actorID uint, messageID *uint) error {
+	payload := db.JSONB{
+		"ticket_id":         ticket.ID,
+		"user_id":           ticket.UserID,
+		"assigned_admin_id": ticket.AssignedAdminID,
+		"subject":           ticket.Subject,
+		"status":            ticket.Status,
+		"queue_pos":         ticket.QueuePos,
+		"actor_id":          actorID,
+	}
+	if messageID != nil {
+		payload["message_id"] = *messageID
+	}
+	return Publish(tx, eventType, AggregateSupportTicket, ticket.ID, payload)
+}
+
+// PublishReview menulis event ulasan baru beserta penjual produk yang diulas
+func PublishReview(tx *gorm.DB, review db.Review, transactionID, sellerID uint) error {
+	return Publish(tx, constants.EventReviewCreated, AggregateReview, review.ID, db.JSONB{
+		"review_id":      review.ID,
+		"transaction_id": transactionID,
+		"product_id":     review.ProductID,
+		"buyer_id":       review.UserID,
+		"seller_id":      sellerID,
+		"rating":         review.Rating,
+		"comment":        review.Comment,
+	})
+}
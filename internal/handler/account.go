@@ -16,6 +16,8 @@ import (
 	"portolio-backend/configs/constants"
 	"portolio-backend/internal/model/db"
 	"portolio-backend/internal/model/dto"
+	"portolio-backend/internal/notify"
+	"portolio-backend/internal/service"
 	"portolio-backend/internal/util"
 )
 
@@ -139,19 +141,11 @@ func (h *AccountHandler) PostLoginRequest(c *gin.Context) {
 			user.PenaltyWarnings = 0
 			h.db.Save(&user)
 
-			notification := dto.NotificationResponse{
-				UserID:    user.ID,
-				Type:      constants.NotifTypeAccount,
-				Message:   "Masa pemblokiran akun Anda telah berakhir. Akun Anda sekarang aktif kembali.",
-				RelatedID: &user.ID,
-				CreatedAt: time.Now(),
-			}
-			util.SendNotificationToUser(user.ID, notification)
-			h.db.Create(&db.Notification{
-				UserID:    notification.UserID,
-				Type:      notification.Type,
-				Message:   notification.Message,
-				RelatedID: notification.RelatedID,
+			notify.Send(h.db, &db.Notification{
+				UserID:     user.ID,
+				Type:       constants.NotifTypeAccount,
+				MessageKey: "notification.account_ban_expired",
+				RelatedID:  &user.ID,
 			})
 
 		} else {
@@ -180,6 +174,34 @@ func (h *AccountHandler) PostLoginRequest(c *gin.Context) {
 	util.RespondJSON(c, http.StatusOK, constants.MsgSuccessLogin)
 }
 
+// GetAccount menampilkan profil akun beserta pemakaian kuota penyimpanan
+func (h *AccountHandler) GetAccount(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	var user db.User
+	if err := h.db.First(&user, userID).Error; err != nil {
+		util.RespondJSON(c, http.StatusNotFound, constants.ErrMsgUserNotFound)
+		return
+	}
+
+	util.RespondJSON(c, http.StatusOK, dto.GetAccountResponse{
+		ID:        user.ID,
+		FullName:  user.FullName,
+		Email:     user.Email,
+		Role:      user.Role,
+		Balance:   user.Balance,
+		Status:    user.Status,
+		Language:  util.RequestLanguage(c),
+		CreatedAt: user.CreatedAt,
+		Storage:   service.StorageUsage(user),
+	})
+}
+
 func (h *AccountHandler) GetBalanceRequest(c *gin.Context) {
 	idVal, exists := c.Get("ID")
 	if !exists {
@@ -256,7 +278,7 @@ func (h *AccountHandler) PostTopUpBalance(c *gin.Context) {
 
 	var responseData dto.PostTopUpResponse
 
-	err := h.db.Transaction(func(tx *gorm.DB) error {
+	err := notify.Transaction(h.db, func(tx *gorm.DB) error {
 		var user db.User
 		if err := tx.First(&user, userID).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
@@ -287,22 +309,15 @@ func (h *AccountHandler) PostTopUpBalance(c *gin.Context) {
 		}
 
 		notification := db.Notification{
-			UserID:    user.ID,
-			Type:      constants.NotifTypeTopUp,
-			Message:   fmt.Sprintf("Top up sebesar Rp%d berhasil! Saldo Anda sekarang Rp%d.", req.Amount, finalBalance),
-			RelatedID: &balanceHistory.ID,
+			UserID:        user.ID,
+			Type:          constants.NotifTypeTopUp,
+			MessageKey:    "notification.topup_success",
+			MessageParams: db.JSONB{"amount": req.Amount, "balance": finalBalance},
+			RelatedID:     &balanceHistory.ID,
 		}
-		if err := tx.Create(&notification).Error; err != nil {
+		if err := notify.Send(tx, &notification); err != nil {
 			return fmt.Errorf("failed to create notification: %v", err)
 		}
-		util.SendNotificationToUser(user.ID, dto.NotificationResponse{
-			ID:        notification.ID,
-			Type:      notification.Type,
-			Message:   notification.Message,
-			RelatedID: notification.RelatedID,
-			CreatedAt: notification.CreatedAt,
-			IsRead:    notification.IsRead,
-		})
 
 		balanceResp := dto.BalanceHistoryResponse{
 			ID:           balanceHistory.ID,
@@ -355,7 +370,7 @@ func (h *AccountHandler) PostWithDrawBalance(c *gin.Context) {
 
 	var responseData dto.PostWithdrawResponse
 
-	err := h.db.Transaction(func(tx *gorm.DB) error {
+	err := notify.Transaction(h.db, func(tx *gorm.DB) error {
 		var user db.User
 		if err := tx.First(&user, userID).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
@@ -390,22 +405,15 @@ func (h *AccountHandler) PostWithDrawBalance(c *gin.Context) {
 		}
 
 		notification := db.Notification{
-			UserID:    user.ID,
-			Type:      constants.NotifTypeWithdraw,
-			Message:   fmt.Sprintf("Penarikan dana sebesar Rp%d berhasil! Saldo Anda sekarang Rp%d.", req.Amount, finalBalance),
-			RelatedID: &balanceHistory.ID,
+			UserID:        user.ID,
+			Type:          constants.NotifTypeWithdraw,
+			MessageKey:    "notification.withdraw_success",
+			MessageParams: db.JSONB{"amount": req.Amount, "balance": finalBalance},
+			RelatedID:     &balanceHistory.ID,
 		}
-		if err := tx.Create(&notification).Error; err != nil {
+		if err := notify.Send(tx, &notification); err != nil {
 			return fmt.Errorf("failed to create notification: %v", err)
 		}
-		util.SendNotificationToUser(user.ID, dto.NotificationResponse{
-			ID:        notification.ID,
-			Type:      notification.Type,
-			Message:   notification.Message,
-			RelatedID: notification.RelatedID,
-			CreatedAt: notification.CreatedAt,
-			IsRead:    notification.IsRead,
-		})
 
 		balanceResp := dto.BalanceHistoryResponse{
 			ID:           balanceHistory.ID,
@@ -482,7 +490,7 @@ func (h *AccountHandler) PatchAccount(c *gin.Context) {
 
 	if req.NewPassword != "" {
 		if req.OldPassword == "" {
-			util.RespondJSON(c, http.StatusBadRequest, "Kata sandi lama diperlukan untuk mengubah kata sandi.")
+			util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgOldPasswordRequired)
 			return
 		}
 		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.OldPassword)); err != nil {
@@ -501,6 +509,14 @@ func (h *AccountHandler) PatchAccount(c *gin.Context) {
 		updates["password"] = string(hashedPassword)
 	}
 
+	if req.Language != "" {
+		if req.Language == "auto" {
+			updates["language"] = ""
+		} else {
+			updates["language"] = req.Language
+		}
+	}
+
 	if len(updates) == 0 {
 		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgNoFieldsToUpdate)
 		return
@@ -511,5 +527,8 @@ func (h *AccountHandler) PatchAccount(c *gin.Context) {
 		return
 	}
 
+	if language, ok := updates["language"]; ok {
+		c.Set("LANGUAGE", constants.Language(language.(string)))
+	}
 	util.RespondJSON(c, http.StatusOK, constants.MsgSuccessAccountUpdated)
 }
\ No newline at end of file
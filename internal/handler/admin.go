@@ -12,11 +12,15 @@ import (
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"portolio-backend/configs"
 	"portolio-backend/configs/constants"
+	"portolio-backend/internal/events"
 	"portolio-backend/internal/model/db"
 	"portolio-backend/internal/model/dto"
+	"portolio-backend/internal/notify"
+	"portolio-backend/internal/service"
 	"portolio-backend/internal/util"
 )
 
@@ -133,7 +137,7 @@ func (h *AdminHandler) GetUsers(c *gin.Context) {
 
 	if status != "" {
 		if status != string(constants.UserStatusActive) && status != string(constants.UserStatusSuspended) && status != string(constants.UserStatusBanned) {
-			util.RespondJSON(c, http.StatusBadRequest, "Invalid status. Must be 'active', 'suspended', or 'banned'.")
+			util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidUserStatus)
 			return
 		}
 		query = query.Where("status = ?", status)
@@ -141,7 +145,7 @@ func (h *AdminHandler) GetUsers(c *gin.Context) {
 
 	if role != "" {
 		if role != constants.RoleUser && role != constants.RoleAdmin {
-			util.RespondJSON(c, http.StatusBadRequest, "Invalid role. Must be 'user' or 'admin'.")
+			util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidRole)
 			return
 		}
 		query = query.Where("role = ?", role)
@@ -214,16 +218,16 @@ func (h *AdminHandler) SuspendUser(c *gin.Context) {
 	}
 
 	if user.Role == constants.RoleAdmin {
-		util.RespondJSON(c, http.StatusForbidden, "Tidak dapat menangguhkan akun admin.")
+		util.RespondJSON(c, http.StatusForbidden, constants.ErrMsgCannotSuspendAdmin)
 		return
 	}
 
 	if user.Status == constants.UserStatusSuspended {
-		util.RespondJSON(c, http.StatusOK, "Pengguna sudah ditangguhkan.")
+		util.RespondJSON(c, http.StatusOK, constants.ErrMsgUserAlreadySuspended)
 		return
 	}
 
-	err = h.db.Transaction(func(tx *gorm.DB) error {
+	err = notify.Transaction(h.db, func(tx *gorm.DB) error {
 		user.Status = constants.UserStatusSuspended
 		user.BanUntil = nil
 		user.BanReason = req.Reason
@@ -238,32 +242,18 @@ func (h *AdminHandler) SuspendUser(c *gin.Context) {
 		}
 
 		for _, product := range products {
-			product.DeletedAt = gorm.DeletedAt{
-				Time:  time.Now(),
-				Valid: true,
-			}
-			product.Visibility = constants.ProductVisibilityOwnerAdmin
-			if err := tx.Save(&product).Error; err != nil {
-				return fmt.Errorf("failed to soft delete product %d: %v", product.ID, err)
+			if err := deleteSellerProduct(tx, product, adminID); err != nil {
+				return err
 			}
-			handleProductTransactionsOnDelete(tx, product)
-		}
-
-		notification := db.Notification{
-			UserID:    user.ID,
-			Type:      constants.NotifTypeAccount,
-			Message:   fmt.Sprintf("Akun Anda telah ditangguhkan secara permanen karena: %s. Mohon hubungi dukungan untuk informasi lebih lanjut.", req.Reason),
-			RelatedID: &user.ID,
-		}
-		util.SendNotificationToUser(user.ID, dto.NotificationResponse{
-			ID:        notification.ID,
-			Type:      notification.Type,
-			Message:   notification.Message,
-			RelatedID: notification.RelatedID,
-			CreatedAt: notification.CreatedAt,
-			IsRead:    notification.IsRead,
-		})
-		tx.Create(&notification)
+		}
+
+		if err := events.Publish(tx, constants.EventUserSuspended, events.AggregateUser, user.ID, db.JSONB{
+			"user_id":  user.ID,
+			"admin_id": adminID,
+			"reason":   req.Reason,
+		}); err != nil {
+			return err
+		}
 
 		adminLog := db.AdminLog{
 			AdminID:    adminID,
@@ -314,16 +304,16 @@ func (h *AdminHandler) BanUser(c *gin.Context) {
 	}
 
 	if user.Role == constants.RoleAdmin {
-		util.RespondJSON(c, http.StatusForbidden, "Tidak dapat memblokir akun admin.")
+		util.RespondJSON(c, http.StatusForbidden, constants.ErrMsgCannotBanAdmin)
 		return
 	}
 
 	if user.Status == constants.UserStatusBanned {
-		util.RespondJSON(c, http.StatusOK, "Pengguna sudah diblokir.")
+		util.RespondJSON(c, http.StatusOK, constants.ErrMsgUserAlreadyBanned)
 		return
 	}
 
-	err = h.db.Transaction(func(tx *gorm.DB) error {
+	err = notify.Transaction(h.db, func(tx *gorm.DB) error {
 		banUntil := time.Now().Add(time.Duration(req.DurationHours) * time.Hour)
 		banUntilUnix := banUntil.Unix()
 
@@ -341,32 +331,20 @@ func (h *AdminHandler) BanUser(c *gin.Context) {
 		}
 
 		for _, product := range products {
-			product.DeletedAt = gorm.DeletedAt{
-				Time:  time.Now(),
-				Valid: true,
+			if err := deleteSellerProduct(tx, product, adminID); err != nil {
+				return err
 			}
-			product.Visibility = constants.ProductVisibilityOwnerAdmin
-			if err := tx.Save(&product).Error; err != nil {
-				return fmt.Errorf("failed to soft delete product %d: %v", product.ID, err)
-			}
-			handleProductTransactionsOnDelete(tx, product)
-		}
-
-		notification := db.Notification{
-			UserID:    user.ID,
-			Type:      constants.NotifTypeAccount,
-			Message:   fmt.Sprintf("Akun Anda telah diblokir selama %d jam karena: %s. Anda tidak dapat login selama periode ini.", req.DurationHours, req.Reason),
-			RelatedID: &user.ID,
-		}
-		util.SendNotificationToUser(user.ID, dto.NotificationResponse{
-			ID:        notification.ID,
-			Type:      notification.Type,
-			Message:   notification.Message,
-			RelatedID: notification.RelatedID,
-			CreatedAt: notification.CreatedAt,
-			IsRead:    notification.IsRead,
-		})
-		tx.Create(&notification)
+		}
+
+		if err := events.Publish(tx, constants.EventUserBanned, events.AggregateUser, user.ID, db.JSONB{
+			"user_id":        user.ID,
+			"admin_id":       adminID,
+			"reason":         req.Reason,
+			"duration_hours": req.DurationHours,
+			"ban_until":      banUntil,
+		}); err != nil {
+			return err
+		}
 
 		adminLog := db.AdminLog{
 			AdminID:    adminID,
@@ -411,12 +389,12 @@ func (h *AdminHandler) UnbanUser(c *gin.Context) {
 	}
 
 	if user.Role == constants.RoleAdmin {
-		util.RespondJSON(c, http.StatusForbidden, "Tidak dapat mengaktifkan kembali akun admin.")
+		util.RespondJSON(c, http.StatusForbidden, constants.ErrMsgCannotUnbanAdmin)
 		return
 	}
 
 	if user.Status == constants.UserStatusActive {
-		util.RespondJSON(c, http.StatusOK, "Pengguna sudah aktif.")
+		util.RespondJSON(c, http.StatusOK, constants.ErrMsgUserAlreadyActive)
 		return
 	}
 
@@ -442,21 +420,12 @@ func (h *AdminHandler) UnbanUser(c *gin.Context) {
 			}
 		}
 
-		notification := db.Notification{
-			UserID:    user.ID,
-			Type:      constants.NotifTypeAccount,
-			Message:   "Akun Anda telah diaktifkan kembali. Anda sekarang dapat login dan menggunakan layanan kami.",
-			RelatedID: &user.ID,
+		if err := events.Publish(tx, constants.EventUserUnbanned, events.AggregateUser, user.ID, db.JSONB{
+			"user_id":  user.ID,
+			"admin_id": adminID,
+		}); err != nil {
+			return err
 		}
-		util.SendNotificationToUser(user.ID, dto.NotificationResponse{
-			ID:        notification.ID,
-			Type:      notification.Type,
-			Message:   notification.Message,
-			RelatedID: notification.RelatedID,
-			CreatedAt: notification.CreatedAt,
-			IsRead:    notification.IsRead,
-		})
-		tx.Create(&notification)
 
 		adminLog := db.AdminLog{
 			AdminID:    adminID,
@@ -505,16 +474,16 @@ func (h *AdminHandler) DeleteUser(c *gin.Context) {
 	}
 
 	if user.DeletedAt.Valid {
-		util.RespondJSON(c, http.StatusOK, "Pengguna sudah dihapus sebelumnya.")
+		util.RespondJSON(c, http.StatusOK, constants.ErrMsgUserAlreadyDeleted)
 		return
 	}
 
 	if user.ID == adminID {
-		util.RespondJSON(c, http.StatusForbidden, "Admin tidak dapat menghapus akunnya sendiri.")
+		util.RespondJSON(c, http.StatusForbidden, constants.ErrMsgAdminSelfDelete)
 		return
 	}
 
-	err = h.db.Transaction(func(tx *gorm.DB) error {
+	err = notify.Transaction(h.db, func(tx *gorm.DB) error {
 		user.DeletedAt = gorm.DeletedAt{
 			Time:  time.Now(),
 			Valid: true,
@@ -530,15 +499,16 @@ func (h *AdminHandler) DeleteUser(c *gin.Context) {
 		}
 
 		for _, product := range products {
-			product.DeletedAt = gorm.DeletedAt{
-				Time:  time.Now(),
-				Valid: true,
+			if err := deleteSellerProduct(tx, product, adminID); err != nil {
+				return err
 			}
-			product.Visibility = constants.ProductVisibilityOwnerAdmin
-			if err := tx.Save(&product).Error; err != nil {
-				return fmt.Errorf("failed to soft delete product %d: %v", product.ID, err)
-			}
-			handleProductTransactionsOnDelete(tx, product)
+		}
+
+		if err := events.Publish(tx, constants.EventUserDeleted, events.AggregateUser, user.ID, db.JSONB{
+			"user_id":  user.ID,
+			"admin_id": adminID,
+		}); err != nil {
+			return err
 		}
 
 		adminLog := db.AdminLog{
@@ -581,7 +551,7 @@ func (h *AdminHandler) GetProductsAdmin(c *gin.Context) {
 	offset := (page - 1) * limit
 
 	var products []db.Product
-	query := h.db.Preload("Images").Preload("User").Preload("Reviews", func(db *gorm.DB) *gorm.DB {
+	query := h.db.Preload("Images", service.OrderedImages).Preload("User").Preload("Reviews", func(db *gorm.DB) *gorm.DB {
 		return db.Order("created_at desc").Limit(3).Preload("User")
 	})
 
@@ -595,7 +565,7 @@ func (h *AdminHandler) GetProductsAdmin(c *gin.Context) {
 
 	if visibility != "" {
 		if visibility != string(constants.ProductVisibilityAll) && visibility != string(constants.ProductVisibilityOwnerAdmin) && visibility != string(constants.ProductVisibilityAdminOnly) {
-			util.RespondJSON(c, http.StatusBadRequest, "Visibilitas tidak valid. Harus 'all', 'owner_admin', atau 'admin_only'.")
+			util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidVisibilityAdmin)
 			return
 		}
 		query = query.Where("visibility = ?", visibility)
@@ -604,7 +574,7 @@ func (h *AdminHandler) GetProductsAdmin(c *gin.Context) {
 	if userIDStr != "" {
 		userID, err := strconv.ParseUint(userIDStr, 10, 64)
 		if err != nil {
-			util.RespondJSON(c, http.StatusBadRequest, "Invalid user ID.")
+			util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidUserID)
 			return
 		}
 		query = query.Where("user_id = ?", userID)
@@ -624,11 +594,7 @@ func (h *AdminHandler) GetProductsAdmin(c *gin.Context) {
 
 		images := make([]dto.ProductImageResponse, len(p.Images))
 		for j, img := range p.Images {
-			images[j] = dto.ProductImageResponse{
-				ID:        img.ID,
-				ProductID: img.ProductID,
-				ImageURL:  img.ImageURL,
-			}
+			images[j] = buildProductImageResponse(img)
 		}
 
 		avgRating := calculateAverageRating(p.Reviews)
@@ -638,6 +604,9 @@ func (h *AdminHandler) GetProductsAdmin(c *gin.Context) {
 			Id:         p.ID,
 			Title:      p.Title,
 			Price:      p.Price,
+			PreviousPrice: p.PreviousPrice,
+			AvailableStock: service.AvailableStock(p),
+			VisibilityReason: p.VisibilityReason,
 			Stock:      p.Stock,
 			Visibility: string(p.Visibility),
 			Categories: categories,
@@ -699,18 +668,14 @@ func (h *AdminHandler) PatchProductAdmin(c *gin.Context) {
 	}
 	if req.Stock != 0 {
 		updates["stock"] = req.Stock
-		if req.Stock == 0 {
-			updates["visibility"] = constants.ProductVisibilityOwnerAdmin
-		} else if product.Stock == 0 && req.Stock > 0 && product.Visibility == constants.ProductVisibilityOwnerAdmin {
-			updates["visibility"] = constants.ProductVisibilityAll
-		}
 	}
 	if req.Visibility != "" {
 		if req.Visibility != constants.ProductVisibilityAll && req.Visibility != constants.ProductVisibilityOwnerAdmin && req.Visibility != constants.ProductVisibilityAdminOnly {
-			util.RespondJSON(c, http.StatusBadRequest, "Visibilitas tidak valid. Harus 'all', 'owner_admin', atau 'admin_only'.")
+			util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidVisibilityAdmin)
 			return
 		}
 		updates["visibility"] = req.Visibility
+		updates["visibility_reason"] = constants.VisibilityReasonAdmin
 	}
 	if req.Categories != "" {
 		updates["categories"] = req.Categories
@@ -718,13 +683,46 @@ func (h *AdminHandler) PatchProductAdmin(c *gin.Context) {
 	if req.IsActive != nil {
 		updates["is_active"] = *req.IsActive
 	}
+	if req.LowStockThreshold != nil {
+		updates["low_stock_threshold"] = *req.LowStockThreshold
+	}
 
 	if len(updates) == 0 {
 		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgNoFieldsToUpdate)
 		return
 	}
 
-	err = h.db.Transaction(func(tx *gorm.DB) error {
+	err = notify.Transaction(h.db, func(tx *gorm.DB) error {
+		if req.Stock != 0 {
+			if err := service.AdjustStock(tx, &product, service.StockChange{
+				Delta:   int(req.Stock) - int(product.Stock),
+				Cause:   constants.InventoryCauseManualEdit,
+				Note:    "Perubahan stok oleh admin",
+				ActorID: &adminID,
+			}); err != nil {
+				return fmt.Errorf("failed to update product stock: %v", err)
+			}
+		}
+
+		if req.Price != 0 && (req.Price != product.Price || product.PreviousPrice != nil) {
+			if err := service.CancelActivePriceSchedules(tx, product.ID); err != nil {
+				return fmt.Errorf("failed to cancel active price schedules: %v", err)
+			}
+			if err := service.ChangeProductPrice(tx, &product, service.PriceChange{
+				NewPrice:  req.Price,
+				Reason:    constants.PriceChangeAdmin,
+				ChangedBy: &adminID,
+			}); err != nil {
+				return fmt.Errorf("failed to update product price: %v", err)
+			}
+		}
+
+		if req.Visibility != "" {
+			if err := service.SetProductVisibility(tx, &product, req.Visibility, constants.VisibilityReasonAdmin, &adminID); err != nil {
+				return fmt.Errorf("failed to update product visibility: %v", err)
+			}
+		}
+
 		if err := tx.Model(&product).Updates(updates).Error; err != nil {
 			return fmt.Errorf("failed to update product: %v", err)
 		}
@@ -780,7 +778,7 @@ func (h *AdminHandler) GetTransactions(c *gin.Context) {
 			string(constants.TrxStatusCancel):       true,
 		}
 		if !validStatuses[status] {
-			util.RespondJSON(c, http.StatusBadRequest, "Invalid transaction status.")
+			util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidTransactionStatus)
 			return
 		}
 		query = query.Where("status = ?", status)
@@ -789,7 +787,7 @@ func (h *AdminHandler) GetTransactions(c *gin.Context) {
 	if userIDStr != "" {
 		userID, err := strconv.ParseUint(userIDStr, 10, 64)
 		if err != nil {
-			util.RespondJSON(c, http.StatusBadRequest, "Invalid user ID.")
+			util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidUserID)
 			return
 		}
 		query = query.Where("user_id = ?", userID)
@@ -798,7 +796,7 @@ func (h *AdminHandler) GetTransactions(c *gin.Context) {
 	if productIDStr != "" {
 		productID, err := strconv.ParseUint(productIDStr, 10, 64)
 		if err != nil {
-			util.RespondJSON(c, http.StatusBadRequest, "Invalid product ID.")
+			util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidProductID)
 			return
 		}
 		query = query.Where("product_id = ?", productID)
@@ -811,7 +809,7 @@ func (h *AdminHandler) GetTransactions(c *gin.Context) {
 			string(constants.ReceiptCanceled):       true,
 		}
 		if !validReceiptStatuses[strings.ToUpper(receiptStatus)] {
-			util.RespondJSON(c, http.StatusBadRequest, "Invalid receipt status.")
+			util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidReceiptStatus)
 			return
 		}
 		query = query.Where("receipt_status = ?", strings.ToUpper(receiptStatus))
@@ -833,6 +831,7 @@ func (h *AdminHandler) GetTransactions(c *gin.Context) {
 			UserID:        trx.UserID,
 			Quantity:      trx.Quantity,
 			TotalPrice:    trx.TotalPrice,
+			DiscountAmount: trx.DiscountAmount,
 			GovtTax:       trx.GovtTax,
 			EcommerceTax:  trx.EcommerceTax,
 			Status:        trx.Status,
@@ -917,13 +916,21 @@ func (h *AdminHandler) PatchTransactionStatus(c *gin.Context) {
 		string(constants.TrxStatusCancel):       true,
 	}
 	if !validStatuses[req.Status] {
-		util.RespondJSON(c, http.StatusBadRequest, "Status tidak valid. Harus 'pending', 'waiting_owner', 'waiting_users', 'success', atau 'cancel'.")
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidTransactionStatusFilter)
 		return
 	}
 
-	oldStatus := trx.Status
+	var oldStatus constants.TransactionStatus
+	err = notify.Transaction(h.db, func(tx *gorm.DB) error {
+		// Status lama dibaca dari baris yang dikunci agar refund, restock, dan pembayaran
+		// penjual tidak berjalan dua kali bersamaan dengan pembatalan oleh pengguna
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Preload("Product", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).Preload("User").
+			First(&trx, transactionID).Error; err != nil {
+			return fmt.Errorf(constants.ErrMsgTransactionNotFound+" (ID: %d)", transactionID)
+		}
+		oldStatus = trx.Status
 
-	err = h.db.Transaction(func(tx *gorm.DB) error {
 		updates := map[string]interface{}{
 			"status": req.Status,
 		}
@@ -938,50 +945,22 @@ func (h *AdminHandler) PatchTransactionStatus(c *gin.Context) {
 				if err := tx.First(&owner, trx.Product.UserID).Error; err != nil {
 					return fmt.Errorf("failed to find product owner: %v", err)
 				}
-				sellerReceiveAmount := trx.TotalPrice - trx.EcommerceTax
+				sellerReceiveAmount := trx.SellerPayoutAmount()
 				newOwnerBalance := owner.Balance + sellerReceiveAmount
 				if err := tx.Model(&owner).Update("balance", newOwnerBalance).Error; err != nil {
 					return fmt.Errorf("failed to update seller balance: %v", err)
 				}
-				tx.Create(&db.BalanceHistory{
+				if err := tx.Create(&db.BalanceHistory{
 					UserID:       owner.ID,
 					Description:  fmt.Sprintf("Pembayaran penjualan produk '%s' (ID: %d) oleh admin", trx.Product.Title, trx.ProductID),
 					Amount:       int(sellerReceiveAmount),
 					LastBalance:  owner.Balance,
 					FinalBalance: newOwnerBalance,
 					Status:       constants.BalanceStatusCredit,
-				})
-				notificationSeller := db.Notification{
-					UserID:    owner.ID,
-					Type:      constants.NotifTypeSale,
-					Message:   fmt.Sprintf("Transaksi produk '%s' (ID: %d) berhasil diselesaikan oleh admin. Dana telah ditransfer ke saldo Anda.", trx.Product.Title, trx.ProductID),
-					RelatedID: &trx.ID,
+				}).Error; err != nil {
+					return fmt.Errorf("failed to record seller balance history: %v", err)
 				}
-				util.SendNotificationToUser(owner.ID, dto.NotificationResponse{
-					ID:        notificationSeller.ID,
-					Type:      notificationSeller.Type,
-					Message:   notificationSeller.Message,
-					RelatedID: notificationSeller.RelatedID,
-					CreatedAt: notificationSeller.CreatedAt,
-					IsRead:    notificationSeller.IsRead,
-				})
-				tx.Create(&notificationSeller)
 			}
-			notificationBuyer := db.Notification{
-				UserID:    trx.UserID,
-				Type:      constants.NotifTypePurchase,
-				Message:   fmt.Sprintf("Transaksi produk '%s' (ID: %d) Anda berhasil diselesaikan oleh admin.", trx.Product.Title, trx.ProductID),
-				RelatedID: &trx.ID,
-			}
-			util.SendNotificationToUser(trx.UserID, dto.NotificationResponse{
-				ID:        notificationBuyer.ID,
-				Type:      notificationBuyer.Type,
-				Message:   notificationBuyer.Message,
-				RelatedID: notificationBuyer.RelatedID,
-				CreatedAt: notificationBuyer.CreatedAt,
-				IsRead:    notificationBuyer.IsRead,
-			})
-			tx.Create(&notificationBuyer)
 
 		case constants.TrxStatusCancel:
 			updates["is_solved"] = true
@@ -992,34 +971,41 @@ func (h *AdminHandler) PatchTransactionStatus(c *gin.Context) {
 				if err := tx.First(&buyer, trx.UserID).Error; err != nil {
 					return fmt.Errorf("failed to find buyer: %v", err)
 				}
+				if err := service.ReleaseCouponRedemption(tx, trx); err != nil {
+					return fmt.Errorf("failed to release coupon: %v", err)
+				}
+
+				// Barang yang belum dikirim dikembalikan ke stok penjual.
+				if oldStatus == constants.TrxStatusPending || oldStatus == constants.TrxStatusWaitingOwner {
+					var product db.Product
+					if err := tx.Unscoped().First(&product, trx.ProductID).Error; err != nil {
+						return fmt.Errorf("failed to find product for restock: %v", err)
+					}
+					if err := service.AdjustStock(tx, &product, service.StockChange{
+						Delta:         int(trx.Quantity),
+						Cause:         constants.InventoryCauseCancelRestock,
+						Note:          "Pembatalan oleh admin",
+						ActorID:       &adminID,
+						TransactionID: &trx.ID,
+					}); err != nil {
+						return fmt.Errorf("failed to restock product: %v", err)
+					}
+				}
 				refundAmount := trx.TotalPrice + trx.GovtTax
 				newBalance := buyer.Balance + refundAmount
 				if err := tx.Model(&buyer).Update("balance", newBalance).Error; err != nil {
 					return fmt.Errorf("failed to refund buyer: %v", err)
 				}
-				tx.Create(&db.BalanceHistory{
+				if err := tx.Create(&db.BalanceHistory{
 					UserID:       buyer.ID,
 					Description:  fmt.Sprintf("Refund dari pembatalan produk '%s' (ID: %d) oleh admin", trx.Product.Title, trx.ProductID),
 					Amount:       int(refundAmount),
 					LastBalance:  buyer.Balance,
 					FinalBalance: newBalance,
 					Status:       constants.BalanceStatusRefund,
-				})
-				notificationBuyer := db.Notification{
-					UserID:    buyer.ID,
-					Type:      constants.NotifTypePurchase,
-					Message:   fmt.Sprintf("Transaksi produk '%s' Anda (ID: %d) dibatalkan oleh admin. Dana telah dikembalikan.", trx.Product.Title, trx.ProductID),
-					RelatedID: &trx.ID,
+				}).Error; err != nil {
+					return fmt.Errorf("failed to record refund balance history: %v", err)
 				}
-				util.SendNotificationToUser(buyer.ID, dto.NotificationResponse{
-					ID:        notificationBuyer.ID,
-					Type:      notificationBuyer.Type,
-					Message:   notificationBuyer.Message,
-					RelatedID: notificationBuyer.RelatedID,
-					CreatedAt: notificationBuyer.CreatedAt,
-					IsRead:    notificationBuyer.IsRead,
-				})
-				tx.Create(&notificationBuyer)
 			}
 
 			if oldStatus == constants.TrxStatusWaitingUser || oldStatus == constants.TrxStatusSuccess {
@@ -1027,7 +1013,7 @@ func (h *AdminHandler) PatchTransactionStatus(c *gin.Context) {
 				if err := tx.First(&owner, trx.Product.UserID).Error; err != nil {
 					return fmt.Errorf("failed to find product owner for debit: %v", err)
 				}
-				debitAmount := trx.TotalPrice - trx.EcommerceTax
+				debitAmount := trx.SellerPayoutAmount()
 				newOwnerBalance := owner.Balance - debitAmount
 				if newOwnerBalance < 0 {
 					newOwnerBalance = 0
@@ -1035,29 +1021,16 @@ func (h *AdminHandler) PatchTransactionStatus(c *gin.Context) {
 				if err := tx.Model(&owner).Update("balance", newOwnerBalance).Error; err != nil {
 					return fmt.Errorf("failed to debit seller balance: %v", err)
 				}
-				tx.Create(&db.BalanceHistory{
+				if err := tx.Create(&db.BalanceHistory{
 					UserID:       owner.ID,
 					Description:  fmt.Sprintf("Debit dari pembatalan paksa produk '%s' (ID: %d) oleh admin", trx.Product.Title, trx.ProductID),
 					Amount:       -int(debitAmount),
 					LastBalance:  owner.Balance,
 					FinalBalance: newOwnerBalance,
 					Status:       constants.BalanceStatusDebit,
-				})
-				notificationSeller := db.Notification{
-					UserID:    owner.ID,
-					Type:      constants.NotifTypeSale,
-					Message:   fmt.Sprintf("Transaksi produk '%s' (ID: %d) Anda dibatalkan paksa oleh admin. Saldo Anda dikurangi Rp%d.", trx.Product.Title, trx.ProductID, debitAmount),
-					RelatedID: &trx.ID,
+				}).Error; err != nil {
+					return fmt.Errorf("failed to record seller debit history: %v", err)
 				}
-				util.SendNotificationToUser(owner.ID, dto.NotificationResponse{
-					ID:        notificationSeller.ID,
-					Type:      notificationSeller.Type,
-					Message:   notificationSeller.Message,
-					RelatedID: notificationSeller.RelatedID,
-					CreatedAt: notificationSeller.CreatedAt,
-					IsRead:    notificationSeller.IsRead,
-				})
-				tx.Create(&notificationSeller)
 			}
 
 		default:
@@ -1069,6 +1042,23 @@ func (h *AdminHandler) PatchTransactionStatus(c *gin.Context) {
 			return fmt.Errorf("failed to update transaction status: %v", err)
 		}
 
+		if constants.TransactionStatus(req.Status) != oldStatus {
+			var eventType constants.EventType
+			switch constants.TransactionStatus(req.Status) {
+			case constants.TrxStatusWaitingUser:
+				eventType = constants.EventTransactionShipped
+			case constants.TrxStatusSuccess:
+				eventType = constants.EventTransactionCompleted
+			case constants.TrxStatusCancel:
+				eventType = constants.EventTransactionCanceled
+			}
+			if eventType != "" {
+				if err := events.PublishTransaction(tx, eventType, trx, trx.Product.UserID, oldStatus, events.ReasonAdmin); err != nil {
+					return err
+				}
+			}
+		}
+
 		adminLog := db.AdminLog{
 			AdminID:    adminID,
 			Action:     "patch_transaction_status",
@@ -1112,7 +1102,7 @@ func (h *AdminHandler) GetBalanceHistories(c *gin.Context) {
 	if userIDStr != "" {
 		userID, err := strconv.ParseUint(userIDStr, 10, 64)
 		if err != nil {
-			util.RespondJSON(c, http.StatusBadRequest, "Invalid user ID.")
+			util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidUserID)
 			return
 		}
 		query = query.Where("user_id = ?", userID)
@@ -1120,7 +1110,7 @@ func (h *AdminHandler) GetBalanceHistories(c *gin.Context) {
 
 	if status != "" {
 		if status != string(constants.BalanceStatusCredit) && status != string(constants.BalanceStatusDebit) && status != string(constants.BalanceStatusRefund) {
-			util.RespondJSON(c, http.StatusBadRequest, "Invalid status. Must be 'credit', 'debit', or 'refund'.")
+			util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidBalanceStatus)
 			return
 		}
 		query = query.Where("status = ?", status)
@@ -1194,7 +1184,7 @@ func (h *AdminHandler) GetTopUpWithdrawLogs(c *gin.Context) {
 	if userIDStr != "" {
 		userID, err := strconv.ParseUint(userIDStr, 10, 64)
 		if err != nil {
-			util.RespondJSON(c, http.StatusBadRequest, "Invalid user ID.")
+			util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidUserID)
 			return
 		}
 		query = query.Where("user_id = ?", userID)
@@ -1206,7 +1196,7 @@ func (h *AdminHandler) GetTopUpWithdrawLogs(c *gin.Context) {
 		} else if actionType == "withdraw" {
 			query = query.Where("status = ?", constants.BalanceStatusDebit)
 		} else {
-			util.RespondJSON(c, http.StatusBadRequest, "Invalid action type. Must be 'topup' or 'withdraw'.")
+			util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidBalanceAction)
 			return
 		}
 	} else {
@@ -1294,7 +1284,7 @@ func (h *AdminHandler) GetSupportTickets(c *gin.Context) {
 	if userIDStr != "" {
 		userID, err := strconv.ParseUint(userIDStr, 10, 64)
 		if err != nil {
-			util.RespondJSON(c, http.StatusBadRequest, "Invalid user ID.")
+			util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidUserID)
 			return
 		}
 		query = query.Where("user_id = ?", userID)
@@ -1303,7 +1293,7 @@ func (h *AdminHandler) GetSupportTickets(c *gin.Context) {
 	if adminIDStr != "" {
 		adminID, err := strconv.ParseUint(adminIDStr, 10, 64)
 		if err != nil {
-			util.RespondJSON(c, http.StatusBadRequest, "Invalid admin ID.")
+			util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidAdminID)
 			return
 		}
 		query = query.Where("assigned_admin_id = ?", adminID)
@@ -1347,6 +1337,13 @@ func (h *AdminHandler) GetSupportTickets(c *gin.Context) {
 }
 
 func (h *AdminHandler) GetSupportTicketMessages(c *gin.Context) {
+	adminIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	adminID := adminIDRaw.(uint)
+
 	ticketIDStr := c.Param("id")
 	ticketID, err := strconv.ParseUint(ticketIDStr, 10, 64)
 	if err != nil {
@@ -1380,7 +1377,7 @@ func (h *AdminHandler) GetSupportTicketMessages(c *gin.Context) {
 			IsAdmin:     msg.Sender.Role == constants.RoleAdmin,
 			Content:     msg.Content,
 			MessageType: msg.MessageType,
-			FileURL:     msg.FileURL,
+			FileURL:     util.SignMediaURL(msg.FileURL, adminID),
 			CreatedAt:   msg.CreatedAt,
 		})
 	}
@@ -1435,21 +1432,9 @@ func (h *AdminHandler) ClaimSupportTicket(c *gin.Context) {
 		var adminUser db.User
 		tx.First(&adminUser, adminID)
 
-		notification := db.Notification{
-			UserID:    ticket.UserID,
-			Type:      constants.NotifTypeSupport,
-			Message:   fmt.Sprintf("Tiket dukungan Anda #%d ('%s') telah diklaim oleh admin %s. Mohon tunggu balasan dari kami.", ticket.ID, ticket.Subject, adminUser.FullName),
-			RelatedID: &ticket.ID,
-		}
-		util.SendNotificationToUser(ticket.UserID, dto.NotificationResponse{
-			ID:        notification.ID,
-			Type:      notification.Type,
-			Message:   notification.Message,
-			RelatedID: notification.RelatedID,
-			CreatedAt: notification.CreatedAt,
-			IsRead:    notification.IsRead,
-		})
-		tx.Create(&notification)
+		if err := events.PublishSupportTicket(tx, constants.EventSupportTicketClaimed, ticket, adminID, nil); err != nil {
+			return err
+		}
 
 		adminLog := db.AdminLog{
 			AdminID:    adminID,
@@ -1500,12 +1485,12 @@ func (h *AdminHandler) ReplySupportTicket(c *gin.Context) {
 	}
 
 	if ticket.AssignedAdminID == nil || *ticket.AssignedAdminID != adminID {
-		util.RespondJSON(c, http.StatusForbidden, "Anda tidak ditugaskan untuk tiket ini atau tiket belum diklaim.")
+		util.RespondJSON(c, http.StatusForbidden, constants.ErrMsgTicketNotAssigned)
 		return
 	}
 
 	if ticket.Status == constants.TicketStatusClosed || ticket.Status == constants.TicketStatusCanceled {
-		util.RespondJSON(c, http.StatusBadRequest, "Tidak dapat membalas tiket yang sudah ditutup atau dibatalkan.")
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgTicketClosed)
 		return
 	}
 
@@ -1515,32 +1500,27 @@ func (h *AdminHandler) ReplySupportTicket(c *gin.Context) {
 			SenderID:    adminID,
 			MessageType: req.MessageType,
 			Content:     req.Content,
-			FileURL:     req.FileURL,
+		}
+		if req.FileURL != "" {
+			message.FileURL = util.NormalizeMediaURL(req.FileURL)
 		}
 		if err := tx.Create(&message).Error; err != nil {
 			return fmt.Errorf("failed to create support message: %v", err)
 		}
+		if message.FileURL != "" {
+			if err := service.ClaimUpload(tx, adminID, constants.UploadPurposeSupport, message.FileURL, fmt.Sprintf("support_message:%d", message.ID)); err != nil {
+				return err
+			}
+		}
 
 		ticket.Status = constants.TicketStatusPendingUser
 		if err := tx.Save(&ticket).Error; err != nil {
 			return fmt.Errorf("failed to update ticket status: %v", err)
 		}
 
-		notification := db.Notification{
-			UserID:    ticket.UserID,
-			Type:      constants.NotifTypeSupport,
-			Message:   fmt.Sprintf("Ada balasan baru untuk tiket dukungan Anda #%d ('%s').", ticket.ID, ticket.Subject),
-			RelatedID: &ticket.ID,
+		if err := events.PublishSupportTicket(tx, constants.EventSupportTicketReplied, ticket, adminID, &message.ID); err != nil {
+			return err
 		}
-		util.SendNotificationToUser(ticket.UserID, dto.NotificationResponse{
-			ID:        notification.ID,
-			Type:      notification.Type,
-			Message:   notification.Message,
-			RelatedID: notification.RelatedID,
-			CreatedAt: notification.CreatedAt,
-			IsRead:    notification.IsRead,
-		})
-		tx.Create(&notification)
 
 		adminLog := db.AdminLog{
 			AdminID:    adminID,
@@ -1556,6 +1536,10 @@ func (h *AdminHandler) ReplySupportTicket(c *gin.Context) {
 	})
 
 	if err != nil {
+		if err.Error() == constants.ErrMsgUploadNotOwned {
+			util.RespondJSON(c, http.StatusBadRequest, err.Error())
+			return
+		}
 		util.RespondJSON(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -1563,13 +1547,29 @@ func (h *AdminHandler) ReplySupportTicket(c *gin.Context) {
 	util.RespondJSON(c, http.StatusOK, constants.MsgSuccessMessageSent)
 }
 
-func handleProductTransactionsOnDelete(tx *gorm.DB, product db.Product) error {
-	var pendingTransactions []db.TransactionHistory
-	if err := tx.Where("product_id = ? AND status IN (?, ?)", product.ID, constants.TrxStatusPending, constants.TrxStatusWaitingOwner).Find(&pendingTransactions).Error; err != nil {
+// deleteSellerProduct menyembunyikan dan menghapus produk milik penjual yang diblokir atau
+// dihapus, lalu membatalkan transaksi yang belum dikirim dan menyelesaikan transaksi yang
+// sudah dikirim. Transaksi dikunci sebelum produk, sama dengan urutan di CancelTransaction.
+func deleteSellerProduct(tx *gorm.DB, product db.Product, adminID uint) error {
+	var transactions []db.TransactionHistory
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ? AND status IN ?", product.ID, []constants.TransactionStatus{constants.TrxStatusPending, constants.TrxStatusWaitingOwner, constants.TrxStatusWaitingUser}).
+		Order("id").Find(&transactions).Error; err != nil {
 		return fmt.Errorf("failed to find pending transactions for product %d: %v", product.ID, err)
 	}
 
-	for _, trx := range pendingTransactions {
+	if err := service.SetProductVisibility(tx, &product, constants.ProductVisibilityOwnerAdmin, constants.VisibilityReasonAdmin, &adminID); err != nil {
+		return fmt.Errorf("failed to hide product %d: %v", product.ID, err)
+	}
+	if err := tx.Delete(&product).Error; err != nil {
+		return fmt.Errorf("failed to soft delete product %d: %v", product.ID, err)
+	}
+
+	for _, trx := range transactions {
+		if trx.Status == constants.TrxStatusWaitingUser {
+			continue
+		}
+		previous := trx.Status
 		var buyer db.User
 		if err := tx.First(&buyer, trx.UserID).Error; err != nil {
 			return fmt.Errorf("failed to find buyer for transaction %d: %v", trx.ID, err)
@@ -1579,74 +1579,69 @@ func handleProductTransactionsOnDelete(tx *gorm.DB, product db.Product) error {
 		if err := tx.Model(&buyer).Update("balance", newBalance).Error; err != nil {
 			return fmt.Errorf("failed to refund buyer for transaction %d: %v", trx.ID, err)
 		}
-		tx.Create(&db.BalanceHistory{
+		if err := tx.Create(&db.BalanceHistory{
 			UserID:       buyer.ID,
 			Description:  fmt.Sprintf("Refund dari pembatalan produk '%s' (ID: %d) karena penjual dihapus/diblokir", product.Title, product.ID),
 			Amount:       int(refundAmount),
 			LastBalance:  buyer.Balance,
 			FinalBalance: newBalance,
 			Status:       constants.BalanceStatusRefund,
-		})
+		}).Error; err != nil {
+			return fmt.Errorf("failed to record refund for transaction %d: %v", trx.ID, err)
+		}
 
-		tx.Model(&trx).Updates(map[string]interface{}{
-			"status":        constants.TrxStatusCancel,
-			"is_solved":     true,
+		if err := tx.Model(&trx).Updates(map[string]interface{}{
+			"status":         constants.TrxStatusCancel,
+			"is_solved":      true,
 			"receipt_status": constants.ReceiptCanceled,
-		})
+		}).Error; err != nil {
+			return fmt.Errorf("failed to cancel transaction %d: %v", trx.ID, err)
+		}
+		if err := service.ReleaseCouponRedemption(tx, trx); err != nil {
+			return fmt.Errorf("failed to release coupon for transaction %d: %v", trx.ID, err)
+		}
+		if err := service.AdjustStock(tx, &product, service.StockChange{
+			Delta:         int(trx.Quantity),
+			Cause:         constants.InventoryCauseCancelRestock,
+			Note:          "Pembatalan karena penjual dihapus/diblokir",
+			ActorID:       &adminID,
+			TransactionID: &trx.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to restock product %d for transaction %d: %v", product.ID, trx.ID, err)
+		}
 
-		notificationBuyer := db.Notification{
-			UserID:    buyer.ID,
-			Type:      constants.NotifTypePurchase,
-			Message:   fmt.Sprintf("Pembelian produk '%s' Anda (ID: %d) dibatalkan karena penjual dihapus/diblokir. Dana telah dikembalikan.", product.Title, trx.ID),
-			RelatedID: &trx.ID,
-		}
-		util.SendNotificationToUser(buyer.ID, dto.NotificationResponse{
-			ID:        notificationBuyer.ID,
-			Type:      notificationBuyer.Type,
-			Message:   notificationBuyer.Message,
-			RelatedID: notificationBuyer.RelatedID,
-			CreatedAt: notificationBuyer.CreatedAt,
-			IsRead:    notificationBuyer.IsRead,
-		})
-		tx.Create(&notificationBuyer)
+		if err := events.PublishTransaction(tx, constants.EventTransactionCanceled, trx, product.UserID, previous, events.ReasonSellerRemoved); err != nil {
+			return err
+		}
 	}
 
-	var waitingUserTransactions []db.TransactionHistory
-	if err := tx.Where("product_id = ? AND status = ?", product.ID, constants.TrxStatusWaitingUser).Find(&waitingUserTransactions).Error; err != nil {
-		return fmt.Errorf("failed to find waiting_users transactions for product %d: %v", product.ID, err)
-	}
-	for _, trx := range waitingUserTransactions {
-		sellerReceiveAmount := trx.TotalPrice - trx.EcommerceTax
-		tx.Create(&db.BalanceHistory{
+	for _, trx := range transactions {
+		if trx.Status != constants.TrxStatusWaitingUser {
+			continue
+		}
+		sellerReceiveAmount := trx.SellerPayoutAmount()
+		if err := tx.Create(&db.BalanceHistory{
 			UserID:       product.UserID,
 			Description:  fmt.Sprintf("Pembayaran penjualan produk '%s' (ID: %d) karena penjual dihapus/diblokir", product.Title, product.ID),
 			Amount:       int(sellerReceiveAmount),
 			LastBalance:  0,
 			FinalBalance: 0,
 			Status:       constants.BalanceStatusCredit,
-		})
+		}).Error; err != nil {
+			return fmt.Errorf("failed to record payout for transaction %d: %v", trx.ID, err)
+		}
 
-		tx.Model(&trx).Updates(map[string]interface{}{
-			"status":        constants.TrxStatusSuccess,
-			"is_solved":     true,
+		if err := tx.Model(&trx).Updates(map[string]interface{}{
+			"status":         constants.TrxStatusSuccess,
+			"is_solved":      true,
 			"receipt_status": constants.ReceiptCompleted,
-		})
+		}).Error; err != nil {
+			return fmt.Errorf("failed to complete transaction %d: %v", trx.ID, err)
+		}
 
-		notificationBuyer := db.Notification{
-			UserID:    trx.UserID,
-			Type:      constants.NotifTypePurchase,
-			Message:   fmt.Sprintf("Pembelian produk '%s' Anda (ID: %d) telah diselesaikan karena penjual dihapus/diblokir. Penjual telah dibayar.", product.Title, trx.ID),
-			RelatedID: &trx.ID,
-		}
-		util.SendNotificationToUser(trx.UserID, dto.NotificationResponse{
-			ID:        notificationBuyer.ID,
-			Type:      notificationBuyer.Type,
-			Message:   notificationBuyer.Message,
-			RelatedID: notificationBuyer.RelatedID,
-			CreatedAt: notificationBuyer.CreatedAt,
-			IsRead:    notificationBuyer.IsRead,
-		})
-		tx.Create(&notificationBuyer)
+		if err := events.PublishTransaction(tx, constants.EventTransactionCompleted, trx, product.UserID, constants.TrxStatusWaitingUser, events.ReasonSellerRemoved); err != nil {
+			return err
+		}
 	}
 	return nil
 }
\ No newline at end of file
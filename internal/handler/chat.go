@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/model/dto"
+	"portolio-backend/internal/service"
+	"portolio-backend/internal/util"
+)
+
+const (
+	defaultChatPageSize = 30
+	maxChatPageSize     = 100
+)
+
+// GetChatMessages menampilkan riwayat chat transaksi untuk pembeli dan penjual, termasuk
+// transaksi yang sudah selesai. Pesan diurutkan dari yang terlama. Tanpa cursor, halaman
+// berisi pesan terbaru; before=<id> memuat pesan yang lebih lama dan after=<id> memuat
+// pesan yang lebih baru, misalnya setelah klien tersambung ulang. next_cursor dipakai
+// sebagai nilai before/after berikutnya selama has_more bernilai true.
+func (h *ShopHandler) GetChatMessages(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	transactionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidTransactionID)
+		return
+	}
+	transaction, status, err := loadChatTransaction(h.db, uint(transactionID), userID)
+	if err != nil {
+		util.RespondJSON(c, status, err.Error())
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultChatPageSize)))
+	if err != nil || limit < 1 || limit > maxChatPageSize {
+		limit = defaultChatPageSize
+	}
+	before, after := c.Query("before"), c.Query("after")
+	if before != "" && after != "" {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidChatCursor)
+		return
+	}
+
+	beforeID, okBefore := parseChatCursor(before)
+	afterID, okAfter := parseChatCursor(after)
+	if !okBefore || !okAfter {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgBadRequest)
+		return
+	}
+
+	page, err := service.ListChatMessages(h.db, transaction.ID, beforeID, afterID, limit)
+	if err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	response := dto.GetChatMessagesResponse{
+		TransactionID: transaction.ID,
+		Messages:      make([]dto.ChatMessageResponse, len(page.Messages)),
+		HasMore:       page.HasMore,
+		NextCursor:    page.NextCursor,
+	}
+	for i, message := range page.Messages {
+		response.Messages[i] = buildChatMessageResponse(message, userID)
+	}
+	h.db.Model(&db.ChatMessage{}).
+		Where("transaction_id = ? AND receiver_id = ? AND is_read = ?", transaction.ID, userID, false).
+		Count(&response.UnreadCount)
+
+	util.RespondJSON(c, http.StatusOK, response)
+}
+
+// MarkChatMessagesRead menandai pesan yang diterima pengguna di transaksi sebagai dibaca
+// sampai up_to_id (kosong berarti semua), lalu mengirim tanda baca ke lawan bicara
+func (h *ShopHandler) MarkChatMessagesRead(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	transactionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidTransactionID)
+		return
+	}
+
+	var req dto.RequestMarkChatRead
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			util.RespondJSON(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	transaction, status, err := loadChatTransaction(h.db, uint(transactionID), userID)
+	if err != nil {
+		util.RespondJSON(c, status, err.Error())
+		return
+	}
+
+	receipt, err := markChatRead(h.db, transaction, userID, req.UpToID)
+	if err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	util.RespondJSON(c, http.StatusOK, gin.H{
+		"message":     constants.MsgSuccessChatMarkedRead,
+		"message_ids": receipt.MessageIDs,
+		"read_at":     receipt.ReadAt,
+	})
+}
+
+// parseChatCursor membaca ID pesan dari query before/after; nilai kosong berarti tanpa cursor
+func parseChatCursor(value string) (uint, bool) {
+	if value == "" {
+		return 0, true
+	}
+	cursorID, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || cursorID == 0 {
+		return 0, false
+	}
+	return uint(cursorID), true
+}
+
+// loadChatTransaction memuat transaksi beserta produknya dan memastikan userID adalah
+// pembeli atau penjualnya, mengembalikan status HTTP bila gagal. Produk yang sudah dihapus
+// tetap dimuat agar penjual masih dapat membuka riwayat chat transaksinya.
+func loadChatTransaction(dbConn *gorm.DB, transactionID, userID uint) (db.TransactionHistory, int, error) {
+	var transaction db.TransactionHistory
+	if err := dbConn.Preload("Product", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).First(&transaction, transactionID).Error; err != nil {
+		return transaction, http.StatusNotFound, fmt.Errorf(constants.ErrMsgTransactionNotFound)
+	}
+	if transaction.UserID != userID && transaction.Product.UserID != userID {
+		return transaction, http.StatusForbidden, fmt.Errorf(constants.ErrMsgNotChatParticipant)
+	}
+	return transaction, http.StatusOK, nil
+}
+
+// markChatRead menjalankan service.MarkChatRead lalu mengirim tanda baca "chat_read" ke
+// lawan bicara melalui WebSocket bila ada pesan yang baru ditandai
+func markChatRead(dbConn *gorm.DB, transaction db.TransactionHistory, readerID, upToID uint) (dto.ChatReadReceiptWebSocketResponse, error) {
+	receipt := dto.ChatReadReceiptWebSocketResponse{
+		Type:          "chat_read",
+		TransactionID: transaction.ID,
+		ReaderID:      readerID,
+		ReadAt:        time.Now(),
+	}
+
+	err := dbConn.Transaction(func(tx *gorm.DB) error {
+		messageIDs, err := service.MarkChatRead(tx, transaction.ID, readerID, upToID, receipt.ReadAt)
+		receipt.MessageIDs = messageIDs
+		return err
+	})
+	if err != nil {
+		return receipt, err
+	}
+	if receipt.MessageIDs == nil {
+		receipt.MessageIDs = []uint{}
+	}
+	if len(receipt.MessageIDs) == 0 {
+		return receipt, nil
+	}
+
+	otherID := transaction.UserID
+	if otherID == readerID {
+		otherID = transaction.Product.UserID
+	}
+	receiptMsg, _ := json.Marshal(receipt)
+	util.SendToUser(otherID, receiptMsg)
+	return receipt, nil
+}
+
+// buildChatMessageResponse menyusun pesan chat dengan URL file yang ditandatangani untuk viewerID
+func buildChatMessageResponse(message db.ChatMessage, viewerID uint) dto.ChatMessageResponse {
+	return dto.ChatMessageResponse{
+		ID:            message.ID,
+		TransactionID: message.TransactionID,
+		SenderID:      message.SenderID,
+		SenderName:    message.Sender.FullName,
+		MessageType:   message.MessageType,
+		Content:       message.Content,
+		FileURL:       util.SignMediaURL(message.FileURL, viewerID),
+		IsRead:        message.IsRead,
+		ReadAt:        message.ReadAt,
+		CreatedAt:     message.CreatedAt,
+	}
+}
@@ -0,0 +1,430 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/model/dto"
+	"portolio-backend/internal/service"
+	"portolio-backend/internal/util"
+)
+
+// CreateCouponAdmin membuat kupon platform. Admin dapat memilih penanggung diskon
+// dan membatasi kupon ke penjual tertentu melalui seller_id.
+func (h *AdminHandler) CreateCouponAdmin(c *gin.Context) {
+	adminIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	adminID := adminIDRaw.(uint)
+
+	var req dto.RequestCreateCoupon
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if req.FundedBy == "" {
+		req.FundedBy = constants.CouponFundedByPlatform
+	}
+	if req.FundedBy == constants.CouponFundedBySeller && req.SellerID == nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgCouponSellerRequired)
+		return
+	}
+
+	coupon, status, err := createCoupon(h.db, req, adminID)
+	if err != nil {
+		util.RespondJSON(c, status, err.Error())
+		return
+	}
+
+	adminLog := db.AdminLog{
+		AdminID:    adminID,
+		Action:     "create_coupon",
+		TargetType: "coupon",
+		TargetID:   &coupon.ID,
+		Details:    db.JSONB{"code": coupon.Code, "funded_by": coupon.FundedBy, "discount_type": coupon.DiscountType, "discount_value": coupon.DiscountValue},
+		IPAddress:  c.ClientIP(),
+	}
+	h.db.Create(&adminLog)
+
+	util.RespondJSON(c, http.StatusCreated, gin.H{
+		"message": constants.MsgSuccessCouponCreated,
+		"coupon":  buildCouponResponse(*coupon),
+	})
+}
+
+func (h *AdminHandler) GetCouponsAdmin(c *gin.Context) {
+	query := h.db.Model(&db.Coupon{})
+	if sellerIDStr := c.Query("seller_id"); sellerIDStr != "" {
+		query = query.Where("seller_id = ?", sellerIDStr)
+	}
+	if fundedBy := c.Query("funded_by"); fundedBy != "" {
+		query = query.Where("funded_by = ?", fundedBy)
+	}
+	respondCouponList(c, query)
+}
+
+func (h *AdminHandler) PatchCouponAdmin(c *gin.Context) {
+	adminIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	adminID := adminIDRaw.(uint)
+
+	couponID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgBadRequest)
+		return
+	}
+
+	var req dto.RequestPatchCoupon
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var coupon db.Coupon
+	if err := h.db.First(&coupon, couponID).Error; err != nil {
+		util.RespondJSON(c, http.StatusNotFound, constants.ErrMsgCouponNotFound)
+		return
+	}
+
+	updates, ok := couponPatchUpdates(req)
+	if !ok {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgNoFieldsToUpdate)
+		return
+	}
+	if err := h.db.Model(&coupon).Updates(updates).Error; err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	adminLog := db.AdminLog{
+		AdminID:    adminID,
+		Action:     "patch_coupon",
+		TargetType: "coupon",
+		TargetID:   &coupon.ID,
+		Details:    db.JSONB{"code": coupon.Code, "updates": updates},
+		IPAddress:  c.ClientIP(),
+	}
+	h.db.Create(&adminLog)
+
+	util.RespondJSON(c, http.StatusOK, constants.MsgSuccessCouponUpdated)
+}
+
+func (h *AdminHandler) GetCouponUsageAdmin(c *gin.Context) {
+	couponID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgBadRequest)
+		return
+	}
+
+	var coupon db.Coupon
+	if err := h.db.First(&coupon, couponID).Error; err != nil {
+		util.RespondJSON(c, http.StatusNotFound, constants.ErrMsgCouponNotFound)
+		return
+	}
+	respondCouponUsage(c, h.db, coupon)
+}
+
+// CreateSellerCoupon membuat kupon yang ditanggung penjual dan hanya berlaku untuk produknya sendiri
+func (h *ShopHandler) CreateSellerCoupon(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	var req dto.RequestCreateCoupon
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, err)
+		return
+	}
+	req.FundedBy = constants.CouponFundedBySeller
+	req.SellerID = &userID
+
+	if len(req.ProductIDs) > 0 {
+		var owned int64
+		h.db.Model(&db.Product{}).Where("id IN ? AND user_id = ?", req.ProductIDs, userID).Count(&owned)
+		if int(owned) != len(req.ProductIDs) {
+			util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgProductNotFound)
+			return
+		}
+	}
+
+	coupon, status, err := createCoupon(h.db, req, userID)
+	if err != nil {
+		util.RespondJSON(c, status, err.Error())
+		return
+	}
+
+	util.RespondJSON(c, http.StatusCreated, gin.H{
+		"message": constants.MsgSuccessCouponCreated,
+		"coupon":  buildCouponResponse(*coupon),
+	})
+}
+
+func (h *ShopHandler) GetSellerCoupons(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	respondCouponList(c, h.db.Model(&db.Coupon{}).Where("seller_id = ?", userID))
+}
+
+func (h *ShopHandler) PatchSellerCoupon(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	couponID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgBadRequest)
+		return
+	}
+
+	var req dto.RequestPatchCoupon
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var coupon db.Coupon
+	if err := h.db.First(&coupon, "id = ? AND seller_id = ? AND created_by = ?", couponID, userID, userID).Error; err != nil {
+		util.RespondJSON(c, http.StatusNotFound, constants.ErrMsgCouponNotFound)
+		return
+	}
+
+	updates, ok := couponPatchUpdates(req)
+	if !ok {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgNoFieldsToUpdate)
+		return
+	}
+	if err := h.db.Model(&coupon).Updates(updates).Error; err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	util.RespondJSON(c, http.StatusOK, constants.MsgSuccessCouponUpdated)
+}
+
+func (h *ShopHandler) GetSellerCouponUsage(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	couponID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgBadRequest)
+		return
+	}
+
+	var coupon db.Coupon
+	if err := h.db.First(&coupon, "id = ? AND seller_id = ?", couponID, userID).Error; err != nil {
+		util.RespondJSON(c, http.StatusNotFound, constants.ErrMsgCouponNotFound)
+		return
+	}
+	respondCouponUsage(c, h.db, coupon)
+}
+
+// createCoupon memvalidasi dan menyimpan kupon baru, mengembalikan status HTTP bila gagal
+func createCoupon(dbConn *gorm.DB, req dto.RequestCreateCoupon, createdBy uint) (*db.Coupon, int, error) {
+	if req.DiscountType == constants.CouponDiscountPercentage && req.DiscountValue > 100 {
+		return nil, http.StatusBadRequest, fmt.Errorf(constants.ErrMsgCouponInvalidValue)
+	}
+	if req.StartsAt != nil && req.ExpiresAt != nil && !req.ExpiresAt.After(*req.StartsAt) {
+		return nil, http.StatusBadRequest, fmt.Errorf(constants.ErrMsgCouponExpired)
+	}
+
+	code := service.NormalizeCouponCode(req.Code)
+	var existing int64
+	dbConn.Unscoped().Model(&db.Coupon{}).Where("code = ?", code).Count(&existing)
+	if existing > 0 {
+		return nil, http.StatusConflict, fmt.Errorf(constants.ErrMsgCouponCodeTaken)
+	}
+
+	productIDs := make([]string, len(req.ProductIDs))
+	for i, id := range req.ProductIDs {
+		productIDs[i] = strconv.FormatUint(uint64(id), 10)
+	}
+
+	coupon := db.Coupon{
+		Code:          code,
+		Description:   req.Description,
+		DiscountType:  req.DiscountType,
+		DiscountValue: req.DiscountValue,
+		MaxDiscount:   req.MaxDiscount,
+		FundedBy:      req.FundedBy,
+		SellerID:      req.SellerID,
+		CreatedBy:     createdBy,
+		MinSpend:      req.MinSpend,
+		UsageLimit:    req.UsageLimit,
+		PerUserLimit:  req.PerUserLimit,
+		StartsAt:      req.StartsAt,
+		ExpiresAt:     req.ExpiresAt,
+		ProductIDs:    strings.Join(productIDs, ","),
+		Categories:    req.Categories,
+		IsActive:      true,
+	}
+	if err := dbConn.Create(&coupon).Error; err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("Gagal menyimpan kupon: %v", err)
+	}
+	return &coupon, http.StatusCreated, nil
+}
+
+func couponPatchUpdates(req dto.RequestPatchCoupon) (map[string]interface{}, bool) {
+	updates := make(map[string]interface{})
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+	if req.UsageLimit != nil {
+		updates["usage_limit"] = *req.UsageLimit
+	}
+	if req.ExpiresAt != nil {
+		updates["expires_at"] = *req.ExpiresAt
+	}
+	return updates, len(updates) > 0
+}
+
+func respondCouponList(c *gin.Context, query *gorm.DB) {
+	pageStr := c.DefaultQuery("page", "1")
+	limitStr := c.DefaultQuery("limit", "20")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	if isActive := c.Query("is_active"); isActive != "" {
+		query = query.Where("is_active = ?", isActive == "true")
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var coupons []db.Coupon
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&coupons).Error; err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	responseCoupons := make([]dto.CouponResponse, len(coupons))
+	for i, coupon := range coupons {
+		responseCoupons[i] = buildCouponResponse(coupon)
+	}
+
+	util.RespondJSON(c, http.StatusOK, dto.GetCouponsResponse{
+		TotalRecords: total,
+		Page:         page,
+		Limit:        limit,
+		Coupons:      responseCoupons,
+	})
+}
+
+func respondCouponUsage(c *gin.Context, dbConn *gorm.DB, coupon db.Coupon) {
+	pageStr := c.DefaultQuery("page", "1")
+	limitStr := c.DefaultQuery("limit", "20")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	var appliedCount, releasedCount int64
+	dbConn.Model(&db.CouponRedemption{}).Where("coupon_id = ? AND status = ?", coupon.ID, constants.CouponRedemptionApplied).Count(&appliedCount)
+	dbConn.Model(&db.CouponRedemption{}).Where("coupon_id = ? AND status = ?", coupon.ID, constants.CouponRedemptionReleased).Count(&releasedCount)
+
+	var totalDiscount uint
+	dbConn.Model(&db.CouponRedemption{}).
+		Where("coupon_id = ? AND status = ?", coupon.ID, constants.CouponRedemptionApplied).
+		Select("COALESCE(SUM(discount_amount), 0)").Scan(&totalDiscount)
+
+	var redemptions []db.CouponRedemption
+	if err := dbConn.Where("coupon_id = ?", coupon.ID).Order("created_at DESC").Limit(limit).Offset(offset).Find(&redemptions).Error; err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	responseRedemptions := make([]dto.CouponRedemptionResponse, len(redemptions))
+	for i, r := range redemptions {
+		responseRedemptions[i] = dto.CouponRedemptionResponse{
+			ID:             r.ID,
+			UserID:         r.UserID,
+			TransactionID:  r.TransactionID,
+			DiscountAmount: r.DiscountAmount,
+			Status:         r.Status,
+			CreatedAt:      r.CreatedAt,
+		}
+	}
+
+	util.RespondJSON(c, http.StatusOK, dto.CouponUsageResponse{
+		Coupon:        buildCouponResponse(coupon),
+		TotalDiscount: totalDiscount,
+		AppliedCount:  appliedCount,
+		ReleasedCount: releasedCount,
+		TotalRecords:  appliedCount + releasedCount,
+		Page:          page,
+		Limit:         limit,
+		Redemptions:   responseRedemptions,
+	})
+}
+
+func buildCouponResponse(coupon db.Coupon) dto.CouponResponse {
+	productIDs := []uint{}
+	for _, idStr := range service.SplitCSV(coupon.ProductIDs) {
+		if id, err := strconv.ParseUint(idStr, 10, 64); err == nil {
+			productIDs = append(productIDs, uint(id))
+		}
+	}
+
+	return dto.CouponResponse{
+		ID:            coupon.ID,
+		Code:          coupon.Code,
+		Description:   coupon.Description,
+		DiscountType:  coupon.DiscountType,
+		DiscountValue: coupon.DiscountValue,
+		MaxDiscount:   coupon.MaxDiscount,
+		FundedBy:      coupon.FundedBy,
+		SellerID:      coupon.SellerID,
+		MinSpend:      coupon.MinSpend,
+		UsageLimit:    coupon.UsageLimit,
+		PerUserLimit:  coupon.PerUserLimit,
+		UsedCount:     coupon.UsedCount,
+		StartsAt:      coupon.StartsAt,
+		ExpiresAt:     coupon.ExpiresAt,
+		ProductIDs:    productIDs,
+		Categories:    filterCategories(coupon.Categories),
+		IsActive:      coupon.IsActive,
+		CreatedAt:     coupon.CreatedAt,
+	}
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/model/dto"
+	"portolio-backend/internal/notify"
+	"portolio-backend/internal/service"
+	"portolio-backend/internal/util"
+)
+
+// GetInventoryMovements menampilkan log perubahan stok produk milik penjual,
+// dapat difilter berdasarkan penyebab melalui query cause.
+func (h *ShopHandler) GetInventoryMovements(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgBadRequest)
+		return
+	}
+
+	var product db.Product
+	if err := h.db.First(&product, "id = ? AND user_id = ?", productID, userID).Error; err != nil {
+		util.RespondJSON(c, http.StatusNotFound, constants.ErrMsgProductNotFound)
+		return
+	}
+
+	pageStr := c.DefaultQuery("page", "1")
+	limitStr := c.DefaultQuery("limit", "20")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := h.db.Where("product_id = ?", product.ID)
+	if cause := c.Query("cause"); cause != "" {
+		query = query.Where("cause = ?", cause)
+	}
+
+	var total int64
+	query.Model(&db.InventoryMovement{}).Count(&total)
+
+	var movements []db.InventoryMovement
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&movements).Error; err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	responseMovements := make([]dto.InventoryMovementResponse, len(movements))
+	for i, m := range movements {
+		responseMovements[i] = dto.InventoryMovementResponse{
+			ID:            m.ID,
+			ProductID:     m.ProductID,
+			Change:        m.Change,
+			StockBefore:   m.StockBefore,
+			StockAfter:    m.StockAfter,
+			Cause:         m.Cause,
+			Note:          m.Note,
+			ActorID:       m.ActorID,
+			TransactionID: m.TransactionID,
+			CreatedAt:     m.CreatedAt,
+		}
+	}
+
+	util.RespondJSON(c, http.StatusOK, dto.GetInventoryMovementsResponse{
+		TotalRecords: total,
+		Page:         page,
+		Limit:        limit,
+		Movements:    responseMovements,
+	})
+}
+
+// AdjustProductStock menambah atau mengurangi stok secara manual dengan catatan,
+// misalnya untuk barang rusak atau hasil stock opname.
+func (h *ShopHandler) AdjustProductStock(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgBadRequest)
+		return
+	}
+
+	var req dto.RequestAdjustStock
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var product db.Product
+	err = notify.Transaction(h.db, func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&product, "id = ? AND user_id = ?", productID, userID).Error; err != nil {
+			return fmt.Errorf(constants.ErrMsgProductNotFound)
+		}
+
+		if req.Change < 0 && int(product.Stock)+req.Change < 0 {
+			return fmt.Errorf(constants.ErrMsgStockAdjustmentInvalid)
+		}
+		if req.Change > 0 && int(product.Stock)+req.Change > 10000 {
+			return fmt.Errorf(constants.ErrMsgStockAdjustmentInvalid)
+		}
+
+		return service.AdjustStock(tx, &product, service.StockChange{
+			Delta:   req.Change,
+			Cause:   constants.InventoryCauseAdjustment,
+			Note:    req.Note,
+			ActorID: &userID,
+		})
+	})
+
+	if err != nil {
+		switch err.Error() {
+		case constants.ErrMsgProductNotFound:
+			util.RespondJSON(c, http.StatusNotFound, err.Error())
+		case constants.ErrMsgStockAdjustmentInvalid:
+			util.RespondJSON(c, http.StatusBadRequest, err.Error())
+		default:
+			util.RespondJSON(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	util.RespondJSON(c, http.StatusOK, gin.H{
+		"message":         constants.MsgSuccessStockAdjusted,
+		"product_id":      product.ID,
+		"stock":           product.Stock,
+		"available_stock": service.AvailableStock(product),
+		"visibility":      product.Visibility,
+	})
+}
@@ -1,15 +1,20 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
-	"os"
-	"path/filepath"
+	"path"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
 	"portolio-backend/configs/constants"
+	"portolio-backend/internal/middlewares"
 	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/storage"
 	"portolio-backend/internal/util"
 )
 
@@ -39,19 +44,20 @@ func (h *MediaHandler) ServeProtectedMedia(c *gin.Context) {
 		return
 	}
 
-	fullPath := filepath.Join("./media", mediaType, filename)
+	key := path.Join("media", mediaType, path.Base(filename))
 
-	// Periksa apakah file ada di disk
-	if _, err := os.Stat(fullPath); os.IsNotExist(err) {
+	// Periksa apakah file ada di storage
+	if _, err := util.MediaStorage.Stat(c.Request.Context(), key); err != nil {
 		util.RespondJSON(c, http.StatusNotFound, constants.ErrMsgNotFound)
 		return
 	}
 
-	// Logika otorisasi
-	isAuthorized := false
+	// Logika otorisasi. URL bertanda tangan sudah diverifikasi middleware SignedMedia
+	// sehingga tidak perlu mencocokkan file_url ke database.
+	isAuthorized := c.GetBool(middlewares.SignedMediaKey)
 	var err error
 
-	if mediaType == "chat" {
+	if !isAuthorized && mediaType == "chat" {
 		// Periksa apakah pengguna adalah pengirim atau penerima pesan chat dengan file ini
 		var chatMessage db.ChatMessage
 		// Pastikan FileURL di DB disimpan dengan format /media/chat/namafile.ext
@@ -62,7 +68,7 @@ func (h *MediaHandler) ServeProtectedMedia(c *gin.Context) {
 			util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
 			return
 		}
-	} else if mediaType == "support" {
+	} else if !isAuthorized && mediaType == "support" {
 		// Periksa apakah pengguna adalah pembuat tiket atau admin yang ditugaskan untuk pesan support dengan file ini
 		var supportMessage db.SupportMessage
 		// Pastikan FileURL di DB disimpan dengan format /media/support/namafile.ext
@@ -83,6 +89,62 @@ func (h *MediaHandler) ServeProtectedMedia(c *gin.Context) {
 		return
 	}
 
+	// File media memakai nama acak sehingga isinya tidak pernah berubah; cache browser
+	// dibatasi sampai tautan bertanda tangan kedaluwarsa.
+	cacheControl := "private, max-age=300"
+	if expiresAtRaw, ok := c.Get("MEDIA_EXPIRES_AT"); ok {
+		maxAge := int(time.Until(expiresAtRaw.(time.Time)).Seconds())
+		cacheControl = fmt.Sprintf("private, max-age=%d", max(maxAge, 0))
+	}
+
 	// Sajikan file
-	c.File(fullPath)
+	serveStoredMedia(c, key, cacheControl)
+}
+
+// ServeProductMedia menyajikan gambar produk secara publik dari MediaStorage
+func (h *MediaHandler) ServeProductMedia(c *gin.Context) {
+	serveStoredMedia(c, path.Join("media/products", path.Base(c.Param("filename"))), "public, max-age=31536000, immutable")
+}
+
+// serveStoredMedia mengarahkan ke signed URL bila backend mendukungnya (S3), selain itu
+// file disajikan dari storage dengan dukungan Range, ETag, dan If-Modified-Since.
+func serveStoredMedia(c *gin.Context, key, cacheControl string) {
+	ctx := c.Request.Context()
+
+	signedURL, err := util.MediaStorage.SignedURL(ctx, key, constants.DefaultSignedURLTTL)
+	if err == nil {
+		c.Header("Cache-Control", "no-store")
+		c.Redirect(http.StatusFound, signedURL)
+		return
+	}
+	if !errors.Is(err, storage.ErrSignedURLUnsupported) {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	reader, object, err := util.MediaStorage.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+			util.RespondJSON(c, http.StatusNotFound, constants.ErrMsgNotFound)
+			return
+		}
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+	defer reader.Close()
+
+	contentType := object.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Cache-Control", cacheControl)
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("ETag", fmt.Sprintf(`"%x-%x"`, object.ModTime.UnixNano(), object.Size))
+
+	if seeker, ok := reader.(io.ReadSeeker); ok {
+		http.ServeContent(c.Writer, c.Request, path.Base(key), object.ModTime, seeker)
+		return
+	}
+	c.DataFromReader(http.StatusOK, object.Size, contentType, reader, nil)
 }
\ No newline at end of file
@@ -0,0 +1,346 @@
+package handler
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/model/dto"
+	"portolio-backend/internal/notify"
+	"portolio-backend/internal/util"
+)
+
+type NotificationHandler struct {
+	db *gorm.DB
+}
+
+func NewNotificationHandler(db *gorm.DB) *NotificationHandler {
+	return &NotificationHandler{db: db}
+}
+
+// GetNotifications menampilkan notifikasi pengguna terbaru lebih dulu. Filter opsional:
+// type (dipisah koma) dan is_read (true/false).
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := h.db.Model(&db.Notification{}).Where("user_id = ?", userID)
+
+	types, ok := parseNotificationTypes(c.Query("type"))
+	if !ok {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidNotificationType)
+		return
+	}
+	if len(types) > 0 {
+		query = query.Where("type IN ?", types)
+	}
+
+	switch c.Query("is_read") {
+	case "":
+	case "true":
+		query = query.Where("is_read = ?", true)
+	case "false":
+		query = query.Where("is_read = ?", false)
+	default:
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidIsReadParam)
+		return
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var notifications []db.Notification
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&notifications).Error; err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	lang := util.RequestLanguage(c)
+	responses := make([]dto.NotificationResponse, len(notifications))
+	for i, notif := range notifications {
+		responses[i] = notify.Response(notif, lang)
+	}
+
+	util.RespondJSON(c, http.StatusOK, dto.GetNotificationsResponse{
+		TotalRecords:  total,
+		Page:          page,
+		Limit:         limit,
+		UnreadCount:   h.unreadCount(userID),
+		Notifications: responses,
+	})
+}
+
+// GetUnreadCount mengembalikan jumlah notifikasi belum dibaca, total dan per tipe
+func (h *NotificationHandler) GetUnreadCount(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	var rows []struct {
+		Type  constants.NotificationType
+		Count int64
+	}
+	if err := h.db.Model(&db.Notification{}).
+		Select("type, COUNT(*) AS count").
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Group("type").Scan(&rows).Error; err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	response := dto.UnreadNotificationCountResponse{ByType: make(map[constants.NotificationType]int64, len(rows))}
+	for _, row := range rows {
+		response.ByType[row.Type] = row.Count
+		response.UnreadCount += row.Count
+	}
+
+	util.RespondJSON(c, http.StatusOK, response)
+}
+
+// MarkNotificationRead menandai satu notifikasi sebagai sudah dibaca
+func (h *NotificationHandler) MarkNotificationRead(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	notificationID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgBadRequest)
+		return
+	}
+
+	var notification db.Notification
+	if err := h.db.First(&notification, "id = ? AND user_id = ?", notificationID, userID).Error; err != nil {
+		util.RespondJSON(c, http.StatusNotFound, constants.ErrMsgNotificationNotFound)
+		return
+	}
+
+	h.markRead(c, userID, h.db.Where("id = ?", notification.ID))
+}
+
+// MarkNotificationsRead menandai beberapa notifikasi (ids) sebagai sudah dibaca. ID milik
+// pengguna lain diabaikan.
+func (h *NotificationHandler) MarkNotificationsRead(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	var req dto.RequestMarkNotificationsRead
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	h.markRead(c, userID, h.db.Where("id IN ?", req.IDs))
+}
+
+// MarkAllNotificationsRead menandai seluruh notifikasi sebagai sudah dibaca, atau hanya
+// tipe tertentu bila query type diisi.
+func (h *NotificationHandler) MarkAllNotificationsRead(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	types, ok := parseNotificationTypes(c.Query("type"))
+	if !ok {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidNotificationType)
+		return
+	}
+
+	scope := h.db.Session(&gorm.Session{})
+	if len(types) > 0 {
+		scope = scope.Where("type IN ?", types)
+	}
+	h.markRead(c, userID, scope)
+}
+
+// DeleteNotification menghapus notifikasi milik pengguna
+func (h *NotificationHandler) DeleteNotification(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	notificationID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgBadRequest)
+		return
+	}
+
+	result := h.db.Where("id = ? AND user_id = ?", notificationID, userID).Delete(&db.Notification{})
+	if result.Error != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+	if result.RowsAffected == 0 {
+		util.RespondJSON(c, http.StatusNotFound, constants.ErrMsgNotificationNotFound)
+		return
+	}
+
+	util.RespondJSON(c, http.StatusOK, constants.MsgSuccessNotificationDeleted)
+}
+
+// GetNotificationPreferences menampilkan preferensi efektif per tipe dan channel,
+// frekuensi ringkasan, beserta jam tenang pengguna
+func (h *NotificationHandler) GetNotificationPreferences(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	h.respondPreferences(c, userID, "")
+}
+
+// UpdateNotificationPreferences mengubah sebagian preferensi, frekuensi ringkasan, dan/atau jam tenang.
+// Kombinasi yang tidak dikirim tetap memakai nilai sebelumnya.
+func (h *NotificationHandler) UpdateNotificationPreferences(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	var req dto.RequestUpdateNotificationPreferences
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, err)
+		return
+	}
+	if len(req.Preferences) == 0 && len(req.Digests) == 0 && req.QuietHours == nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgNoFieldsToUpdate)
+		return
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		for _, pref := range req.Preferences {
+			if err := notify.SetPreference(tx, userID, pref.Type, pref.Channel, *pref.Enabled); err != nil {
+				return err
+			}
+		}
+		for _, digest := range req.Digests {
+			if err := notify.SetDigest(tx, userID, digest.Type, digest.Frequency); err != nil {
+				return err
+			}
+		}
+		if req.QuietHours != nil {
+			return notify.SetQuietHours(tx, userID, req.QuietHours.Enabled, req.QuietHours.Start, req.QuietHours.End, req.QuietHours.Timezone)
+		}
+		return nil
+	})
+	if err != nil {
+		switch err.Error() {
+		case constants.ErrMsgInvalidNotificationType, constants.ErrMsgInvalidNotificationChannel,
+			constants.ErrMsgNotificationChannelRequired, constants.ErrMsgInvalidQuietHours,
+			constants.ErrMsgInvalidDigestFrequency, constants.ErrMsgDigestNotAllowed:
+			util.RespondJSON(c, http.StatusBadRequest, err.Error())
+		default:
+			util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		}
+		return
+	}
+
+	h.respondPreferences(c, userID, constants.MsgSuccessNotificationPreferencesUpdated)
+}
+
+func (h *NotificationHandler) respondPreferences(c *gin.Context, userID uint, message string) {
+	prefs, err := notify.LoadPreferences(h.db, userID)
+	if err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	response := dto.NotificationPreferencesResponse{
+		Channels:    constants.NotificationChannels,
+		Preferences: prefs.Matrix(),
+		Digests:     prefs.Digests(),
+		QuietHours: dto.QuietHoursSetting{
+			Enabled:  prefs.Setting.QuietHoursEnabled,
+			Start:    prefs.Setting.QuietHoursStart,
+			End:      prefs.Setting.QuietHoursEnd,
+			Timezone: prefs.Setting.Timezone,
+		},
+	}
+	if message == "" {
+		util.RespondJSON(c, http.StatusOK, response)
+		return
+	}
+	util.RespondJSON(c, http.StatusOK, gin.H{
+		"message":     message,
+		"preferences": response,
+	})
+}
+
+func (h *NotificationHandler) markRead(c *gin.Context, userID uint, scope *gorm.DB) {
+	result := scope.Model(&db.Notification{}).
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Update("is_read", true)
+	if result.Error != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	util.RespondJSON(c, http.StatusOK, dto.MarkNotificationsReadResponse{
+		Message:     constants.MsgSuccessNotificationsRead,
+		Updated:     result.RowsAffected,
+		UnreadCount: h.unreadCount(userID),
+	})
+}
+
+func (h *NotificationHandler) unreadCount(userID uint) int64 {
+	var count int64
+	h.db.Model(&db.Notification{}).Where("user_id = ? AND is_read = ?", userID, false).Count(&count)
+	return count
+}
+
+// parseNotificationTypes mengurai daftar tipe notifikasi yang dipisah koma
+func parseNotificationTypes(raw string) ([]constants.NotificationType, bool) {
+	var types []constants.NotificationType
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		notifType := constants.NotificationType(part)
+		if !slices.Contains(constants.NotificationTypes, notifType) {
+			return nil, false
+		}
+		types = append(types, notifType)
+	}
+	return types, true
+}
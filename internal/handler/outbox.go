@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/events"
+	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/model/dto"
+	"portolio-backend/internal/util"
+)
+
+// GetOutboxEventsAdmin menampilkan event domain di outbox, terbaru lebih dulu. Filter
+// opsional: status, event_type, aggregate_type, dan aggregate_id.
+func (h *AdminHandler) GetOutboxEventsAdmin(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := h.db.Model(&db.OutboxEvent{})
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if eventType := c.Query("event_type"); eventType != "" {
+		if !slices.Contains(constants.EventTypes, constants.EventType(eventType)) {
+			util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidEventType)
+			return
+		}
+		query = query.Where("event_type = ?", eventType)
+	}
+	if aggregateType := c.Query("aggregate_type"); aggregateType != "" {
+		query = query.Where("aggregate_type = ?", aggregateType)
+	}
+	if aggregateIDStr := c.Query("aggregate_id"); aggregateIDStr != "" {
+		aggregateID, err := strconv.ParseUint(aggregateIDStr, 10, 64)
+		if err != nil {
+			util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgBadRequest)
+			return
+		}
+		query = query.Where("aggregate_id = ?", aggregateID)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var outboxEvents []db.OutboxEvent
+	if err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&outboxEvents).Error; err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	var counts []struct {
+		Status constants.OutboxStatus
+		Count  int64
+	}
+	h.db.Model(&db.OutboxEvent{}).Select("status, COUNT(*) AS count").Group("status").Scan(&counts)
+	statusCounts := make(map[constants.OutboxStatus]int64, len(counts))
+	for _, row := range counts {
+		statusCounts[row.Status] = row.Count
+	}
+
+	responses := make([]dto.OutboxEventResponse, len(outboxEvents))
+	for i, outbox := range outboxEvents {
+		responses[i] = buildOutboxEventResponse(outbox)
+	}
+
+	util.RespondJSON(c, http.StatusOK, dto.GetOutboxEventsResponse{
+		TotalRecords: total,
+		Page:         page,
+		Limit:        limit,
+		StatusCounts: statusCounts,
+		Events:       responses,
+	})
+}
+
+// GetOutboxEventAdmin menampilkan detail satu event beserta payload dan error terakhir
+func (h *AdminHandler) GetOutboxEventAdmin(c *gin.Context) {
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgBadRequest)
+		return
+	}
+
+	var outbox db.OutboxEvent
+	if err := h.db.First(&outbox, eventID).Error; err != nil {
+		util.RespondJSON(c, http.StatusNotFound, constants.ErrMsgOutboxEventNotFound)
+		return
+	}
+
+	util.RespondJSON(c, http.StatusOK, buildOutboxEventResponse(outbox))
+}
+
+// ReplayOutboxEvent menjadwalkan ulang event yang gagal atau sudah terkirim. Secara bawaan
+// hanya subscriber yang belum berhasil yang dijalankan; all=true menjalankan semua
+// subscriber dari awal.
+func (h *AdminHandler) ReplayOutboxEvent(c *gin.Context) {
+	adminIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	adminID := adminIDRaw.(uint)
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgBadRequest)
+		return
+	}
+	replayAll := c.Query("all") == "true"
+
+	var outbox db.OutboxEvent
+	if err := h.db.First(&outbox, eventID).Error; err != nil {
+		util.RespondJSON(c, http.StatusNotFound, constants.ErrMsgOutboxEventNotFound)
+		return
+	}
+
+	updates := map[string]interface{}{
+		"status":          constants.OutboxPending,
+		"attempts":        0,
+		"next_attempt_at": time.Now(),
+		"published_at":    nil,
+	}
+	if replayAll {
+		updates["completed_subscribers"] = ""
+	}
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&db.OutboxEvent{}).
+			Where("id = ? AND status IN ?", outbox.ID, []constants.OutboxStatus{constants.OutboxFailed, constants.OutboxPublished}).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf(constants.ErrMsgOutboxEventNotReplayable)
+		}
+		// Subscriber yang dijalankan ulang dari awal tidak boleh terhalang catatan sebelumnya
+		if replayAll {
+			if err := tx.Where("event_id = ?", outbox.ID).Delete(&db.EventReceipt{}).Error; err != nil {
+				return err
+			}
+		}
+
+		adminLog := db.AdminLog{
+			AdminID:    adminID,
+			Action:     "replay_outbox_event",
+			TargetType: "outbox_event",
+			TargetID:   &outbox.ID,
+			Details:    db.JSONB{"event_type": outbox.EventType, "previous_status": outbox.Status, "all_subscribers": replayAll},
+			IPAddress:  c.ClientIP(),
+		}
+		return tx.Create(&adminLog).Error
+	})
+	if err != nil {
+		if err.Error() == constants.ErrMsgOutboxEventNotReplayable {
+			util.RespondJSON(c, http.StatusConflict, constants.ErrMsgOutboxEventNotReplayable)
+			return
+		}
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	h.db.First(&outbox, outbox.ID)
+	util.RespondJSON(c, http.StatusOK, gin.H{
+		"message": constants.MsgSuccessOutboxEventReplayed,
+		"event":   buildOutboxEventResponse(outbox),
+	})
+}
+
+func buildOutboxEventResponse(outbox db.OutboxEvent) dto.OutboxEventResponse {
+	completed := events.CompletedSubscribers(outbox)
+	if completed == nil {
+		completed = []string{}
+	}
+	return dto.OutboxEventResponse{
+		ID:                   outbox.ID,
+		EventType:            outbox.EventType,
+		AggregateType:        outbox.AggregateType,
+		AggregateID:          outbox.AggregateID,
+		Payload:              outbox.Payload,
+		Status:               outbox.Status,
+		Attempts:             outbox.Attempts,
+		NextAttemptAt:        outbox.NextAttemptAt,
+		LastError:            outbox.LastError,
+		CompletedSubscribers: completed,
+		PublishedAt:          outbox.PublishedAt,
+		CreatedAt:            outbox.CreatedAt,
+	}
+}
@@ -0,0 +1,286 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/i18n"
+	"portolio-backend/internal/jobs"
+	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/model/dto"
+	"portolio-backend/internal/service"
+	"portolio-backend/internal/util"
+)
+
+// AddProductImages menambahkan gambar ke produk milik penjual, baik dari file
+// multipart "images" maupun dari images_links. Gambar ditambahkan di urutan terakhir;
+// gambar dari images_links berstatus pending sampai selesai diunduh di latar belakang.
+func (h *ShopHandler) AddProductImages(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgBadRequest)
+		return
+	}
+
+	var req dto.RequestAddProductImages
+	if err := c.ShouldBind(&req); err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var product db.Product
+	if err := h.db.First(&product, "id = ? AND user_id = ?", productID, userID).Error; err != nil {
+		util.RespondJSON(c, http.StatusNotFound, constants.ErrMsgProductNotFound)
+		return
+	}
+
+	var productImages []db.ProductImage
+	form, err := c.MultipartForm()
+	if err == nil && form != nil {
+		for _, file := range form.File["images"] {
+			image, err := service.SaveProductImageUpload(file)
+			if err != nil {
+				removeProductImageFiles(h.db, productImages)
+				util.RespondJSON(c, http.StatusBadRequest, i18n.Message{Key: "error.image_upload_failed", Params: i18n.Params{"error": err.Error()}})
+				return
+			}
+			image.AltText = req.AltText
+			productImages = append(productImages, image)
+		}
+	}
+	for _, link := range req.ImagesLinks {
+		image, err := service.PendingProductImage(link)
+		if err != nil {
+			removeProductImageFiles(h.db, productImages)
+			util.RespondJSON(c, http.StatusBadRequest, i18n.Message{Key: "error.image_url_failed", Params: i18n.Params{"error": err.Error()}})
+			return
+		}
+		image.AltText = req.AltText
+		productImages = append(productImages, image)
+	}
+
+	if len(productImages) == 0 {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgProductImageRequired)
+		return
+	}
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		// Kunci produk agar dua unggahan bersamaan tidak melewati batas jumlah gambar.
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&db.Product{}, product.ID).Error; err != nil {
+			return err
+		}
+		return service.AddProductImages(tx, product.ID, productImages)
+	})
+	if err != nil {
+		removeProductImageFiles(h.db, productImages)
+		if strings.HasPrefix(err.Error(), constants.ErrMsgProductImageLimit) {
+			util.RespondJSON(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	if len(req.ImagesLinks) > 0 {
+		jobs.WakeImageFetcher()
+	}
+
+	h.respondProductImages(c, http.StatusCreated, constants.MsgSuccessProductImagesAdded, product.ID)
+}
+
+// PatchProductImage mengubah alt text dan/atau menjadikan gambar sebagai gambar utama
+func (h *ShopHandler) PatchProductImage(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	var req dto.RequestPatchProductImage
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	image, ok := h.findOwnedProductImage(c, userID)
+	if !ok {
+		return
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if req.AltText != nil {
+			if err := tx.Model(&image).Update("alt_text", strings.TrimSpace(*req.AltText)).Error; err != nil {
+				return err
+			}
+		}
+		if req.IsPrimary != nil && *req.IsPrimary {
+			return service.SetPrimaryImage(tx, &image)
+		}
+		return nil
+	})
+	if err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	h.respondProductImages(c, http.StatusOK, constants.MsgSuccessProductImageUpdated, image.ProductID)
+}
+
+// DeleteProductImage menghapus gambar produk beserta filenya di media/products
+func (h *ShopHandler) DeleteProductImage(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	image, ok := h.findOwnedProductImage(c, userID)
+	if !ok {
+		return
+	}
+
+	if err := h.db.Transaction(func(tx *gorm.DB) error {
+		return service.DeleteProductImage(tx, image)
+	}); err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	removeProductImageFiles(h.db, []db.ProductImage{image})
+
+	h.respondProductImages(c, http.StatusOK, constants.MsgSuccessProductImageDeleted, image.ProductID)
+}
+
+// ReorderProductImages menyusun ulang urutan gambar produk sesuai image_ids
+func (h *ShopHandler) ReorderProductImages(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgBadRequest)
+		return
+	}
+
+	var req dto.RequestReorderProductImages
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var product db.Product
+	if err := h.db.First(&product, "id = ? AND user_id = ?", productID, userID).Error; err != nil {
+		util.RespondJSON(c, http.StatusNotFound, constants.ErrMsgProductNotFound)
+		return
+	}
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		return service.ReorderProductImages(tx, product.ID, req.ImageIDs)
+	})
+	if err != nil {
+		if err.Error() == constants.ErrMsgProductImageOrderInvalid {
+			util.RespondJSON(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	h.respondProductImages(c, http.StatusOK, constants.MsgSuccessProductImagesReordered, product.ID)
+}
+
+func (h *ShopHandler) findOwnedProductImage(c *gin.Context, userID uint) (db.ProductImage, bool) {
+	var image db.ProductImage
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgBadRequest)
+		return image, false
+	}
+	imageID, err := strconv.ParseUint(c.Param("image_id"), 10, 64)
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgBadRequest)
+		return image, false
+	}
+
+	var product db.Product
+	if err := h.db.First(&product, "id = ? AND user_id = ?", productID, userID).Error; err != nil {
+		util.RespondJSON(c, http.StatusNotFound, constants.ErrMsgProductNotFound)
+		return image, false
+	}
+	if err := h.db.First(&image, "id = ? AND product_id = ?", imageID, product.ID).Error; err != nil {
+		util.RespondJSON(c, http.StatusNotFound, constants.ErrMsgProductImageNotFound)
+		return image, false
+	}
+	return image, true
+}
+
+func (h *ShopHandler) respondProductImages(c *gin.Context, status int, message string, productID uint) {
+	var images []db.ProductImage
+	if err := service.OrderedImages(h.db).Where("product_id = ?", productID).Find(&images).Error; err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	responseImages := make([]dto.ProductImageResponse, len(images))
+	for i, img := range images {
+		responseImages[i] = buildProductImageResponse(img)
+	}
+
+	util.RespondJSON(c, status, gin.H{
+		"message": message,
+		"images":  responseImages,
+	})
+}
+
+func removeProductImageFiles(dbConn *gorm.DB, images []db.ProductImage) {
+	for _, image := range images {
+		if err := service.RemoveProductImageFiles(dbConn, image); err != nil {
+			log.Printf("❌ Gagal menghapus file gambar produk %s: %v", image.ImageURL, err)
+		}
+	}
+}
+
+func buildProductImageResponse(img db.ProductImage) dto.ProductImageResponse {
+	response := dto.ProductImageResponse{
+		ID:           img.ID,
+		ProductID:    img.ProductID,
+		ImageURL:     img.ImageURL,
+		ThumbnailURL: img.ThumbnailURL,
+		MediumURL:    img.MediumURL,
+		Position:     img.Position,
+		IsPrimary:    img.IsPrimary,
+		AltText:      img.AltText,
+		Status:       img.Status,
+		SourceURL:    img.SourceURL,
+		FetchError:   img.FetchError,
+	}
+	// Gambar lama belum memiliki varian ukuran, gunakan gambar asli.
+	if response.ThumbnailURL == "" {
+		response.ThumbnailURL = img.ImageURL
+	}
+	if response.MediumURL == "" {
+		response.MediumURL = img.ImageURL
+	}
+	return response
+}
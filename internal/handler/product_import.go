@@ -0,0 +1,537 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/i18n"
+	"portolio-backend/internal/jobs"
+	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/model/dto"
+	"portolio-backend/internal/service"
+	"portolio-backend/internal/util"
+)
+
+var productImportColumns = []string{"title", "price", "stock", "visibility", "categories", "images_links", "low_stock_threshold"}
+
+type productImportRow struct {
+	RowNumber uint
+	Request   dto.RequestPostProduct
+	Errors    []util.FieldErrorResponse
+}
+
+// ImportProducts menerima file CSV/JSON Lines berisi produk lalu memprosesnya di latar belakang
+func (h *ShopHandler) ImportProducts(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgImportFileMissing)
+		return
+	}
+
+	if file.Size > int64(util.GetMaxImportSizeMB())*1024*1024 {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgFileTooLarge)
+		return
+	}
+
+	var format constants.ImportFormat
+	switch strings.ToLower(filepath.Ext(file.Filename)) {
+	case ".csv":
+		format = constants.ImportFormatCSV
+	case ".jsonl", ".ndjson":
+		format = constants.ImportFormatJSONLines
+	default:
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgImportFormatInvalid)
+		return
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+	defer src.Close()
+
+	var rows []productImportRow
+	if format == constants.ImportFormatCSV {
+		rows, err = parseProductImportCSV(src)
+	} else {
+		rows, err = parseProductImportJSONLines(src)
+	}
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if len(rows) == 0 {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgImportEmpty)
+		return
+	}
+	if len(rows) > constants.MaxImportRows {
+		util.RespondJSON(c, http.StatusBadRequest, i18n.Message{Key: "error.import_row_limit", Params: i18n.Params{"message": constants.ErrMsgImportTooManyRows, "max": constants.MaxImportRows}})
+		return
+	}
+
+	job := db.ProductImportJob{
+		UserID:    userID,
+		FileName:  file.Filename,
+		Format:    format,
+		Status:    constants.ImportStatusPending,
+		TotalRows: uint(len(rows)),
+	}
+	if err := h.db.Create(&job).Error; err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	go h.runProductImport(job.ID, userID, rows)
+
+	util.RespondJSON(c, http.StatusAccepted, gin.H{
+		"message": constants.MsgSuccessImportQueued,
+		"job":     buildProductImportJobResponse(job),
+	})
+}
+
+// GetProductImportJobs menampilkan daftar pekerjaan impor milik penjual
+func (h *ShopHandler) GetProductImportJobs(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	pageStr := c.DefaultQuery("page", "1")
+	limitStr := c.DefaultQuery("limit", "20")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := h.db.Where("user_id = ?", userID).Order("created_at DESC")
+
+	var total int64
+	query.Model(&db.ProductImportJob{}).Count(&total)
+
+	var jobs []db.ProductImportJob
+	if err := query.Limit(limit).Offset(offset).Find(&jobs).Error; err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	responseJobs := make([]dto.ProductImportJobResponse, len(jobs))
+	for i, job := range jobs {
+		responseJobs[i] = buildProductImportJobResponse(job)
+	}
+
+	util.RespondJSON(c, http.StatusOK, dto.GetProductImportJobsResponse{
+		TotalRecords: total,
+		Page:         page,
+		Limit:        limit,
+		Jobs:         responseJobs,
+	})
+}
+
+// GetProductImportJob menampilkan status pekerjaan impor beserta laporan error per baris
+func (h *ShopHandler) GetProductImportJob(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	jobID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgBadRequest)
+		return
+	}
+
+	var job db.ProductImportJob
+	if err := h.db.Preload("Errors", func(db *gorm.DB) *gorm.DB {
+		return db.Order("row_number ASC, id ASC")
+	}).Where("id = ? AND user_id = ?", jobID, userID).First(&job).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			util.RespondJSON(c, http.StatusNotFound, constants.ErrMsgImportJobNotFound)
+		} else {
+			util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		}
+		return
+	}
+
+	util.RespondJSON(c, http.StatusOK, buildProductImportJobResponse(job))
+}
+
+// ExportProducts mengekspor katalog penjual dalam format yang sama dengan impor
+func (h *ShopHandler) ExportProducts(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	format := constants.ImportFormat(strings.ToLower(c.DefaultQuery("format", string(constants.ImportFormatCSV))))
+	if format != constants.ImportFormatCSV && format != constants.ImportFormatJSONLines {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgImportFormatInvalid)
+		return
+	}
+
+	var products []db.Product
+	if err := h.db.Preload("Images", service.OrderedImages).Where("user_id = ?", userID).Order("id ASC").Find(&products).Error; err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	items := make([]dto.ProductExportItem, len(products))
+	for i, p := range products {
+		links := make([]string, 0, len(p.Images))
+		for _, img := range p.Images {
+			switch {
+			case img.ImageURL != "":
+				links = append(links, productImagePublicURL(c, img.ImageURL))
+			case img.SourceURL != "":
+				// Gambar yang belum selesai diunduh diekspor dengan URL asalnya.
+				links = append(links, img.SourceURL)
+			}
+		}
+		items[i] = dto.ProductExportItem{
+			ID:                p.ID,
+			Title:             p.Title,
+			Price:             p.Price,
+			Stock:             p.Stock,
+			Visibility:        p.Visibility,
+			Categories:        p.Categories,
+			IsActive:          p.IsActive,
+			ImagesLinks:       links,
+			LowStockThreshold: p.LowStockThreshold,
+		}
+	}
+
+	filename := fmt.Sprintf("products-%s.%s", time.Now().Format("20060102150405"), format)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	if format == constants.ImportFormatCSV {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Status(http.StatusOK)
+
+		w := csv.NewWriter(c.Writer)
+		w.Write(append([]string{"id"}, append(productImportColumns, "is_active")...))
+		for _, item := range items {
+			w.Write([]string{
+				strconv.FormatUint(uint64(item.ID), 10),
+				item.Title,
+				strconv.FormatUint(uint64(item.Price), 10),
+				strconv.FormatUint(uint64(item.Stock), 10),
+				string(item.Visibility),
+				item.Categories,
+				strings.Join(item.ImagesLinks, "|"),
+				strconv.FormatUint(uint64(item.LowStockThreshold), 10),
+				strconv.FormatBool(item.IsActive),
+			})
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			log.Printf("Gagal menulis ekspor CSV untuk user %d: %v", userID, err)
+		}
+		return
+	}
+
+	c.Header("Content-Type", "application/x-ndjson; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	for _, item := range items {
+		if err := encoder.Encode(item); err != nil {
+			log.Printf("Gagal menulis ekspor JSON Lines untuk user %d: %v", userID, err)
+			return
+		}
+	}
+}
+
+func (h *ShopHandler) runProductImport(jobID, userID uint, rows []productImportRow) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Impor produk job %d berhenti karena panic: %v", jobID, r)
+			finishedAt := time.Now()
+			h.db.Model(&db.ProductImportJob{}).Where("id = ?", jobID).Updates(map[string]interface{}{
+				"status":      constants.ImportStatusFailed,
+				"finished_at": finishedAt,
+			})
+		}
+	}()
+
+	startedAt := time.Now()
+	if err := h.db.Model(&db.ProductImportJob{}).Where("id = ?", jobID).Updates(map[string]interface{}{
+		"status":     constants.ImportStatusProcessing,
+		"started_at": startedAt,
+	}).Error; err != nil {
+		log.Printf("Gagal memulai impor produk job %d: %v", jobID, err)
+		return
+	}
+
+	var successRows, failedRows uint
+	for _, row := range rows {
+		rowErrors := row.Errors
+		if len(rowErrors) == 0 {
+			rowErrors = h.importProductRow(userID, row.Request)
+		}
+
+		if len(rowErrors) == 0 {
+			successRows++
+			if len(row.Request.ImagesLinks) > 0 {
+				jobs.WakeImageFetcher()
+			}
+			continue
+		}
+
+		failedRows++
+		for _, fe := range rowErrors {
+			if err := h.db.Create(&db.ProductImportError{
+				JobID:     jobID,
+				RowNumber: row.RowNumber,
+				Field:     fe.Field,
+				Reason:    fe.Reason,
+			}).Error; err != nil {
+				log.Printf("Gagal menyimpan error impor baris %d job %d: %v", row.RowNumber, jobID, err)
+			}
+		}
+	}
+
+	status := constants.ImportStatusCompleted
+	if successRows == 0 {
+		status = constants.ImportStatusFailed
+	}
+
+	finishedAt := time.Now()
+	if err := h.db.Model(&db.ProductImportJob{}).Where("id = ?", jobID).Updates(map[string]interface{}{
+		"status":       status,
+		"success_rows": successRows,
+		"failed_rows":  failedRows,
+		"finished_at":  finishedAt,
+	}).Error; err != nil {
+		log.Printf("Gagal menyelesaikan impor produk job %d: %v", jobID, err)
+	}
+}
+
+func (h *ShopHandler) importProductRow(userID uint, req dto.RequestPostProduct) []util.FieldErrorResponse {
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return util.ValidationFieldErrors(err)
+	}
+
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = constants.ProductVisibilityAll
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		product := db.Product{
+			Title:             req.Title,
+			Price:             req.Price,
+			Stock:             req.Stock,
+			Visibility:        visibility,
+			Categories:        req.Categories,
+			UserID:            userID,
+			IsActive:          true,
+			LowStockThreshold: req.LowStockThreshold,
+		}
+		if visibility == constants.ProductVisibilityOwnerAdmin {
+			product.VisibilityReason = constants.VisibilityReasonSeller
+		}
+
+		if err := tx.Create(&product).Error; err != nil {
+			return fmt.Errorf("Gagal membuat produk: %v", err)
+		}
+		if err := service.RecordInitialStock(tx, product, constants.InventoryCauseImport, &userID); err != nil {
+			return fmt.Errorf("Gagal mencatat stok awal produk: %v", err)
+		}
+
+		var productImages []db.ProductImage
+		for _, link := range req.ImagesLinks {
+			image, err := service.PendingProductImage(link)
+			if err != nil {
+				return fmt.Errorf("Gagal menambahkan gambar dari URL %s: %v", link, err)
+			}
+			productImages = append(productImages, image)
+		}
+
+		if err := service.AddProductImages(tx, product.ID, productImages); err != nil {
+			return fmt.Errorf("Gagal menyimpan gambar produk: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return []util.FieldErrorResponse{{Field: "General", Reason: err.Error()}}
+	}
+	return nil
+}
+
+func parseProductImportCSV(r io.Reader) ([]productImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Header CSV tidak valid: %v", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"title", "price", "stock"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("Header CSV wajib memiliki kolom: %s", strings.Join(productImportColumns, ", "))
+		}
+	}
+
+	var rows []productImportRow
+	var rowNumber uint
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		rowNumber++
+		if err != nil {
+			rows = append(rows, productImportRow{
+				RowNumber: rowNumber,
+				Errors:    []util.FieldErrorResponse{{Field: "CSV", Reason: err.Error()}},
+			})
+			continue
+		}
+
+		value := func(column string) string {
+			idx, ok := columns[column]
+			if !ok || idx >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[idx])
+		}
+
+		row := productImportRow{RowNumber: rowNumber}
+		row.Request.Title = value("title")
+		row.Request.Visibility = constants.ProductVisibility(value("visibility"))
+		row.Request.Categories = value("categories")
+
+		if price := value("price"); price != "" {
+			parsed, err := strconv.ParseUint(price, 10, 64)
+			if err != nil {
+				row.Errors = append(row.Errors, util.FieldErrorResponse{Field: "Price", Reason: "Price harus berupa bilangan bulat positif."})
+			}
+			row.Request.Price = uint(parsed)
+		}
+		if stock := value("stock"); stock != "" {
+			parsed, err := strconv.ParseUint(stock, 10, 64)
+			if err != nil {
+				row.Errors = append(row.Errors, util.FieldErrorResponse{Field: "Stock", Reason: "Stock harus berupa bilangan bulat positif."})
+			}
+			row.Request.Stock = uint(parsed)
+		}
+		if threshold := value("low_stock_threshold"); threshold != "" {
+			parsed, err := strconv.ParseUint(threshold, 10, 64)
+			if err != nil {
+				row.Errors = append(row.Errors, util.FieldErrorResponse{Field: "LowStockThreshold", Reason: "LowStockThreshold harus berupa bilangan bulat positif."})
+			}
+			row.Request.LowStockThreshold = uint(parsed)
+		}
+		for _, link := range strings.Split(value("images_links"), "|") {
+			if link = strings.TrimSpace(link); link != "" {
+				row.Request.ImagesLinks = append(row.Request.ImagesLinks, link)
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func parseProductImportJSONLines(r io.Reader) ([]productImportRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var rows []productImportRow
+	var rowNumber uint
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		rowNumber++
+
+		row := productImportRow{RowNumber: rowNumber}
+		if err := json.Unmarshal([]byte(line), &row.Request); err != nil {
+			row.Errors = []util.FieldErrorResponse{{Field: "JSON", Reason: err.Error()}}
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Gagal membaca file JSON Lines: %v", err)
+	}
+
+	return rows, nil
+}
+
+func buildProductImportJobResponse(job db.ProductImportJob) dto.ProductImportJobResponse {
+	errors := make([]dto.ProductImportErrorResponse, len(job.Errors))
+	for i, e := range job.Errors {
+		errors[i] = dto.ProductImportErrorResponse{
+			RowNumber: e.RowNumber,
+			Field:     e.Field,
+			Reason:    e.Reason,
+		}
+	}
+
+	return dto.ProductImportJobResponse{
+		ID:          job.ID,
+		FileName:    job.FileName,
+		Format:      job.Format,
+		Status:      job.Status,
+		TotalRows:   job.TotalRows,
+		SuccessRows: job.SuccessRows,
+		FailedRows:  job.FailedRows,
+		StartedAt:   job.StartedAt,
+		FinishedAt:  job.FinishedAt,
+		CreatedAt:   job.CreatedAt,
+		Errors:      errors,
+	}
+}
+
+func productImagePublicURL(c *gin.Context, imagePath string) string {
+	scheme := "http"
+	if c.Request.TLS != nil || strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https") {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s/api/%s", scheme, c.Request.Host, strings.TrimPrefix(imagePath, "/"))
+}
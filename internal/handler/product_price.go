@@ -0,0 +1,280 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/model/dto"
+	"portolio-backend/internal/service"
+	"portolio-backend/internal/util"
+)
+
+// CreatePriceSchedule menjadwalkan harga baru untuk produk milik penjual.
+// Tanpa end_at harga berlaku permanen, dengan end_at harga dikembalikan setelah periode berakhir.
+func (h *ShopHandler) CreatePriceSchedule(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgBadRequest)
+		return
+	}
+
+	var req dto.RequestCreatePriceSchedule
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if !req.StartAt.After(time.Now()) || (req.EndAt != nil && !req.EndAt.After(req.StartAt)) {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgPriceScheduleInvalidTime)
+		return
+	}
+
+	var schedule db.ProductPriceSchedule
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		var product db.Product
+		if err := tx.First(&product, "id = ? AND user_id = ?", productID, userID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf(constants.ErrMsgProductNotFound)
+			}
+			return fmt.Errorf(constants.ErrMsgInternalServerError)
+		}
+
+		overlap := tx.Model(&db.ProductPriceSchedule{}).
+			Where("product_id = ? AND status IN ?", product.ID, []constants.PriceScheduleStatus{constants.PriceScheduleScheduled, constants.PriceScheduleActive}).
+			Where("end_at IS NULL OR end_at > ?", req.StartAt)
+		if req.EndAt != nil {
+			overlap = overlap.Where("start_at < ?", *req.EndAt)
+		}
+		var count int64
+		if err := overlap.Count(&count).Error; err != nil {
+			return fmt.Errorf(constants.ErrMsgInternalServerError)
+		}
+		if count > 0 {
+			return fmt.Errorf(constants.ErrMsgPriceScheduleOverlap)
+		}
+
+		schedule = db.ProductPriceSchedule{
+			ProductID: product.ID,
+			UserID:    userID,
+			Price:     req.Price,
+			StartAt:   req.StartAt,
+			EndAt:     req.EndAt,
+			Status:    constants.PriceScheduleScheduled,
+		}
+		if err := tx.Create(&schedule).Error; err != nil {
+			return fmt.Errorf("Gagal menyimpan jadwal harga: %v", err)
+		}
+		return nil
+	})
+
+	if err != nil {
+		switch err.Error() {
+		case constants.ErrMsgProductNotFound:
+			util.RespondJSON(c, http.StatusNotFound, err.Error())
+		case constants.ErrMsgPriceScheduleOverlap:
+			util.RespondJSON(c, http.StatusConflict, err.Error())
+		default:
+			util.RespondJSON(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	util.RespondJSON(c, http.StatusCreated, gin.H{
+		"message":  constants.MsgSuccessPriceScheduled,
+		"schedule": buildPriceScheduleResponse(schedule),
+	})
+}
+
+// GetPriceSchedules menampilkan seluruh jadwal harga sebuah produk milik penjual
+func (h *ShopHandler) GetPriceSchedules(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgBadRequest)
+		return
+	}
+
+	var product db.Product
+	if err := h.db.First(&product, "id = ? AND user_id = ?", productID, userID).Error; err != nil {
+		util.RespondJSON(c, http.StatusNotFound, constants.ErrMsgProductNotFound)
+		return
+	}
+
+	query := h.db.Where("product_id = ?", product.ID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var schedules []db.ProductPriceSchedule
+	if err := query.Order("start_at DESC").Find(&schedules).Error; err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	responseSchedules := make([]dto.PriceScheduleResponse, len(schedules))
+	for i, s := range schedules {
+		responseSchedules[i] = buildPriceScheduleResponse(s)
+	}
+
+	util.RespondJSON(c, http.StatusOK, responseSchedules)
+}
+
+// CancelPriceSchedule membatalkan jadwal harga. Jadwal yang sedang aktif
+// langsung mengembalikan harga produk ke harga semula.
+func (h *ShopHandler) CancelPriceSchedule(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgBadRequest)
+		return
+	}
+	scheduleID, err := strconv.ParseUint(c.Param("schedule_id"), 10, 64)
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgBadRequest)
+		return
+	}
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		var schedule db.ProductPriceSchedule
+		if err := tx.Joins("JOIN product ON product.id = product_price_schedule.product_id").
+			Where("product_price_schedule.id = ? AND product_price_schedule.product_id = ? AND product.user_id = ?", scheduleID, productID, userID).
+			First(&schedule).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf(constants.ErrMsgPriceScheduleNotFound)
+			}
+			return fmt.Errorf(constants.ErrMsgInternalServerError)
+		}
+
+		switch schedule.Status {
+		case constants.PriceScheduleScheduled:
+			return tx.Model(&schedule).Update("status", constants.PriceScheduleCanceled).Error
+		case constants.PriceScheduleActive:
+			return service.EndPriceSchedule(tx, &schedule, constants.PriceScheduleCanceled, constants.PriceChangeScheduleCancel, &userID)
+		default:
+			return fmt.Errorf(constants.ErrMsgPriceScheduleNotCancellable)
+		}
+	})
+
+	if err != nil {
+		switch err.Error() {
+		case constants.ErrMsgPriceScheduleNotFound:
+			util.RespondJSON(c, http.StatusNotFound, err.Error())
+		case constants.ErrMsgPriceScheduleNotCancellable:
+			util.RespondJSON(c, http.StatusBadRequest, err.Error())
+		default:
+			util.RespondJSON(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	util.RespondJSON(c, http.StatusOK, constants.MsgSuccessPriceScheduleCanceled)
+}
+
+// GetPriceHistory menampilkan riwayat perubahan harga produk.
+// Hanya pemilik produk dan admin yang dapat melihat riwayat ini.
+func (h *ShopHandler) GetPriceHistory(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	roleVal, _ := c.Get("ROLE")
+	role, _ := roleVal.(constants.UserRole)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgBadRequest)
+		return
+	}
+
+	var product db.Product
+	if err := h.db.Unscoped().First(&product, productID).Error; err != nil || (role != constants.RoleAdmin && product.UserID != userID) {
+		util.RespondJSON(c, http.StatusNotFound, constants.ErrMsgProductNotFound)
+		return
+	}
+
+	pageStr := c.DefaultQuery("page", "1")
+	limitStr := c.DefaultQuery("limit", "20")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := h.db.Where("product_id = ?", product.ID).Order("created_at DESC")
+
+	var total int64
+	query.Model(&db.ProductPriceHistory{}).Count(&total)
+
+	var history []db.ProductPriceHistory
+	if err := query.Limit(limit).Offset(offset).Find(&history).Error; err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	responseHistory := make([]dto.PriceHistoryResponse, len(history))
+	for i, entry := range history {
+		responseHistory[i] = dto.PriceHistoryResponse{
+			ID:         entry.ID,
+			OldPrice:   entry.OldPrice,
+			NewPrice:   entry.NewPrice,
+			Reason:     entry.Reason,
+			ChangedBy:  entry.ChangedBy,
+			ScheduleID: entry.ScheduleID,
+			CreatedAt:  entry.CreatedAt,
+		}
+	}
+
+	util.RespondJSON(c, http.StatusOK, dto.GetPriceHistoryResponse{
+		TotalRecords: total,
+		Page:         page,
+		Limit:        limit,
+		History:      responseHistory,
+	})
+}
+
+func buildPriceScheduleResponse(s db.ProductPriceSchedule) dto.PriceScheduleResponse {
+	return dto.PriceScheduleResponse{
+		ID:            s.ID,
+		ProductID:     s.ProductID,
+		Price:         s.Price,
+		OriginalPrice: s.OriginalPrice,
+		StartAt:       s.StartAt,
+		EndAt:         s.EndAt,
+		Status:        s.Status,
+		CreatedAt:     s.CreatedAt,
+	}
+}
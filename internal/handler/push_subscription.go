@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/model/dto"
+	"portolio-backend/internal/util"
+	"portolio-backend/internal/webpush"
+)
+
+// GetVAPIDPublicKey mengembalikan kunci publik VAPID untuk applicationServerKey saat
+// browser berlangganan
+func (h *NotificationHandler) GetVAPIDPublicKey(c *gin.Context) {
+	if webpush.DefaultSender == nil {
+		util.RespondJSON(c, http.StatusServiceUnavailable, constants.ErrMsgWebPushNotConfigured)
+		return
+	}
+
+	util.RespondJSON(c, http.StatusOK, gin.H{"public_key": webpush.DefaultSender.PublicKey()})
+}
+
+// GetPushSubscriptions menampilkan perangkat yang terdaftar untuk web push
+func (h *NotificationHandler) GetPushSubscriptions(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	var subscriptions []db.PushSubscription
+	if err := h.db.Where("user_id = ?", userID).Order("id ASC").Find(&subscriptions).Error; err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	responses := make([]dto.PushSubscriptionResponse, len(subscriptions))
+	for i, subscription := range subscriptions {
+		responses[i] = buildPushSubscriptionResponse(subscription)
+	}
+	util.RespondJSON(c, http.StatusOK, responses)
+}
+
+// CreatePushSubscription mendaftarkan PushSubscription dari browser. Endpoint yang sudah
+// terdaftar, termasuk milik akun lain di browser yang sama, diperbarui dan dipindahkan ke
+// pengguna yang login.
+func (h *NotificationHandler) CreatePushSubscription(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	if webpush.DefaultSender == nil {
+		util.RespondJSON(c, http.StatusServiceUnavailable, constants.ErrMsgWebPushNotConfigured)
+		return
+	}
+
+	var req dto.RequestCreatePushSubscription
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := webpush.DefaultSender.Validate(webpush.Subscription{
+		Endpoint: req.Endpoint,
+		P256dh:   req.Keys.P256dh,
+		Auth:     req.Keys.Auth,
+	}); err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidPushSubscription)
+		return
+	}
+
+	var expiresAt *time.Time
+	if req.ExpirationTime != nil {
+		expiry := time.UnixMilli(*req.ExpirationTime)
+		if !expiry.After(time.Now()) {
+			util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidPushSubscription)
+			return
+		}
+		expiresAt = &expiry
+	}
+
+	var count int64
+	h.db.Model(&db.PushSubscription{}).Where("user_id = ? AND endpoint <> ?", userID, req.Endpoint).Count(&count)
+	if count >= constants.MaxPushSubscriptionsPerUser {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgPushSubscriptionLimit)
+		return
+	}
+
+	userAgent := c.Request.UserAgent()
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+	subscription := db.PushSubscription{
+		UserID:    userID,
+		Endpoint:  req.Endpoint,
+		P256dh:    req.Keys.P256dh,
+		Auth:      req.Keys.Auth,
+		UserAgent: userAgent,
+		ExpiresAt: expiresAt,
+	}
+	if err := h.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "endpoint"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"user_id":       userID,
+			"p256dh":        req.Keys.P256dh,
+			"auth":          req.Keys.Auth,
+			"user_agent":    userAgent,
+			"expires_at":    expiresAt,
+			"failure_count": 0,
+			"updated_at":    time.Now(),
+			"deleted_at":    nil,
+		}),
+	}).Create(&subscription).Error; err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	h.db.Where("endpoint = ?", req.Endpoint).First(&subscription)
+	util.RespondJSON(c, http.StatusCreated, gin.H{
+		"message":      constants.MsgSuccessPushSubscribed,
+		"subscription": buildPushSubscriptionResponse(subscription),
+	})
+}
+
+// DeletePushSubscription menghapus perangkat dari web push, misalnya saat logout
+func (h *NotificationHandler) DeletePushSubscription(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	subscriptionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgBadRequest)
+		return
+	}
+
+	result := h.db.Unscoped().Where("id = ? AND user_id = ?", subscriptionID, userID).Delete(&db.PushSubscription{})
+	if result.Error != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+	if result.RowsAffected == 0 {
+		util.RespondJSON(c, http.StatusNotFound, constants.ErrMsgPushSubscriptionNotFound)
+		return
+	}
+
+	util.RespondJSON(c, http.StatusOK, constants.MsgSuccessPushUnsubscribed)
+}
+
+func buildPushSubscriptionResponse(subscription db.PushSubscription) dto.PushSubscriptionResponse {
+	return dto.PushSubscriptionResponse{
+		ID:         subscription.ID,
+		Endpoint:   subscription.Endpoint,
+		UserAgent:  subscription.UserAgent,
+		ExpiresAt:  subscription.ExpiresAt,
+		LastUsedAt: subscription.LastUsedAt,
+		CreatedAt:  subscription.CreatedAt,
+	}
+}
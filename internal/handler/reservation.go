@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"portolio-backend/configs"
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/model/dto"
+	"portolio-backend/internal/service"
+	"portolio-backend/internal/util"
+)
+
+// CreateReservations menahan stok untuk item yang akan dibayar saat checkout dimulai.
+// Semua item direservasi dalam satu transaksi, gagal satu maka gagal semua.
+func (h *ShopHandler) CreateReservations(c *gin.Context) {
+	var req []dto.RequestReserveItem
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if len(req) == 0 {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgReservationItemsEmpty)
+		return
+	}
+
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	ttl := time.Duration(configs.GetEnvInt("RESERVATION_TTL_MINUTES", constants.DefaultReservationTTLMinutes)) * time.Minute
+
+	var reservations []db.StockReservation
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		for _, item := range req {
+			reservation, err := service.ReserveStock(tx, userID, item.ProductID, item.Quantity, ttl)
+			if err != nil {
+				return err
+			}
+			reservations = append(reservations, *reservation)
+		}
+		return nil
+	})
+
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	responseReservations := make([]dto.StockReservationResponse, len(reservations))
+	for i, r := range reservations {
+		responseReservations[i] = buildReservationResponse(r)
+	}
+
+	util.RespondJSON(c, http.StatusCreated, gin.H{
+		"message":      constants.MsgSuccessStockReserved,
+		"reservations": responseReservations,
+	})
+}
+
+// GetReservations menampilkan reservasi milik pengguna, default hanya yang masih aktif
+func (h *ShopHandler) GetReservations(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	status := c.DefaultQuery("status", string(constants.ReservationActive))
+
+	var reservations []db.StockReservation
+	if err := h.db.Preload("Product").
+		Where("user_id = ? AND status = ?", userID, status).
+		Order("created_at DESC").Limit(100).
+		Find(&reservations).Error; err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	responseReservations := make([]dto.StockReservationResponse, len(reservations))
+	for i, r := range reservations {
+		responseReservations[i] = buildReservationResponse(r)
+	}
+
+	util.RespondJSON(c, http.StatusOK, responseReservations)
+}
+
+func (h *ShopHandler) CancelReservation(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	reservationID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgBadRequest)
+		return
+	}
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		reservation, err := service.LockReservation(tx, uint(reservationID), userID, false)
+		if err != nil {
+			return err
+		}
+		if reservation == nil {
+			return fmt.Errorf(constants.ErrMsgReservationNotFound)
+		}
+		return service.ReleaseReservation(tx, reservation, constants.ReservationReleased)
+	})
+
+	if err != nil {
+		if err.Error() == constants.ErrMsgReservationNotFound {
+			util.RespondJSON(c, http.StatusNotFound, err.Error())
+			return
+		}
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	util.RespondJSON(c, http.StatusOK, constants.MsgSuccessReservationReleased)
+}
+
+func buildReservationResponse(r db.StockReservation) dto.StockReservationResponse {
+	return dto.StockReservationResponse{
+		ID:            r.ID,
+		ProductID:     r.ProductID,
+		ProductTitle:  r.Product.Title,
+		Quantity:      r.Quantity,
+		Status:        r.Status,
+		ExpiresAt:     r.ExpiresAt,
+		TransactionID: r.TransactionID,
+		CreatedAt:     r.CreatedAt,
+	}
+}
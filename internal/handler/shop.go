@@ -3,18 +3,23 @@ package handler
 import (
 	"fmt"
 	"net/http"
-	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"portolio-backend/configs"
 	"portolio-backend/configs/constants"
+	"portolio-backend/internal/events"
+	"portolio-backend/internal/jobs"
 	"portolio-backend/internal/model/db"
 	"portolio-backend/internal/model/dto"
+	"portolio-backend/internal/notify"
+	"portolio-backend/internal/service"
 	"portolio-backend/internal/util"
 )
 
@@ -52,7 +57,7 @@ func (h *ShopHandler) GetProductsRequest(c *gin.Context) {
 	}
 
 	var products []db.Product
-	query := h.db.Preload("Images").Preload("Reviews", func(db *gorm.DB) *gorm.DB {
+	query := h.db.Preload("Images", service.OrderedImages).Preload("Reviews", func(db *gorm.DB) *gorm.DB {
 		return db.Order("created_at desc").Limit(3).Preload("User")
 	})
 
@@ -87,11 +92,7 @@ func (h *ShopHandler) GetProductsRequest(c *gin.Context) {
 
 		images := make([]dto.ProductImageResponse, len(p.Images))
 		for j, img := range p.Images {
-			images[j] = dto.ProductImageResponse{
-				ID:        img.ID,
-				ProductID: img.ProductID,
-				ImageURL:  img.ImageURL,
-			}
+			images[j] = buildProductImageResponse(img)
 		}
 
 		avgRating := calculateAverageRating(p.Reviews)
@@ -101,6 +102,8 @@ func (h *ShopHandler) GetProductsRequest(c *gin.Context) {
 			Id:         p.ID,
 			Title:      p.Title,
 			Price:      p.Price,
+			PreviousPrice: p.PreviousPrice,
+			AvailableStock: service.AvailableStock(p),
 			Stock:      p.Stock,
 			Categories: categories,
 			Images:     images,
@@ -132,10 +135,12 @@ func (h *ShopHandler) PostProductsRequest(c *gin.Context) {
 		visibility = constants.ProductVisibilityAll
 	}
 	if visibility != constants.ProductVisibilityAll && visibility != constants.ProductVisibilityOwnerAdmin {
-		util.RespondJSON(c, http.StatusBadRequest, "Visibilitas tidak valid. Harus 'all' atau 'owner_admin'.")
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidVisibility)
 		return
 	}
 
+	// File gambar disimpan ke storage di dalam transaksi dan dihapus lagi bila transaksi gagal
+	var productImages []db.ProductImage
 	err := h.db.Transaction(func(tx *gorm.DB) error {
 		product := db.Product{
 			Title:      req.Title,
@@ -145,47 +150,45 @@ func (h *ShopHandler) PostProductsRequest(c *gin.Context) {
 			Categories: req.Categories,
 			UserID:     userID,
 			IsActive:   true,
+			LowStockThreshold: req.LowStockThreshold,
+		}
+		if visibility == constants.ProductVisibilityOwnerAdmin {
+			product.VisibilityReason = constants.VisibilityReasonSeller
 		}
 
 		if err := tx.Create(&product).Error; err != nil {
 			return fmt.Errorf("Gagal membuat produk: %v", err)
 		}
-
-		var productImages []db.ProductImage
+		if err := service.RecordInitialStock(tx, product, constants.InventoryCauseInitial, &userID); err != nil {
+			return fmt.Errorf("Gagal mencatat stok awal produk: %v", err)
+		}
 
 		form, err := c.MultipartForm()
 		if err == nil && form != nil {
 			files := form.File["images"]
 			for _, file := range files {
-				filePath, err := util.SaveUploadedFile(file, "media/products", constants.MaxImageSizeMB*1024*1024, util.AllowedImageExtensions)
+				image, err := service.SaveProductImageUpload(file)
 				if err != nil {
 					return fmt.Errorf("Gagal mengunggah gambar: %v", err)
 				}
-				productImages = append(productImages, db.ProductImage{
-					ProductID: product.ID,
-					ImageURL:  filepath.ToSlash(filePath),
-				})
+				productImages = append(productImages, image)
 			}
 		}
 
 		for _, link := range req.ImagesLinks {
-			filePath, err := util.DownloadImage(link, "media/products", constants.MaxImageSizeMB*1024*1024)
+			// Gambar dari URL diunduh di latar belakang oleh jobs.RunImageFetcher.
+			image, err := service.PendingProductImage(link)
 			if err != nil {
-				return fmt.Errorf("Gagal mengunduh gambar dari URL: %v", err)
+				return fmt.Errorf("Gagal menambahkan gambar dari URL: %v", err)
 			}
-			productImages = append(productImages, db.ProductImage{
-				ProductID: product.ID,
-				ImageURL:  filepath.ToSlash(filePath),
-			})
+			productImages = append(productImages, image)
 		}
 
-		if len(productImages) > 0 {
-			if err := tx.Create(&productImages).Error; err != nil {
-				return fmt.Errorf("Gagal menyimpan gambar produk: %v", err)
-			}
+		if err := service.AddProductImages(tx, product.ID, productImages); err != nil {
+			return fmt.Errorf("Gagal menyimpan gambar produk: %v", err)
 		}
 
-		if err := tx.Preload("Images").First(&productWithImages, product.ID).Error; err != nil {
+		if err := tx.Preload("Images", service.OrderedImages).First(&productWithImages, product.ID).Error; err != nil {
 			return fmt.Errorf("Gagal mengambil produk setelah dibuat: %v", err)
 		}
 
@@ -193,23 +196,28 @@ func (h *ShopHandler) PostProductsRequest(c *gin.Context) {
 	})
 
 	if err != nil {
+		removeProductImageFiles(h.db, productImages)
 		util.RespondJSON(c, http.StatusInternalServerError, err.Error())
 		return
 	}
+	if len(req.ImagesLinks) > 0 {
+		jobs.WakeImageFetcher()
+	}
 
 	responseImages := make([]dto.ProductImageResponse, len(productWithImages.Images))
 	for i, img := range productWithImages.Images {
-		responseImages[i] = dto.ProductImageResponse{
-			ID:        img.ID,
-			ProductID: img.ProductID,
-			ImageURL:  img.ImageURL,
-		}
+		responseImages[i] = buildProductImageResponse(img)
 	}
 	responseProduct := dto.ProductDetailResponse{
 		ID:         productWithImages.ID,
 		UserID:     productWithImages.UserID,
 		Title:      productWithImages.Title,
 		Price:      productWithImages.Price,
+		PreviousPrice: productWithImages.PreviousPrice,
+		ReservedStock:  productWithImages.ReservedStock,
+		AvailableStock: service.AvailableStock(productWithImages),
+		LowStockThreshold: productWithImages.LowStockThreshold,
+		VisibilityReason: productWithImages.VisibilityReason,
 		Stock:      productWithImages.Stock,
 		Visibility: productWithImages.Visibility,
 		Categories: filterCategories(productWithImages.Categories),
@@ -231,7 +239,7 @@ func (h *ShopHandler) PutProductsRequest(c *gin.Context) {
 
 	productIDStr := c.Param("id")
 	if productIDStr == "" {
-		util.RespondJSON(c, http.StatusBadRequest, "ID produk diperlukan.")
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgProductIDRequired)
 		return
 	}
 
@@ -247,7 +255,9 @@ func (h *ShopHandler) PutProductsRequest(c *gin.Context) {
 		return
 	}
 
-	err := h.db.Transaction(func(tx *gorm.DB) error {
+	// File gambar disimpan ke storage di dalam transaksi dan dihapus lagi bila transaksi gagal
+	var productImages []db.ProductImage
+	err := notify.Transaction(h.db, func(tx *gorm.DB) error {
 		var product db.Product
 		if err := tx.First(&product, "id = ? AND user_id = ?", productIDStr, userID).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
@@ -260,25 +270,18 @@ func (h *ShopHandler) PutProductsRequest(c *gin.Context) {
 		if req.Title != "" {
 			updates["title"] = req.Title
 		}
-		if req.Price != 0 {
-			updates["price"] = req.Price
-		}
+		priceChanged := req.Price != 0 && (req.Price != product.Price || product.PreviousPrice != nil)
+		newStock := product.Stock
 		if req.Stock != 0 {
-			updates["stock"] = req.Stock
-			if req.Stock == 0 {
-				updates["visibility"] = constants.ProductVisibilityOwnerAdmin
-			} else if product.Stock == 0 && req.Stock > 0 && product.Visibility == constants.ProductVisibilityOwnerAdmin {
-				updates["visibility"] = constants.ProductVisibilityAll
-			}
+			newStock = req.Stock
 		}
 		if req.Visibility != "" {
 			if req.Visibility != constants.ProductVisibilityAll && req.Visibility != constants.ProductVisibilityOwnerAdmin {
-				return fmt.Errorf("Visibilitas tidak valid. Harus 'all' atau 'owner_admin'.")
+				return fmt.Errorf(constants.ErrMsgInvalidVisibility)
 			}
-			if req.Visibility == constants.ProductVisibilityAll && product.Stock == 0 {
-				return fmt.Errorf("Produk tidak dapat ditampilkan ke publik karena stok 0. Mohon perbarui stok terlebih dahulu.")
+			if req.Visibility == constants.ProductVisibilityAll && newStock == 0 {
+				return fmt.Errorf(constants.ErrMsgProductOutOfStockVisibility)
 			}
-			updates["visibility"] = req.Visibility
 		}
 		if req.Categories != "" {
 			updates["categories"] = req.Categories
@@ -286,67 +289,92 @@ func (h *ShopHandler) PutProductsRequest(c *gin.Context) {
 		if req.IsActive != nil {
 			updates["is_active"] = *req.IsActive
 		}
+		if req.LowStockThreshold != nil {
+			updates["low_stock_threshold"] = *req.LowStockThreshold
+		}
 
-		if len(updates) == 0 {
+		if len(updates) == 0 && !priceChanged && req.Stock == 0 && req.Visibility == "" {
 			return fmt.Errorf(constants.ErrMsgNoFieldsToUpdate)
 		}
 
-		if err := tx.Model(&product).Updates(updates).Error; err != nil {
-			return fmt.Errorf("Gagal memperbarui produk: %v", err)
+		if err := service.AdjustStock(tx, &product, service.StockChange{
+			Delta:   int(newStock) - int(product.Stock),
+			Cause:   constants.InventoryCauseManualEdit,
+			ActorID: &userID,
+		}); err != nil {
+			return fmt.Errorf("Gagal memperbarui stok produk: %v", err)
+		}
+
+		if len(updates) > 0 {
+			if err := tx.Model(&product).Updates(updates).Error; err != nil {
+				return fmt.Errorf("Gagal memperbarui produk: %v", err)
+			}
+		}
+
+		if req.Visibility != "" {
+			if err := service.SetProductVisibility(tx, &product, req.Visibility, constants.VisibilityReasonSeller, &userID); err != nil {
+				return fmt.Errorf("Gagal memperbarui visibilitas produk: %v", err)
+			}
 		}
 
-		var productImages []db.ProductImage
+		if priceChanged {
+			// Harga manual menggantikan jadwal harga yang sedang berjalan.
+			if err := service.CancelActivePriceSchedules(tx, product.ID); err != nil {
+				return fmt.Errorf("Gagal membatalkan jadwal harga aktif: %v", err)
+			}
+			if err := service.ChangeProductPrice(tx, &product, service.PriceChange{
+				NewPrice:  req.Price,
+				Reason:    constants.PriceChangeManual,
+				ChangedBy: &userID,
+			}); err != nil {
+				return fmt.Errorf("Gagal memperbarui harga produk: %v", err)
+			}
+		}
 
 		form, err := c.MultipartForm()
 		if err == nil && form != nil {
 			files := form.File["images"]
 			for _, file := range files {
-				filePath, err := util.SaveUploadedFile(file, "media/products", constants.MaxImageSizeMB*1024*1024, util.AllowedImageExtensions)
+				image, err := service.SaveProductImageUpload(file)
 				if err != nil {
 					return fmt.Errorf("Gagal mengunggah gambar: %v", err)
 				}
-				productImages = append(productImages, db.ProductImage{
-					ProductID: product.ID,
-					ImageURL:  filepath.ToSlash(filePath),
-				})
+				productImages = append(productImages, image)
 			}
 		}
 
 		for _, link := range req.ImagesLinks {
-			filePath, err := util.DownloadImage(link, "media/products", constants.MaxImageSizeMB*1024*1024)
+			// Gambar dari URL diunduh di latar belakang oleh jobs.RunImageFetcher.
+			image, err := service.PendingProductImage(link)
 			if err != nil {
-				return fmt.Errorf("Gagal mengunduh gambar dari URL: %v", err)
+				return fmt.Errorf("Gagal menambahkan gambar dari URL: %v", err)
 			}
-			productImages = append(productImages, db.ProductImage{
-				ProductID: product.ID,
-				ImageURL:  filepath.ToSlash(filePath),
-			})
+			productImages = append(productImages, image)
 		}
 
-		if len(productImages) > 0 {
-			if err := tx.Create(&productImages).Error; err != nil {
-				return fmt.Errorf("Gagal menyimpan gambar produk baru: %v", err)
-			}
+		if err := service.AddProductImages(tx, product.ID, productImages); err != nil {
+			return fmt.Errorf("Gagal menyimpan gambar produk baru: %v", err)
 		}
 
 		var productWithImages db.Product
-		if err := tx.Preload("Images").First(&productWithImages, product.ID).Error; err != nil {
+		if err := tx.Preload("Images", service.OrderedImages).First(&productWithImages, product.ID).Error; err != nil {
 			return fmt.Errorf("Gagal mengambil produk setelah diperbarui: %v", err)
 		}
 
 		responseImages := make([]dto.ProductImageResponse, len(productWithImages.Images))
 		for i, img := range productWithImages.Images {
-			responseImages[i] = dto.ProductImageResponse{
-				ID:        img.ID,
-				ProductID: img.ProductID,
-				ImageURL:  img.ImageURL,
-			}
+			responseImages[i] = buildProductImageResponse(img)
 		}
 		responseProduct := dto.ProductDetailResponse{
 			ID:         productWithImages.ID,
 			UserID:     productWithImages.UserID,
 			Title:      productWithImages.Title,
 			Price:      productWithImages.Price,
+			PreviousPrice: productWithImages.PreviousPrice,
+			ReservedStock:  productWithImages.ReservedStock,
+			AvailableStock: service.AvailableStock(productWithImages),
+			LowStockThreshold: productWithImages.LowStockThreshold,
+			VisibilityReason: productWithImages.VisibilityReason,
 			Stock:      productWithImages.Stock,
 			Visibility: productWithImages.Visibility,
 			Categories: filterCategories(productWithImages.Categories),
@@ -365,7 +393,12 @@ func (h *ShopHandler) PutProductsRequest(c *gin.Context) {
 	})
 
 	if err != nil {
+		removeProductImageFiles(h.db, productImages)
 		util.RespondJSON(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(req.ImagesLinks) > 0 {
+		jobs.WakeImageFetcher()
 	}
 }
 
@@ -416,22 +449,32 @@ func (h *ShopHandler) DeleteProductsRequest(c *gin.Context) {
 		return
 	}
 
-	err = h.db.Transaction(func(tx *gorm.DB) error {
-		product.Visibility = constants.ProductVisibilityOwnerAdmin
-		product.DeletedAt = gorm.DeletedAt{
-			Time:  time.Now(),
-			Valid: true,
-		}
-		if err := tx.Save(&product).Error; err != nil {
-			return fmt.Errorf("failed to soft delete product: %v", err)
+	err = notify.Transaction(h.db, func(tx *gorm.DB) error {
+		// Transaksi dikunci sebelum produk, sama dengan urutan di CancelTransaction, agar
+		// pembatalan yang berjalan bersamaan tidak me-refund transaksi yang sama dua kali
+		var transactions []db.TransactionHistory
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("product_id = ? AND status IN ?", product.ID, []constants.TransactionStatus{constants.TrxStatusPending, constants.TrxStatusWaitingOwner, constants.TrxStatusWaitingUser}).
+			Order("id").Find(&transactions).Error; err != nil {
+			return fmt.Errorf("failed to find pending transactions: %v", err)
 		}
 
-		var pendingTransactions []db.TransactionHistory
-		if err := tx.Where("product_id = ? AND status IN (?, ?)", product.ID, constants.TrxStatusPending, constants.TrxStatusWaitingOwner).Find(&pendingTransactions).Error; err != nil {
-			return fmt.Errorf("failed to find pending transactions: %v", err)
+		reason := constants.VisibilityReasonSeller
+		if role == constants.RoleAdmin {
+			reason = constants.VisibilityReasonAdmin
+		}
+		if err := service.SetProductVisibility(tx, &product, constants.ProductVisibilityOwnerAdmin, reason, &userID); err != nil {
+			return fmt.Errorf("failed to hide product: %v", err)
+		}
+		if err := tx.Delete(&product).Error; err != nil {
+			return fmt.Errorf("failed to soft delete product: %v", err)
 		}
 
-		for _, trx := range pendingTransactions {
+		for _, trx := range transactions {
+			if trx.Status == constants.TrxStatusWaitingUser {
+				continue
+			}
+			previous := trx.Status
 			var buyer db.User
 			if err := tx.First(&buyer, trx.UserID).Error; err != nil {
 				return fmt.Errorf("failed to find buyer for transaction %d: %v", trx.ID, err)
@@ -441,98 +484,77 @@ func (h *ShopHandler) DeleteProductsRequest(c *gin.Context) {
 			if err := tx.Model(&buyer).Update("balance", newBalance).Error; err != nil {
 				return fmt.Errorf("failed to refund buyer for transaction %d: %v", trx.ID, err)
 			}
-			tx.Create(&db.BalanceHistory{
+			if err := tx.Create(&db.BalanceHistory{
 				UserID:       buyer.ID,
 				Description:  fmt.Sprintf("Refund dari pembatalan produk '%s' (ID: %d) karena produk dihapus", product.Title, product.ID),
 				Amount:       int(refundAmount),
 				LastBalance:  buyer.Balance,
 				FinalBalance: newBalance,
 				Status:       constants.BalanceStatusRefund,
-			})
+			}).Error; err != nil {
+				return fmt.Errorf("failed to record refund for transaction %d: %v", trx.ID, err)
+			}
 
-			tx.Model(&trx).Updates(map[string]interface{}{
-				"status":        constants.TrxStatusCancel,
-				"is_solved":     true,
+			if err := tx.Model(&trx).Updates(map[string]interface{}{
+				"status":         constants.TrxStatusCancel,
+				"is_solved":      true,
 				"receipt_status": constants.ReceiptCanceled,
-			})
-
-			notificationBuyer := db.Notification{
-				UserID:    buyer.ID,
-				Type:      constants.NotifTypePurchase,
-				Message:   fmt.Sprintf("Pembelian produk '%s' Anda (ID: %d) dibatalkan karena produk dihapus. Dana telah dikembalikan.", product.Title, trx.ID),
-				RelatedID: &trx.ID,
-			}
-			util.SendNotificationToUser(buyer.ID, dto.NotificationResponse{
-				ID:        notificationBuyer.ID,
-				Type:      notificationBuyer.Type,
-				Message:   notificationBuyer.Message,
-				RelatedID: notificationBuyer.RelatedID,
-				CreatedAt: notificationBuyer.CreatedAt,
-				IsRead:    notificationBuyer.IsRead,
-			})
-			tx.Create(&notificationBuyer)
-		}
+			}).Error; err != nil {
+				return fmt.Errorf("failed to cancel transaction %d: %v", trx.ID, err)
+			}
+			if err := service.ReleaseCouponRedemption(tx, trx); err != nil {
+				return fmt.Errorf("failed to release coupon for transaction %d: %v", trx.ID, err)
+			}
+			if err := service.AdjustStock(tx, &product, service.StockChange{
+				Delta:         int(trx.Quantity),
+				Cause:         constants.InventoryCauseCancelRestock,
+				Note:          "Pembatalan karena produk dihapus",
+				ActorID:       &userID,
+				TransactionID: &trx.ID,
+			}); err != nil {
+				return fmt.Errorf("failed to restock product for transaction %d: %v", trx.ID, err)
+			}
 
-		var waitingUserTransactions []db.TransactionHistory
-		if err := tx.Where("product_id = ? AND status = ?", product.ID, constants.TrxStatusWaitingUser).Find(&waitingUserTransactions).Error; err != nil {
-			return fmt.Errorf("failed to find waiting_users transactions: %v", err)
+			if err := events.PublishTransaction(tx, constants.EventTransactionCanceled, trx, product.UserID, previous, events.ReasonProductDeleted); err != nil {
+				return err
+			}
 		}
-		for _, trx := range waitingUserTransactions {
+
+		for _, trx := range transactions {
+			if trx.Status != constants.TrxStatusWaitingUser {
+				continue
+			}
 			var owner db.User
 			if err := tx.First(&owner, product.UserID).Error; err != nil {
 				return fmt.Errorf("failed to find owner for transaction %d: %v", trx.ID, err)
 			}
-			sellerReceiveAmount := trx.TotalPrice - trx.EcommerceTax
+			sellerReceiveAmount := trx.SellerPayoutAmount()
 			newOwnerBalance := owner.Balance + sellerReceiveAmount
 			if err := tx.Model(&owner).Update("balance", newOwnerBalance).Error; err != nil {
 				return fmt.Errorf("failed to pay owner for transaction %d: %v", trx.ID, err)
 			}
-			tx.Create(&db.BalanceHistory{
+			if err := tx.Create(&db.BalanceHistory{
 				UserID:       owner.ID,
 				Description:  fmt.Sprintf("Pembayaran penjualan produk '%s' (ID: %d) karena produk dihapus", product.Title, product.ID),
 				Amount:       int(sellerReceiveAmount),
 				LastBalance:  owner.Balance,
 				FinalBalance: newOwnerBalance,
 				Status:       constants.BalanceStatusCredit,
-			})
+			}).Error; err != nil {
+				return fmt.Errorf("failed to record payout for transaction %d: %v", trx.ID, err)
+			}
 
-			tx.Model(&trx).Updates(map[string]interface{}{
-				"status":        constants.TrxStatusSuccess,
-				"is_solved":     true,
+			if err := tx.Model(&trx).Updates(map[string]interface{}{
+				"status":         constants.TrxStatusSuccess,
+				"is_solved":      true,
 				"receipt_status": constants.ReceiptCompleted,
-			})
-
-			notificationBuyer := db.Notification{
-				UserID:    trx.UserID,
-				Type:      constants.NotifTypePurchase,
-				Message:   fmt.Sprintf("Pembelian produk '%s' Anda (ID: %d) telah diselesaikan karena produk dihapus. Penjual telah dibayar.", product.Title, trx.ID),
-				RelatedID: &trx.ID,
-			}
-			util.SendNotificationToUser(trx.UserID, dto.NotificationResponse{
-				ID:        notificationBuyer.ID,
-				Type:      notificationBuyer.Type,
-				Message:   notificationBuyer.Message,
-				RelatedID: notificationBuyer.RelatedID,
-				CreatedAt: notificationBuyer.CreatedAt,
-				IsRead:    notificationBuyer.IsRead,
-			})
-			tx.Create(&notificationBuyer)
-
-			notificationSeller := db.Notification{
-				UserID:    owner.ID,
-				Type:      constants.NotifTypeSale,
-				Message:   fmt.Sprintf("Pembayaran produk '%s' Anda (ID: %d) telah diproses karena produk dihapus.", product.Title, trx.ID),
-				RelatedID: &trx.ID,
-			}
-			util.SendNotificationToUser(owner.ID, dto.NotificationResponse{
-				ID:        notificationSeller.ID,
-				Type:      notificationSeller.Type,
-				Message:   notificationSeller.Message,
-				RelatedID: notificationSeller.RelatedID,
-				CreatedAt: notificationSeller.CreatedAt,
-				IsRead:    notificationSeller.IsRead,
-			})
-			tx.Create(&notificationSeller)
+			}).Error; err != nil {
+				return fmt.Errorf("failed to complete transaction %d: %v", trx.ID, err)
+			}
+
+			if err := events.PublishTransaction(tx, constants.EventTransactionCompleted, trx, owner.ID, constants.TrxStatusWaitingUser, events.ReasonProductDeleted); err != nil {
+				return err
+			}
 		}
 
 		return nil
@@ -591,17 +613,21 @@ func (h *ShopHandler) PatchProductVisibility(c *gin.Context) {
 		message = "Visibilitas produk berhasil diubah menjadi 'owner_admin' (tersembunyi dari publik)."
 	} else if product.Visibility == constants.ProductVisibilityOwnerAdmin {
 		if product.Stock == 0 {
-			util.RespondJSON(c, http.StatusBadRequest, "Produk tidak dapat ditampilkan ke publik karena stok 0. Mohon perbarui stok terlebih dahulu.")
+			util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgProductOutOfStockVisibility)
 			return
 		}
 		newVisibility = constants.ProductVisibilityAll
 		message = "Visibilitas produk berhasil diubah menjadi 'all' (terlihat publik)."
 	} else {
-		util.RespondJSON(c, http.StatusBadRequest, "Visibilitas produk tidak dapat diubah melalui endpoint ini.")
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgVisibilityNotEditable)
 		return
 	}
 
-	if err := h.db.Model(&product).Update("visibility", newVisibility).Error; err != nil {
+	reason := constants.VisibilityReasonSeller
+	if role == constants.RoleAdmin && product.UserID != userID {
+		reason = constants.VisibilityReasonAdmin
+	}
+	if err := service.SetProductVisibility(h.db, &product, newVisibility, reason, &userID); err != nil {
 		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
 		return
 	}
@@ -618,7 +644,7 @@ func (h *ShopHandler) PostPurchaseProduct(c *gin.Context) {
 	}
 
 	if len(req) == 0 {
-		util.RespondJSON(c, http.StatusBadRequest, "Daftar pembelian tidak boleh kosong.")
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgPurchaseItemsEmpty)
 		return
 	}
 
@@ -638,22 +664,82 @@ func (h *ShopHandler) PostPurchaseProduct(c *gin.Context) {
 	govtTaxPercent := configs.GetEnvFloat("GOVT_TAX_PERCENT", constants.DefaultGovtTaxPercent)
 	ecommerceTaxPercent := configs.GetEnvFloat("ECOMMERCE_TAX_PERCENT", constants.DefaultEcommerceTaxPercent)
 
-	err := h.db.Transaction(func(tx *gorm.DB) error {
-		for _, item := range req {
+	err := notify.Transaction(h.db, func(tx *gorm.DB) error {
+		// Seluruh produk dikunci lebih dulu agar kupon divalidasi dan dihitung sekali
+		// untuk seluruh pesanan dari harga yang sama dengan yang dibayar
+		couponLines := map[string][]int{}
+		lines := make([]service.CouponLine, len(req))
+		for i, item := range req {
 			var product db.Product
-			if err := tx.Where("id = ? AND visibility = ? AND deleted_at IS NULL", item.ProductID, constants.ProductVisibilityAll).First(&product).Error; err != nil {
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? AND visibility = ? AND deleted_at IS NULL", item.ProductID, constants.ProductVisibilityAll).First(&product).Error; err != nil {
 				return fmt.Errorf(constants.ErrMsgProductNotFound+" (ID: %d)", item.ProductID)
 			}
-
 			if product.UserID == user.ID {
 				return fmt.Errorf(constants.ErrMsgProductSelfPurchase+": %s", product.Title)
 			}
+			lines[i] = service.CouponLine{Product: product, BasePrice: product.Price * item.Quantity}
+			if item.CouponCode != "" {
+				code := service.NormalizeCouponCode(item.CouponCode)
+				couponLines[code] = append(couponLines[code], i)
+			}
+		}
+
+		// Kupon dikunci dengan urutan kode yang tetap agar dua pesanan tidak saling menunggu
+		codes := make([]string, 0, len(couponLines))
+		for code := range couponLines {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		coupons := make([]*db.Coupon, len(req))
+		discounts := make([]uint, len(req))
+		for _, code := range codes {
+			indexes := couponLines[code]
+			itemLines := make([]service.CouponLine, len(indexes))
+			for j, i := range indexes {
+				itemLines[j] = lines[i]
+			}
+			coupon, lineDiscounts, err := service.ResolveCoupon(tx, code, user.ID, itemLines, time.Now())
+			if err != nil {
+				return err
+			}
+			for j, i := range indexes {
+				coupons[i] = coupon
+				discounts[i] = lineDiscounts[j]
+			}
+		}
+		couponTransactions := map[*db.Coupon][]db.TransactionHistory{}
+
+		for i, item := range req {
+			var product db.Product
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, item.ProductID).Error; err != nil {
+				return fmt.Errorf(constants.ErrMsgProductNotFound+" (ID: %d)", item.ProductID)
+			}
 
-			if product.Stock < item.Quantity {
+			// Pembelian dengan reservasi memakai stok yang sudah ditahan,
+			// tanpa reservasi hanya boleh memakai stok yang belum ditahan.
+			var reservation *db.StockReservation
+			if item.ReservationID != nil {
+				var err error
+				reservation, err = service.LockActiveReservation(tx, *item.ReservationID, user.ID, product.ID, item.Quantity)
+				if err != nil {
+					return err
+				}
+				if product.Stock < item.Quantity {
+					return fmt.Errorf(constants.ErrMsgInsufficientStock+" untuk produk %s", product.Title)
+				}
+			} else if service.AvailableStock(product) < item.Quantity {
 				return fmt.Errorf(constants.ErrMsgInsufficientStock+" untuk produk %s", product.Title)
 			}
 
 			basePrice := product.Price * item.Quantity
+
+			coupon, discountAmount := coupons[i], discounts[i]
+			var platformDiscount uint
+			if coupon != nil && coupon.FundedBy == constants.CouponFundedByPlatform {
+				platformDiscount = discountAmount
+			}
+			basePrice -= discountAmount
+
 			govtTaxAmount := uint(float64(basePrice) * govtTaxPercent)
 			ecommerceTaxAmount := uint(float64(basePrice) * ecommerceTaxPercent)
 
@@ -663,33 +749,54 @@ func (h *ShopHandler) PostPurchaseProduct(c *gin.Context) {
 				return fmt.Errorf(constants.ErrMsgInsufficientBalance+" untuk produk %s", product.Title)
 			}
 
-			if err := tx.Model(&product).Update("stock", product.Stock-item.Quantity).Error; err != nil {
-				return err
-			}
-			if product.Stock-item.Quantity == 0 {
-				tx.Model(&product).Update("visibility", constants.ProductVisibilityOwnerAdmin)
-			}
-
 			user.Balance -= totalPriceForBuyer
 			if err := tx.Model(&user).Update("balance", user.Balance).Error; err != nil {
 				return err
 			}
 
 			trx := db.TransactionHistory{
-				ProductID:    product.ID,
-				UserID:       user.ID,
-				Quantity:     item.Quantity,
-				TotalPrice:   basePrice,
-				GovtTax:      govtTaxAmount,
-				EcommerceTax: ecommerceTaxAmount,
-				Status:       constants.TrxStatusPending,
-				IsSolved:     false,
-				ReceiptStatus: constants.ReceiptPendingProcess,
+				ProductID:        product.ID,
+				UserID:           user.ID,
+				Quantity:         item.Quantity,
+				TotalPrice:       basePrice,
+				GovtTax:          govtTaxAmount,
+				EcommerceTax:     ecommerceTaxAmount,
+				Status:           constants.TrxStatusPending,
+				IsSolved:         false,
+				ReceiptStatus:    constants.ReceiptPendingProcess,
+				DiscountAmount:   discountAmount,
+				PlatformDiscount: platformDiscount,
+			}
+			if coupon != nil {
+				trx.CouponID = &coupon.ID
 			}
 			if err := tx.Create(&trx).Error; err != nil {
 				return err
 			}
 
+			if err := service.AdjustStock(tx, &product, service.StockChange{
+				Delta:         -int(item.Quantity),
+				Cause:         constants.InventoryCauseSale,
+				ActorID:       &user.ID,
+				TransactionID: &trx.ID,
+			}); err != nil {
+				return err
+			}
+			if reservation != nil {
+				if err := tx.Model(&product).Update("reserved_stock", gorm.Expr("reserved_stock - ?", item.Quantity)).Error; err != nil {
+					return err
+				}
+			}
+
+			if coupon != nil {
+				couponTransactions[coupon] = append(couponTransactions[coupon], trx)
+			}
+			if reservation != nil {
+				if err := service.ConvertReservation(tx, reservation, trx.ID); err != nil {
+					return err
+				}
+			}
+
 			history := db.BalanceHistory{
 				UserID:       user.ID,
 				Description:  fmt.Sprintf("Pembelian '%s' x%d (termasuk pajak pemerintah Rp%d)", product.Title, item.Quantity, govtTaxAmount),
@@ -702,41 +809,17 @@ func (h *ShopHandler) PostPurchaseProduct(c *gin.Context) {
 				return err
 			}
 
-			notificationSeller := db.Notification{
-				UserID:    product.UserID,
-				Type:      constants.NotifTypeSale,
-				Message:   fmt.Sprintf("Produk '%s' Anda telah dibeli oleh %s (x%d). Menunggu konfirmasi pengiriman.", product.Title, user.FullName, item.Quantity),
-				RelatedID: &trx.ID,
-			}
-			if err := tx.Create(&notificationSeller).Error; err != nil {
+			if err := events.PublishTransaction(tx, constants.EventPurchaseCreated, trx, product.UserID, "", events.ReasonBuyer); err != nil {
 				return err
 			}
-			util.SendNotificationToUser(product.UserID, dto.NotificationResponse{
-				ID:        notificationSeller.ID,
-				Type:      notificationSeller.Type,
-				Message:   notificationSeller.Message,
-				RelatedID: notificationSeller.RelatedID,
-				CreatedAt: notificationSeller.CreatedAt,
-				IsRead:    notificationSeller.IsRead,
-			})
-
-			notificationBuyer := db.Notification{
-				UserID:    user.ID,
-				Type:      constants.NotifTypePurchase,
-				Message:   fmt.Sprintf("Pembelian '%s' Anda berhasil! Menunggu konfirmasi pengiriman dari penjual.", product.Title),
-				RelatedID: &trx.ID,
-			}
-			if err := tx.Create(&notificationBuyer).Error; err != nil {
+		}
+
+		// Satu kupon dicatat sekali per pesanan walaupun dipakai beberapa item
+		for _, code := range codes {
+			coupon := coupons[couponLines[code][0]]
+			if err := service.RedeemCoupon(tx, coupon, couponTransactions[coupon]); err != nil {
 				return err
 			}
-			util.SendNotificationToUser(user.ID, dto.NotificationResponse{
-				ID:        notificationBuyer.ID,
-				Type:      notificationBuyer.Type,
-				Message:   notificationBuyer.Message,
-				RelatedID: notificationBuyer.RelatedID,
-				CreatedAt: notificationBuyer.CreatedAt,
-				IsRead:    notificationBuyer.IsRead,
-			})
 		}
 		return nil
 	})
@@ -790,37 +873,9 @@ func (h *ShopHandler) ConfirmTransactionByOwner(c *gin.Context) {
 				return fmt.Errorf("Gagal memperbarui transaksi %d: %v", trxID, err)
 			}
 
-			notificationBuyer := db.Notification{
-				UserID:    trx.UserID,
-				Type:      constants.NotifTypePurchase,
-				Message:   fmt.Sprintf("Penjual telah mengkonfirmasi pengiriman produk '%s' (ID: %d). Mohon konfirmasi penerimaan setelah barang sampai.", trx.Product.Title, trx.ID),
-				RelatedID: &trx.ID,
-			}
-			util.SendNotificationToUser(trx.UserID, dto.NotificationResponse{
-				ID:        notificationBuyer.ID,
-				Type:      notificationBuyer.Type,
-				Message:   notificationBuyer.Message,
-				RelatedID: notificationBuyer.RelatedID,
-				CreatedAt: notificationBuyer.CreatedAt,
-				IsRead:    notificationBuyer.IsRead,
-			})
-			tx.Create(&notificationBuyer)
-
-			notificationSeller := db.Notification{
-				UserID:    ownerID,
-				Type:      constants.NotifTypeSale,
-				Message:   fmt.Sprintf("Anda telah mengkonfirmasi pengiriman produk '%s' (ID: %d). Menunggu konfirmasi pembeli.", trx.Product.Title, trx.ID),
-				RelatedID: &trx.ID,
-			}
-			util.SendNotificationToUser(ownerID, dto.NotificationResponse{
-				ID:        notificationSeller.ID,
-				Type:      notificationSeller.Type,
-				Message:   notificationSeller.Message,
-				RelatedID: notificationSeller.RelatedID,
-				CreatedAt: notificationSeller.CreatedAt,
-				IsRead:    notificationSeller.IsRead,
-			})
-			tx.Create(&notificationSeller)
+			if err := events.PublishTransaction(tx, constants.EventTransactionShipped, trx, ownerID, constants.TrxStatusPending, events.ReasonSeller); err != nil {
+				return err
+			}
 		}
 		return nil
 	})
@@ -867,7 +922,7 @@ func (h *ShopHandler) ConfirmTransactionByUser(c *gin.Context) {
 				return fmt.Errorf("Gagal mengambil info pemilik produk untuk transaksi %d: %v", trxID, err)
 			}
 
-			sellerReceiveAmount := trx.TotalPrice - trx.EcommerceTax
+			sellerReceiveAmount := trx.SellerPayoutAmount()
 			newOwnerBalance := owner.Balance + sellerReceiveAmount
 
 			history := db.BalanceHistory{
@@ -905,40 +960,15 @@ func (h *ShopHandler) ConfirmTransactionByUser(c *gin.Context) {
 					if err := tx.Create(&review).Error; err != nil {
 						return fmt.Errorf("Gagal menyimpan ulasan untuk transaksi %d: %v", trxID, err)
 					}
+					if err := events.PublishReview(tx, review, trxID, owner.ID); err != nil {
+						return err
+					}
 				}
 			}
 
-			notificationSeller := db.Notification{
-				UserID:    owner.ID,
-				Type:      constants.NotifTypeSale,
-				Message:   fmt.Sprintf("Pembeli telah mengkonfirmasi penerimaan produk '%s' (ID: %d). Dana telah ditransfer ke saldo Anda.", product.Title, product.ID),
-				RelatedID: &trx.ID,
-			}
-			util.SendNotificationToUser(owner.ID, dto.NotificationResponse{
-				ID:        notificationSeller.ID,
-				Type:      notificationSeller.Type,
-				Message:   notificationSeller.Message,
-				RelatedID: notificationSeller.RelatedID,
-				CreatedAt: notificationSeller.CreatedAt,
-				IsRead:    notificationSeller.IsRead,
-			})
-			tx.Create(&notificationSeller)
-
-			notificationBuyer := db.Notification{
-				UserID:    userID,
-				Type:      constants.NotifTypePurchase,
-				Message:   fmt.Sprintf("Transaksi produk '%s' (ID: %d) telah berhasil diselesaikan.", product.Title, product.ID),
-				RelatedID: &trx.ID,
-			}
-			util.SendNotificationToUser(userID, dto.NotificationResponse{
-				ID:        notificationBuyer.ID,
-				Type:      notificationBuyer.Type,
-				Message:   notificationBuyer.Message,
-				RelatedID: notificationBuyer.RelatedID,
-				CreatedAt: notificationBuyer.CreatedAt,
-				IsRead:    notificationBuyer.IsRead,
-			})
-			tx.Create(&notificationBuyer)
+			if err := events.PublishTransaction(tx, constants.EventTransactionCompleted, trx, owner.ID, constants.TrxStatusWaitingUser, events.ReasonBuyer); err != nil {
+				return err
+			}
 		}
 		return nil
 	})
@@ -969,11 +999,15 @@ func (h *ShopHandler) CancelTransaction(c *gin.Context) {
 		return
 	}
 
-	err := h.db.Transaction(func(tx *gorm.DB) error {
+	err := notify.Transaction(h.db, func(tx *gorm.DB) error {
 		for _, trxID := range req.TransactionIDs {
 
+			// Baris transaksi dikunci agar dua pembatalan bersamaan tidak sama-sama
+			// mengembalikan dana dan stok. Produk yang sudah dihapus tetap dimuat.
 			var trx db.TransactionHistory
-			if err := tx.Preload("Product").First(&trx, trxID).Error; err != nil {
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Preload("Product", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+				First(&trx, trxID).Error; err != nil {
 				return fmt.Errorf(constants.ErrMsgTransactionNotFound+" (ID: %d)", trxID)
 			}
 
@@ -985,6 +1019,8 @@ func (h *ShopHandler) CancelTransaction(c *gin.Context) {
 				return fmt.Errorf(constants.ErrMsgTransactionNotCancellable+" (ID: %d, status: %s)", trxID, trx.Status)
 			}
 
+			previous := trx.Status
+
 			var buyer db.User
 			if err := tx.First(&buyer, trx.UserID).Error; err != nil {
 				return fmt.Errorf("Pembeli ID %d tidak ditemukan", trx.UserID)
@@ -1009,16 +1045,17 @@ func (h *ShopHandler) CancelTransaction(c *gin.Context) {
 			}
 
 			var product db.Product
-			if err := tx.First(&product, trx.ProductID).Error; err != nil {
+			if err := tx.Unscoped().First(&product, trx.ProductID).Error; err != nil {
 				return fmt.Errorf("Produk ID %d tidak ditemukan", trx.ProductID)
 			}
-			product.Stock += trx.Quantity
-			if err := tx.Save(&product).Error; err != nil {
+			if err := service.AdjustStock(tx, &product, service.StockChange{
+				Delta:         int(trx.Quantity),
+				Cause:         constants.InventoryCauseCancelRestock,
+				ActorID:       &userID,
+				TransactionID: &trx.ID,
+			}); err != nil {
 				return fmt.Errorf("Gagal mengembalikan stok produk %d", product.ID)
 			}
-			if product.Stock > 0 && product.Visibility == constants.ProductVisibilityOwnerAdmin {
-				tx.Model(&product).Update("visibility", constants.ProductVisibilityAll)
-			}
 
 			if err := tx.Model(&trx).Updates(map[string]interface{}{
 				"status":        constants.TrxStatusCancel,
@@ -1027,38 +1064,17 @@ func (h *ShopHandler) CancelTransaction(c *gin.Context) {
 			}).Error; err != nil {
 				return fmt.Errorf("Gagal membatalkan transaksi %d", trxID)
 			}
+			if err := service.ReleaseCouponRedemption(tx, trx); err != nil {
+				return fmt.Errorf("Gagal mengembalikan kuota kupon untuk transaksi %d", trxID)
+			}
 
-			notificationBuyer := db.Notification{
-				UserID:    buyer.ID,
-				Type:      constants.NotifTypePurchase,
-				Message:   fmt.Sprintf("Pembelian produk '%s' Anda (ID: %d) telah dibatalkan. Dana telah dikembalikan.", trx.Product.Title, trx.ID),
-				RelatedID: &trx.ID,
-			}
-			util.SendNotificationToUser(buyer.ID, dto.NotificationResponse{
-				ID:        notificationBuyer.ID,
-				Type:      notificationBuyer.Type,
-				Message:   notificationBuyer.Message,
-				RelatedID: notificationBuyer.RelatedID,
-				CreatedAt: notificationBuyer.CreatedAt,
-				IsRead:    notificationBuyer.IsRead,
-			})
-			tx.Create(&notificationBuyer)
-
-			notificationSeller := db.Notification{
-				UserID:    trx.Product.UserID,
-				Type:      constants.NotifTypeSale,
-				Message:   fmt.Sprintf("Transaksi produk '%s' (ID: %d) dibatalkan oleh pembeli. Stok produk telah dikembalikan.", trx.Product.Title, trx.ID),
-				RelatedID: &trx.ID,
-			}
-			util.SendNotificationToUser(trx.Product.UserID, dto.NotificationResponse{
-				ID:        notificationSeller.ID,
-				Type:      notificationSeller.Type,
-				Message:   notificationSeller.Message,
-				RelatedID: notificationSeller.RelatedID,
-				CreatedAt: notificationSeller.CreatedAt,
-				IsRead:    notificationSeller.IsRead,
-			})
-			tx.Create(&notificationSeller)
+			reason := events.ReasonBuyer
+			if userID == trx.Product.UserID {
+				reason = events.ReasonSeller
+			}
+			if err := events.PublishTransaction(tx, constants.EventTransactionCanceled, trx, trx.Product.UserID, previous, reason); err != nil {
+				return err
+			}
 		}
 		return nil
 	})
@@ -1104,10 +1120,7 @@ func (h *ShopHandler) GetCartHandler(c *gin.Context) {
 			continue
 		}
 
-		var imageURL string
-		if len(t.Product.Images) > 0 {
-			imageURL = t.Product.Images[0].ImageURL
-		}
+		imageURL := service.PrimaryImageURL(t.Product.Images)
 
 		result = append(result, dto.CartProduct{
 			ID:            t.ID,
@@ -1117,6 +1130,7 @@ func (h *ShopHandler) GetCartHandler(c *gin.Context) {
 			UserID:        t.UserID,
 			Quantity:      t.Quantity,
 			TotalPrice:    t.TotalPrice,
+			DiscountAmount: t.DiscountAmount,
 			GovtTax:       t.GovtTax,
 			EcommerceTax:  t.EcommerceTax,
 			Status:        string(t.Status),
@@ -1191,7 +1205,7 @@ func (h *ShopHandler) GetOwnerProductOrders(c *gin.Context) {
 			string(constants.TrxStatusCancel):       true,
 		}
 		if !validStatuses[status] {
-			util.RespondJSON(c, http.StatusBadRequest, "Status transaksi tidak valid.")
+			util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidTransactionStatus)
 			return
 		}
 		query = query.Where("status = ?", status)
@@ -1200,7 +1214,7 @@ func (h *ShopHandler) GetOwnerProductOrders(c *gin.Context) {
 	if buyerIDStr != "" {
 		buyerID, err := strconv.ParseUint(buyerIDStr, 10, 64)
 		if err != nil {
-			util.RespondJSON(c, http.StatusBadRequest, "ID pembeli tidak valid.")
+			util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidBuyerID)
 			return
 		}
 		query = query.Where("user_id = ?", buyerID)
@@ -1213,7 +1227,7 @@ func (h *ShopHandler) GetOwnerProductOrders(c *gin.Context) {
 			string(constants.ReceiptCanceled):       true,
 		}
 		if !validReceiptStatuses[receiptStatus] {
-			util.RespondJSON(c, http.StatusBadRequest, "Status receipt tidak valid.")
+			util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidReceiptStatus)
 			return
 		}
 		query = query.Where("receipt_status = ?", receiptStatus)
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/model/dto"
+	"portolio-backend/internal/service"
+	"portolio-backend/internal/util"
+)
+
+// GetStorageUsageAdmin menampilkan pengguna dengan pemakaian penyimpanan terbesar
+func (h *AdminHandler) GetStorageUsageAdmin(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	var totalUsed int64
+	if err := h.db.Model(&db.User{}).Select("COALESCE(SUM(storage_used_bytes), 0)").Row().Scan(&totalUsed); err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	var users []db.User
+	if err := h.db.Where("storage_used_bytes > 0").
+		Order("storage_used_bytes DESC").Limit(limit).
+		Find(&users).Error; err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	consumers := make([]dto.StorageConsumerResponse, len(users))
+	for i, user := range users {
+		consumers[i] = buildStorageConsumerResponse(user)
+	}
+
+	util.RespondJSON(c, http.StatusOK, dto.GetStorageUsageAdminResponse{
+		TotalUsedBytes: totalUsed,
+		Users:          consumers,
+	})
+}
+
+// UpdateUserStorageQuota mengatur kuota penyimpanan khusus pengguna. Kuota yang lebih kecil
+// dari pemakaian saat ini tidak menghapus file, hanya menolak unggahan berikutnya.
+func (h *AdminHandler) UpdateUserStorageQuota(c *gin.Context) {
+	adminIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	adminID := adminIDRaw.(uint)
+
+	targetUserID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgBadRequest)
+		return
+	}
+
+	var req dto.RequestUpdateStorageQuota
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var user db.User
+	if err := h.db.First(&user, targetUserID).Error; err != nil {
+		util.RespondJSON(c, http.StatusNotFound, constants.ErrMsgUserNotFound)
+		return
+	}
+
+	var quotaBytes *int64
+	if req.QuotaMB != nil {
+		bytes := *req.QuotaMB * 1024 * 1024
+		quotaBytes = &bytes
+	}
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("storage_quota_bytes", quotaBytes).Error; err != nil {
+			return fmt.Errorf("failed to update storage quota: %v", err)
+		}
+		user.StorageQuotaBytes = quotaBytes
+
+		details := db.JSONB{"user_email": user.Email, "quota_mb": nil}
+		if req.QuotaMB != nil {
+			details["quota_mb"] = *req.QuotaMB
+		}
+		adminLog := db.AdminLog{
+			AdminID:    adminID,
+			Action:     "update_storage_quota",
+			TargetType: "user",
+			TargetID:   &user.ID,
+			Details:    details,
+			IPAddress:  c.ClientIP(),
+		}
+		return tx.Create(&adminLog).Error
+	})
+	if err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	util.RespondJSON(c, http.StatusOK, gin.H{
+		"message": constants.MsgSuccessStorageQuotaUpdated,
+		"user":    buildStorageConsumerResponse(user),
+	})
+}
+
+func buildStorageConsumerResponse(user db.User) dto.StorageConsumerResponse {
+	return dto.StorageConsumerResponse{
+		UserID:   user.ID,
+		FullName: user.FullName,
+		Email:    user.Email,
+		Role:     user.Role,
+		Storage:  service.StorageUsage(user),
+	}
+}
@@ -9,8 +9,10 @@ import (
 	"gorm.io/gorm"
 
 	"portolio-backend/configs/constants"
+	"portolio-backend/internal/events"
 	"portolio-backend/internal/model/db"
 	"portolio-backend/internal/model/dto"
+	"portolio-backend/internal/service"
 	"portolio-backend/internal/util"
 )
 
@@ -61,27 +63,7 @@ func (h *SupportHandler) CreateSupportTicket(c *gin.Context) {
 			return fmt.Errorf("failed to create initial message: %v", err)
 		}
 
-		var admins []db.User
-		tx.Where("role = ?", constants.RoleAdmin).Find(&admins)
-		for _, admin := range admins {
-			notification := db.Notification{
-				UserID:    admin.ID,
-				Type:      constants.NotifTypeSupport,
-				Message:   fmt.Sprintf("Tiket dukungan baru dari user ID %d: '%s'. Antrean: %d.", ticket.UserID, ticket.Subject, ticket.QueuePos),
-				RelatedID: &ticket.ID,
-			}
-			util.SendNotificationToUser(admin.ID, dto.NotificationResponse{
-				ID:        notification.ID,
-				Type:      notification.Type,
-				Message:   notification.Message,
-				RelatedID: notification.RelatedID,
-				CreatedAt: notification.CreatedAt,
-				IsRead:    notification.IsRead,
-			})
-			tx.Create(&notification)
-		}
-
-		return nil
+		return events.PublishSupportTicket(tx, constants.EventSupportTicketCreated, ticket, userID, &initialMessage.ID)
 	})
 
 	if err != nil {
@@ -198,7 +180,7 @@ func (h *SupportHandler) GetUserSupportTicketMessages(c *gin.Context) {
 			IsAdmin:     msg.Sender.Role == constants.RoleAdmin,
 			Content:     msg.Content,
 			MessageType: msg.MessageType,
-			FileURL:     msg.FileURL,
+			FileURL:     util.SignMediaURL(msg.FileURL, userID),
 			CreatedAt:   msg.CreatedAt,
 		})
 	}
@@ -244,7 +226,7 @@ func (h *SupportHandler) ReplySupportTicket(c *gin.Context) {
 	}
 
 	if ticket.Status == constants.TicketStatusClosed || ticket.Status == constants.TicketStatusCanceled {
-		util.RespondJSON(c, http.StatusBadRequest, "Tidak dapat membalas tiket yang sudah ditutup atau dibatalkan.")
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgTicketClosed)
 		return
 	}
 
@@ -254,11 +236,18 @@ func (h *SupportHandler) ReplySupportTicket(c *gin.Context) {
 			SenderID:    userID,
 			MessageType: req.MessageType,
 			Content:     req.Content,
-			FileURL:     req.FileURL,
+		}
+		if req.FileURL != "" {
+			message.FileURL = util.NormalizeMediaURL(req.FileURL)
 		}
 		if err := tx.Create(&message).Error; err != nil {
 			return fmt.Errorf("failed to create support message: %v", err)
 		}
+		if message.FileURL != "" {
+			if err := service.ClaimUpload(tx, userID, constants.UploadPurposeSupport, message.FileURL, fmt.Sprintf("support_message:%d", message.ID)); err != nil {
+				return err
+			}
+		}
 
 		if ticket.Status == constants.TicketStatusPendingUser {
 			ticket.Status = constants.TicketStatusPendingAdmin
@@ -267,48 +256,14 @@ func (h *SupportHandler) ReplySupportTicket(c *gin.Context) {
 			}
 		}
 
-		if ticket.AssignedAdminID != nil && *ticket.AssignedAdminID != 0 {
-			notificationAdmin := db.Notification{
-				UserID:    *ticket.AssignedAdminID,
-				Type:      constants.NotifTypeSupport,
-				Message:   fmt.Sprintf("Ada balasan baru dari pengguna untuk tiket dukungan #%d ('%s').", ticket.ID, ticket.Subject),
-				RelatedID: &ticket.ID,
-			}
-			util.SendNotificationToUser(*ticket.AssignedAdminID, dto.NotificationResponse{
-				ID:        notificationAdmin.ID,
-				Type:      notificationAdmin.Type,
-				Message:   notificationAdmin.Message,
-				RelatedID: notificationAdmin.RelatedID,
-				CreatedAt: notificationAdmin.CreatedAt,
-				IsRead:    notificationAdmin.IsRead,
-			})
-			tx.Create(&notificationAdmin)
-		} else {
-			var admins []db.User
-			tx.Where("role = ?", constants.RoleAdmin).Find(&admins)
-			for _, admin := range admins {
-				notification := db.Notification{
-					UserID:    admin.ID,
-					Type:      constants.NotifTypeSupport,
-					Message:   fmt.Sprintf("Ada balasan baru dari pengguna untuk tiket dukungan #%d ('%s').", ticket.ID, ticket.Subject),
-					RelatedID: &ticket.ID,
-				}
-				util.SendNotificationToUser(admin.ID, dto.NotificationResponse{
-					ID:        notification.ID,
-					Type:      notification.Type,
-					Message:   notification.Message,
-					RelatedID: notification.RelatedID,
-					CreatedAt: notification.CreatedAt,
-					IsRead:    notification.IsRead,
-				})
-				tx.Create(&notification)
-			}
-		}
-
-		return nil
+		return events.PublishSupportTicket(tx, constants.EventSupportTicketReplied, ticket, userID, &message.ID)
 	})
 
 	if err != nil {
+		if err.Error() == constants.ErrMsgUploadNotOwned {
+			util.RespondJSON(c, http.StatusBadRequest, err.Error())
+			return
+		}
 		util.RespondJSON(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -353,25 +308,7 @@ func (h *SupportHandler) CancelSupportTicket(c *gin.Context) {
 			return fmt.Errorf("failed to cancel ticket: %v", err)
 		}
 
-		if ticket.AssignedAdminID != nil && *ticket.AssignedAdminID != 0 {
-			notificationAdmin := db.Notification{
-				UserID:    *ticket.AssignedAdminID,
-				Type:      constants.NotifTypeSupport,
-				Message:   fmt.Sprintf("Tiket dukungan #%d ('%s') telah dibatalkan oleh pengguna.", ticket.ID, ticket.Subject),
-				RelatedID: &ticket.ID,
-			}
-			util.SendNotificationToUser(*ticket.AssignedAdminID, dto.NotificationResponse{
-				ID:        notificationAdmin.ID,
-				Type:      notificationAdmin.Type,
-				Message:   notificationAdmin.Message,
-				RelatedID: notificationAdmin.RelatedID,
-				CreatedAt: notificationAdmin.CreatedAt,
-				IsRead:    notificationAdmin.IsRead,
-			})
-			tx.Create(&notificationAdmin)
-		}
-
-		return nil
+		return events.PublishSupportTicket(tx, constants.EventSupportTicketCanceled, ticket, userID, nil)
 	})
 
 	if err != nil {
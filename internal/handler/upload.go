@@ -1,14 +1,19 @@
 package handler
 
 import (
+	"log"
+	"mime/multipart"
 	"net/http"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
 	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/service"
 	"portolio-backend/internal/util"
 	"portolio-backend/internal/model/dto"
 )
@@ -24,31 +29,50 @@ func NewUploadHandler(db *gorm.DB) *UploadHandler {
 func (h *UploadHandler) UploadImage(c *gin.Context) {
 	file, err := c.FormFile("image")
 	if err != nil {
-		util.RespondJSON(c, http.StatusBadRequest, "Gambar tidak ditemukan dalam request.")
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgImageMissing)
 		return
 	}
 
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
 	uploadType := c.DefaultQuery("type", "general")
 	destDir := "media/temp"
+	purpose := constants.UploadPurposeGeneral
 
 	switch strings.ToLower(uploadType) {
 	case "chat":
 		destDir = "media/chat"
+		purpose = constants.UploadPurposeChat
 	case "support":
 		destDir = "media/support"
+		purpose = constants.UploadPurposeSupport
 	case "product":
 		destDir = "media/products"
+		purpose = constants.UploadPurposeProduct
 	default:
 		destDir = "media/general"
 	}
 
-	filePath, err := util.SaveUploadedFile(file, destDir, constants.MaxImageSizeMB*1024*1024, util.AllowedImageExtensions)
+	if !h.checkUploadAllowed(c, userID, file.Size) {
+		return
+	}
+
+	filePath, err := util.SaveUploadedImage(file, destDir, int64(util.GetMaxImageSizeMB())*1024*1024)
 	if err != nil {
 		util.RespondJSON(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	fileURL := "/" + filepath.ToSlash(filePath)
+	if err := h.recordUpload(userID, purpose, fileURL, file); err != nil {
+		respondUploadError(c, err)
+		return
+	}
 
 	util.RespondJSON(c, http.StatusOK, dto.UploadFileResponse{ // Changed to DTO
 		Message:  constants.MsgSuccessFileUpload,
@@ -61,31 +85,50 @@ func (h *UploadHandler) UploadImage(c *gin.Context) {
 func (h *UploadHandler) UploadFile(c *gin.Context) {
 	file, err := c.FormFile("file")
 	if err != nil {
-		util.RespondJSON(c, http.StatusBadRequest, "File tidak ditemukan dalam request.")
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgFileMissing)
 		return
 	}
 
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
 	uploadType := c.DefaultQuery("type", "general")
 	destDir := "media/temp"
+	purpose := constants.UploadPurposeGeneral
 
 	switch strings.ToLower(uploadType) {
 	case "chat":
 		destDir = "media/chat"
+		purpose = constants.UploadPurposeChat
 	case "support":
 		destDir = "media/support"
+		purpose = constants.UploadPurposeSupport
 	case "product":
 		destDir = "media/products"
+		purpose = constants.UploadPurposeProduct
 	default:
 		destDir = "media/general"
 	}
 
-	filePath, err := util.SaveUploadedFile(file, destDir, constants.MaxDocumentSizeMB*1024*1024, util.AllowedDocumentExtensions)
+	if !h.checkUploadAllowed(c, userID, file.Size) {
+		return
+	}
+
+	filePath, err := util.SaveUploadedFile(file, destDir, int64(util.GetMaxDocumentSizeMB())*1024*1024, util.AllowedDocumentExtensions)
 	if err != nil {
 		util.RespondJSON(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	fileURL := "/" + filepath.ToSlash(filePath)
+	if err := h.recordUpload(userID, purpose, fileURL, file); err != nil {
+		respondUploadError(c, err)
+		return
+	}
 
 	util.RespondJSON(c, http.StatusOK, dto.UploadFileResponse{
 		Message:  constants.MsgSuccessFileUpload,
@@ -93,4 +136,65 @@ func (h *UploadHandler) UploadFile(c *gin.Context) {
 		FileName: file.Filename,
 		FileSize: file.Size,
 	})
-}
\ No newline at end of file
+}
+
+// checkUploadAllowed menolak unggahan yang melewati batas laju atau kuota penyimpanan
+// sebelum file disimpan. Kuota diperiksa ulang dengan penguncian di recordUpload.
+func (h *UploadHandler) checkUploadAllowed(c *gin.Context, userID uint, size int64) bool {
+	if err := service.CheckUploadRateLimit(h.db, userID, time.Now()); err != nil {
+		respondUploadError(c, err)
+		return false
+	}
+
+	var user db.User
+	if err := h.db.First(&user, userID).Error; err != nil {
+		util.RespondJSON(c, http.StatusNotFound, constants.ErrMsgUserNotFound)
+		return false
+	}
+	if err := service.CheckStorageQuota(user, size); err != nil {
+		respondUploadError(c, err)
+		return false
+	}
+	return true
+}
+
+// recordUpload mencatat pemilik file dan menambah pemakaian kuota pengguna, sehingga
+// file hanya dapat dipakai di pesan milik pengunggahnya. Jika pencatatan gagal, file
+// yang sudah tersimpan dihapus kembali.
+func (h *UploadHandler) recordUpload(userID uint, purpose constants.UploadPurpose, fileURL string, file *multipart.FileHeader) error {
+	fileHash, err := util.HashUploadedFile(file)
+	if err == nil {
+		err = h.db.Transaction(func(tx *gorm.DB) error {
+			if err := service.ReserveStorage(tx, userID, file.Size); err != nil {
+				return err
+			}
+			return tx.Create(&db.Upload{
+				UserID:   userID,
+				Purpose:  purpose,
+				FileURL:  fileURL,
+				FileName: file.Filename,
+				FileHash: fileHash,
+				FileSize: file.Size,
+			}).Error
+		})
+	}
+	if err != nil {
+		log.Printf("❌ Gagal mencatat unggahan %s: %v", fileURL, err)
+		if delErr := util.DeleteMedia(strings.TrimPrefix(fileURL, "/")); delErr != nil {
+			log.Printf("❌ Gagal menghapus file unggahan %s: %v", fileURL, delErr)
+		}
+		return err
+	}
+	return nil
+}
+
+func respondUploadError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == constants.ErrMsgUploadRateLimited:
+		util.RespondJSON(c, http.StatusTooManyRequests, err.Error())
+	case strings.HasPrefix(err.Error(), constants.ErrMsgStorageQuotaExceeded):
+		util.RespondJSON(c, http.StatusRequestEntityTooLarge, err.Error())
+	default:
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+	}
+}
@@ -0,0 +1,395 @@
+package handler
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/model/dto"
+	"portolio-backend/internal/util"
+	"portolio-backend/internal/webhook"
+)
+
+type WebhookHandler struct {
+	db *gorm.DB
+}
+
+func NewWebhookHandler(db *gorm.DB) *WebhookHandler {
+	return &WebhookHandler{db: db}
+}
+
+// GetWebhooks menampilkan webhook milik pengguna tanpa secret
+func (h *WebhookHandler) GetWebhooks(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	var hooks []db.Webhook
+	if err := h.db.Where("user_id = ?", userID).Order("id ASC").Find(&hooks).Error; err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	responses := make([]dto.WebhookResponse, len(hooks))
+	for i, hook := range hooks {
+		responses[i] = buildWebhookResponse(hook, false)
+	}
+	util.RespondJSON(c, http.StatusOK, responses)
+}
+
+// CreateWebhook mendaftarkan endpoint baru. Secret untuk verifikasi tanda tangan hanya
+// ditampilkan di respons ini.
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	var req dto.RequestCreateWebhook
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := webhook.DefaultClient.ValidateURL(req.URL); err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidWebhookURL)
+		return
+	}
+	eventTypes, ok := normalizeWebhookEventTypes(req.EventTypes)
+	if !ok {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidWebhookEventType)
+		return
+	}
+
+	var count int64
+	h.db.Model(&db.Webhook{}).Where("user_id = ?", userID).Count(&count)
+	if count >= constants.MaxWebhooksPerUser {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgWebhookLimitReached)
+		return
+	}
+
+	secret, err := webhook.GenerateSecret()
+	if err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	hook := db.Webhook{
+		UserID:      userID,
+		URL:         req.URL,
+		Description: req.Description,
+		Secret:      secret,
+		EventTypes:  eventTypes,
+		IsActive:    true,
+	}
+	if err := h.db.Create(&hook).Error; err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	util.RespondJSON(c, http.StatusCreated, gin.H{
+		"message": constants.MsgSuccessWebhookCreated,
+		"webhook": buildWebhookResponse(hook, true),
+	})
+}
+
+// UpdateWebhook mengubah URL, deskripsi, event yang dilanggan, atau status aktif webhook
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	hook, ok := h.findWebhook(c)
+	if !ok {
+		return
+	}
+
+	var req dto.RequestUpdateWebhook
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if req.URL != nil {
+		if err := webhook.DefaultClient.ValidateURL(*req.URL); err != nil {
+			util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidWebhookURL)
+			return
+		}
+		updates["url"] = *req.URL
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.EventTypes != nil {
+		eventTypes, ok := normalizeWebhookEventTypes(req.EventTypes)
+		if !ok {
+			util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidWebhookEventType)
+			return
+		}
+		updates["event_types"] = eventTypes
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+	if len(updates) == 0 {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgNoFieldsToUpdate)
+		return
+	}
+
+	if err := h.db.Model(&hook).Updates(updates).Error; err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	h.db.First(&hook, hook.ID)
+	util.RespondJSON(c, http.StatusOK, gin.H{
+		"message": constants.MsgSuccessWebhookUpdated,
+		"webhook": buildWebhookResponse(hook, false),
+	})
+}
+
+// DeleteWebhook menghapus webhook. Pengiriman yang masih mengantre ditandai gagal oleh
+// job pengiriman.
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	hook, ok := h.findWebhook(c)
+	if !ok {
+		return
+	}
+
+	if err := h.db.Delete(&hook).Error; err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	util.RespondJSON(c, http.StatusOK, constants.MsgSuccessWebhookDeleted)
+}
+
+// RotateWebhookSecret mengganti secret webhook. Pengiriman berikutnya, termasuk percobaan
+// ulang, langsung memakai secret baru.
+func (h *WebhookHandler) RotateWebhookSecret(c *gin.Context) {
+	hook, ok := h.findWebhook(c)
+	if !ok {
+		return
+	}
+
+	secret, err := webhook.GenerateSecret()
+	if err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+	if err := h.db.Model(&hook).Update("secret", secret).Error; err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	hook.Secret = secret
+	util.RespondJSON(c, http.StatusOK, gin.H{
+		"message": constants.MsgSuccessWebhookSecretRotated,
+		"webhook": buildWebhookResponse(hook, true),
+	})
+}
+
+// TestWebhook mengirim event webhook.test secara langsung tanpa percobaan ulang dan
+// mengembalikan hasilnya. Pengiriman tetap tercatat di riwayat.
+func (h *WebhookHandler) TestWebhook(c *gin.Context) {
+	hook, ok := h.findWebhook(c)
+	if !ok {
+		return
+	}
+
+	delivery := db.WebhookDelivery{
+		WebhookID: hook.ID,
+		EventType: constants.EventWebhookTest,
+		Payload: db.JSONB{
+			"webhook_id": hook.ID,
+			"message":    "Ini adalah pengiriman uji coba dari Portolio.",
+		},
+		Status:        constants.WebhookDeliverySending,
+		Attempts:      1,
+		NextAttemptAt: time.Now(),
+	}
+	if err := h.db.Create(&delivery).Error; err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	delivery, _ = webhook.Attempt(c.Request.Context(), h.db, webhook.DefaultClient, hook, delivery, 0, true)
+
+	util.RespondJSON(c, http.StatusOK, gin.H{
+		"message":  constants.MsgSuccessWebhookTested,
+		"delivery": buildWebhookDeliveryResponse(delivery),
+	})
+}
+
+// GetWebhookDeliveries menampilkan riwayat pengiriman webhook, terbaru lebih dulu.
+// Filter opsional: status dan event_type.
+func (h *WebhookHandler) GetWebhookDeliveries(c *gin.Context) {
+	hook, ok := h.findWebhook(c)
+	if !ok {
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := h.db.Model(&db.WebhookDelivery{}).Where("webhook_id = ?", hook.ID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if eventType := c.Query("event_type"); eventType != "" {
+		query = query.Where("event_type = ?", eventType)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var deliveries []db.WebhookDelivery
+	if err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&deliveries).Error; err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	responses := make([]dto.WebhookDeliveryResponse, len(deliveries))
+	for i, delivery := range deliveries {
+		responses[i] = buildWebhookDeliveryResponse(delivery)
+	}
+
+	util.RespondJSON(c, http.StatusOK, dto.GetWebhookDeliveriesResponse{
+		TotalRecords: total,
+		Page:         page,
+		Limit:        limit,
+		Deliveries:   responses,
+	})
+}
+
+// RedeliverWebhook menjadwalkan ulang pengiriman yang gagal atau sudah berhasil dengan
+// jumlah percobaan dari awal
+func (h *WebhookHandler) RedeliverWebhook(c *gin.Context) {
+	hook, ok := h.findWebhook(c)
+	if !ok {
+		return
+	}
+
+	deliveryID, err := strconv.ParseUint(c.Param("delivery_id"), 10, 64)
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgBadRequest)
+		return
+	}
+
+	var delivery db.WebhookDelivery
+	if err := h.db.Where("id = ? AND webhook_id = ?", deliveryID, hook.ID).First(&delivery).Error; err != nil {
+		util.RespondJSON(c, http.StatusNotFound, constants.ErrMsgWebhookDeliveryNotFound)
+		return
+	}
+
+	result := h.db.Model(&db.WebhookDelivery{}).
+		Where("id = ? AND status IN ?", delivery.ID, []constants.WebhookDeliveryStatus{constants.WebhookDeliveryFailed, constants.WebhookDeliverySuccess}).
+		Updates(map[string]interface{}{
+			"status":          constants.WebhookDeliveryPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+		})
+	if result.Error != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+	if result.RowsAffected == 0 {
+		util.RespondJSON(c, http.StatusConflict, constants.ErrMsgWebhookDeliveryPending)
+		return
+	}
+
+	h.db.First(&delivery, delivery.ID)
+	util.RespondJSON(c, http.StatusOK, gin.H{
+		"message":  constants.MsgSuccessWebhookRedelivered,
+		"delivery": buildWebhookDeliveryResponse(delivery),
+	})
+}
+
+// findWebhook memuat webhook dari parameter :id milik pengguna yang login dan menulis
+// respons error bila tidak ditemukan
+func (h *WebhookHandler) findWebhook(c *gin.Context) (db.Webhook, bool) {
+	var hook db.Webhook
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return hook, false
+	}
+	userID := userIDRaw.(uint)
+
+	webhookID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgBadRequest)
+		return hook, false
+	}
+
+	if err := h.db.Where("id = ? AND user_id = ?", webhookID, userID).First(&hook).Error; err != nil {
+		util.RespondJSON(c, http.StatusNotFound, constants.ErrMsgWebhookNotFound)
+		return hook, false
+	}
+	return hook, true
+}
+
+// normalizeWebhookEventTypes memvalidasi dan menghapus duplikat tipe event, lalu
+// menggabungkannya untuk disimpan
+func normalizeWebhookEventTypes(eventTypes []constants.EventType) (string, bool) {
+	var unique []string
+	for _, eventType := range eventTypes {
+		if !slices.Contains(constants.WebhookEventTypes, eventType) {
+			return "", false
+		}
+		if !slices.Contains(unique, string(eventType)) {
+			unique = append(unique, string(eventType))
+		}
+	}
+	return strings.Join(unique, ","), len(unique) > 0
+}
+
+func buildWebhookResponse(hook db.Webhook, withSecret bool) dto.WebhookResponse {
+	response := dto.WebhookResponse{
+		ID:          hook.ID,
+		URL:         hook.URL,
+		Description: hook.Description,
+		EventTypes:  webhook.EventTypes(hook),
+		IsActive:    hook.IsActive,
+		CreatedAt:   hook.CreatedAt,
+		UpdatedAt:   hook.UpdatedAt,
+	}
+	if withSecret {
+		response.Secret = hook.Secret
+	}
+	return response
+}
+
+func buildWebhookDeliveryResponse(delivery db.WebhookDelivery) dto.WebhookDeliveryResponse {
+	return dto.WebhookDeliveryResponse{
+		ID:            delivery.ID,
+		WebhookID:     delivery.WebhookID,
+		EventID:       delivery.EventID,
+		EventType:     delivery.EventType,
+		Payload:       delivery.Payload,
+		Status:        delivery.Status,
+		Attempts:      delivery.Attempts,
+		NextAttemptAt: delivery.NextAttemptAt,
+		ResponseCode:  delivery.ResponseCode,
+		ResponseBody:  delivery.ResponseBody,
+		DurationMs:    delivery.DurationMs,
+		LastError:     delivery.LastError,
+		DeliveredAt:   delivery.DeliveredAt,
+		CreatedAt:     delivery.CreatedAt,
+	}
+}
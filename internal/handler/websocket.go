@@ -15,6 +15,8 @@ import (
 	"portolio-backend/configs/constants"
 	"portolio-backend/internal/model/db"
 	"portolio-backend/internal/model/dto"
+	"portolio-backend/internal/notify"
+	"portolio-backend/internal/service"
 	"portolio-backend/internal/util"
 )
 
@@ -65,7 +67,7 @@ func (h *WebsocketHandler) ChatHandler(c *gin.Context) {
 	transactionIDStr := c.Param("transaction_id")
 	transactionID, err := strconv.ParseUint(transactionIDStr, 10, 64)
 	if err != nil {
-		util.RespondJSON(c, http.StatusBadRequest, "ID transaksi tidak valid.")
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgInvalidTransactionID)
 		return
 	}
 
@@ -139,6 +141,12 @@ func (h *WebsocketHandler) readPumpChat(client *util.Client, transactionID uint)
 			continue
 		}
 
+		var frame dto.ChatFrame
+		if err := json.Unmarshal(message, &frame); err == nil && frame.Type == "mark_read" {
+			h.handleChatRead(client, transactionID, frame.UpToID)
+			continue
+		}
+
 		var req dto.RequestSendMessage
 		if err := json.Unmarshal(message, &req); err != nil {
 			log.Printf("Gagal mengurai pesan chat dari UserID %d: %v. Pesan asli: %s", client.UserID, err, string(message))
@@ -168,7 +176,7 @@ func (h *WebsocketHandler) readPumpChat(client *util.Client, transactionID uint)
 		}
 
 		if transaction.UserID != client.UserID && transaction.Product.UserID != client.UserID {
-			errMsg, _ := json.Marshal(map[string]string{"type": "error", "message": "Anda bukan bagian dari transaksi ini."})
+			errMsg, _ := json.Marshal(map[string]string{"type": "error", "message": constants.ErrMsgNotChatParticipant})
 			client.Send <- errMsg
 			continue
 		}
@@ -192,13 +200,30 @@ func (h *WebsocketHandler) readPumpChat(client *util.Client, transactionID uint)
 			ReceiverID:    receiverID,
 			MessageType:   req.MessageType,
 			Content:       req.Content,
-			FileURL:       req.FileURL,
 			IsRead:        false,
 		}
+		if req.FileURL != "" {
+			chatMessage.FileURL = util.NormalizeMediaURL(req.FileURL)
+		}
 
-		if err := h.db.Create(&chatMessage).Error; err != nil {
-			log.Printf("Gagal menyimpan pesan chat ke DB: %v", err)
-			errMsg, _ := json.Marshal(map[string]string{"type": "error", "message": constants.ErrMsgInternalServerError})
+		// File hanya boleh berasal dari unggahan chat milik pengirim sendiri
+		err = h.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&chatMessage).Error; err != nil {
+				return err
+			}
+			if chatMessage.FileURL == "" {
+				return nil
+			}
+			return service.ClaimUpload(tx, client.UserID, constants.UploadPurposeChat, chatMessage.FileURL, fmt.Sprintf("chat_message:%d", chatMessage.ID))
+		})
+		if err != nil {
+			errText := constants.ErrMsgInternalServerError
+			if err.Error() == constants.ErrMsgUploadNotOwned {
+				errText = err.Error()
+			} else {
+				log.Printf("Gagal menyimpan pesan chat ke DB: %v", err)
+			}
+			errMsg, _ := json.Marshal(map[string]string{"type": "error", "message": errText})
 			client.Send <- errMsg
 			continue
 		}
@@ -210,23 +235,16 @@ func (h *WebsocketHandler) readPumpChat(client *util.Client, transactionID uint)
 		}
 
 		notification := db.Notification{
-			UserID:    receiverID,
-			Type:      constants.NotificationType(constants.NotifTypeChat),
-			Message:   fmt.Sprintf("Anda memiliki pesan baru dari %s terkait transaksi ID %d.", senderUser.FullName, req.TransactionID),
-			RelatedID: &chatMessage.ID,
+			UserID:        receiverID,
+			Type:          constants.NotificationType(constants.NotifTypeChat),
+			MessageKey:    "notification.chat_new_message",
+			MessageParams: db.JSONB{"sender": senderUser.FullName, "transaction_id": req.TransactionID},
+			RelatedID:     &chatMessage.ID,
 		}
 
-		if err := h.db.Create(&notification).Error; err != nil { 
+		if err := notify.Send(h.db, &notification); err != nil { 
 			log.Printf("Gagal menyimpan notifikasi chat ke DB: %v", err)
 		}
-		util.SendNotificationToUser(receiverID, dto.NotificationResponse{
-			ID:        notification.ID,
-			Type:      notification.Type,
-			Message:   notification.Message,
-			RelatedID: notification.RelatedID,
-			CreatedAt: notification.CreatedAt,
-			IsRead:    notification.IsRead,
-		})
 
 		chatMsgResp := dto.ChatMessageResponse{
 			ID:            chatMessage.ID,
@@ -243,9 +261,13 @@ func (h *WebsocketHandler) readPumpChat(client *util.Client, transactionID uint)
 			Type:    "chat_message",
 			Message: chatMsgResp,
 		}
+		// URL file ditandatangani terpisah untuk penerima dan pengirim
+		chatWebSocketResp.Message.FileURL = util.SignMediaURL(chatMessage.FileURL, receiverID)
 		jsonMsgToReceiver, _ := json.Marshal(chatWebSocketResp)
 		util.SendToUser(receiverID, jsonMsgToReceiver)
 
+		chatMsgResp.FileURL = util.SignMediaURL(chatMessage.FileURL, client.UserID)
+
 		successWebSocketResp := dto.SuccessMessageWebSocketResponse{
 			Status:      "sent",
 			Message:     "Pesan berhasil dikirim dan disimpan.",
@@ -256,6 +278,29 @@ func (h *WebsocketHandler) readPumpChat(client *util.Client, transactionID uint)
 	}
 }
 
+// handleChatRead memproses frame mark_read: pesan ditandai dibaca, lawan bicara menerima
+// "chat_read", dan pengirim frame menerima "chat_read_ack"
+func (h *WebsocketHandler) handleChatRead(client *util.Client, transactionID, upToID uint) {
+	transaction, _, err := loadChatTransaction(h.db, transactionID, client.UserID)
+	if err != nil {
+		errMsg, _ := json.Marshal(map[string]string{"type": "error", "message": err.Error()})
+		client.Send <- errMsg
+		return
+	}
+
+	receipt, err := markChatRead(h.db, transaction, client.UserID, upToID)
+	if err != nil {
+		log.Printf("Gagal menandai pesan chat transaksi %d sebagai dibaca: %v", transactionID, err)
+		errMsg, _ := json.Marshal(map[string]string{"type": "error", "message": constants.ErrMsgInternalServerError})
+		client.Send <- errMsg
+		return
+	}
+
+	receipt.Type = "chat_read_ack"
+	ackMsg, _ := json.Marshal(receipt)
+	client.Send <- ackMsg
+}
+
 func (h *WebsocketHandler) NotificationHandler(c *gin.Context) {
 	userIDRaw, exists := c.Get("ID")
 	if !exists {
@@ -293,7 +338,7 @@ func (h *WebsocketHandler) NotificationHandler(c *gin.Context) {
 	go h.readPumpGeneric(client) 
 	go writePump(client)
 
-	go h.sendUnreadNotifications(client)
+	go h.sendUnreadNotifications(client, util.RequestLanguage(c))
 }
 
 func (h *WebsocketHandler) readPumpGeneric(client *util.Client) {
@@ -329,25 +374,21 @@ func (h *WebsocketHandler) readPumpGeneric(client *util.Client) {
 	}
 }
 
-func (h *WebsocketHandler) sendUnreadNotifications(client *util.Client) {
+// sendUnreadNotifications mengirim notifikasi yang belum dibaca saat koneksi dibuka dalam
+// bahasa pembaca.
+// Status dibaca tidak diubah di sini; klien menandainya melalui /api/notifications,
+// sehingga jumlahnya dibatasi dan sisanya dapat diambil dari endpoint yang sama.
+func (h *WebsocketHandler) sendUnreadNotifications(client *util.Client, lang constants.Language) {
 	var notifications []db.Notification
-	if err := h.db.Where("user_id = ? AND is_read = ?", client.UserID, false).Order("created_at DESC").Find(&notifications).Error; err != nil {
+	if err := h.db.Where("user_id = ? AND is_read = ?", client.UserID, false).Order("created_at DESC").Limit(50).Find(&notifications).Error; err != nil {
 		log.Printf("Gagal mengambil notifikasi belum dibaca untuk user %d: %v", client.UserID, err)
 		return
 	}
 
 	for _, notif := range notifications {
-		resp := dto.NotificationResponse{
-			ID:        notif.ID,
-			Type:      notif.Type,
-			Message:   notif.Message,
-			RelatedID: notif.RelatedID,
-			CreatedAt: notif.CreatedAt,
-			IsRead:    notif.IsRead,
-		}
 		notificationWebSocketResp := dto.NotificationWebSocketResponse{
 			Type:    "notification",
-			Message: resp,
+			Message: notify.Response(notif, lang),
 		}
 		jsonMsg, err := json.Marshal(notificationWebSocketResp)
 		if err != nil {
@@ -363,18 +404,6 @@ func (h *WebsocketHandler) sendUnreadNotifications(client *util.Client) {
 			log.Printf("Channel kirim notifikasi penuh untuk user %d saat mengirim yang belum dibaca. Pesan ID: %d", client.UserID, notif.ID)
 		}
 	}
-
-	if len(notifications) > 0 {
-		var ids []uint
-		for _, n := range notifications {
-			ids = append(ids, n.ID)
-		}
-		if err := h.db.Model(&db.Notification{}).Where("id IN (?)", ids).Update("is_read", true).Error; err != nil {
-			log.Printf("Failed to mark notifications as read for user %d: %v", client.UserID, err)
-		} else {
-			log.Printf("Marked %d notifications as read for UserID %d", len(notifications), client.UserID)
-		}
-	}
 }
 
 func writePump(client *util.Client) {
@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/model/dto"
+	"portolio-backend/internal/service"
+	"portolio-backend/internal/util"
+)
+
+// GetWishlist menampilkan wishlist pengguna, termasuk produk yang sedang habis
+// sehingga pembeli tetap dapat melihat dan berlangganan notifikasi stok.
+func (h *ShopHandler) GetWishlist(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	pageStr := c.DefaultQuery("page", "1")
+	limitStr := c.DefaultQuery("limit", "20")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := h.db.Where("user_id = ?", userID)
+
+	var total int64
+	query.Model(&db.WishlistItem{}).Count(&total)
+
+	var items []db.WishlistItem
+	if err := query.Preload("Product").Preload("Product.Images").
+		Order("created_at DESC").Limit(limit).Offset(offset).Find(&items).Error; err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	productIDs := make([]uint, len(items))
+	for i, item := range items {
+		productIDs[i] = item.ProductID
+	}
+	var subscribedIDs []uint
+	if len(productIDs) > 0 {
+		h.db.Model(&db.RestockSubscription{}).
+			Where("user_id = ? AND product_id IN ? AND notified_at IS NULL", userID, productIDs).
+			Pluck("product_id", &subscribedIDs)
+	}
+	subscribed := make(map[uint]bool, len(subscribedIDs))
+	for _, id := range subscribedIDs {
+		subscribed[id] = true
+	}
+
+	responseItems := make([]dto.WishlistItemResponse, 0, len(items))
+	for _, item := range items {
+		// Produk yang sudah dihapus tidak ikut dimuat oleh Preload
+		if item.Product.ID == 0 {
+			continue
+		}
+		resp := dto.WishlistItemResponse{
+			ID:                item.ID,
+			ProductID:         item.ProductID,
+			Title:             item.Product.Title,
+			Price:             item.Product.Price,
+			PreviousPrice:     item.Product.PreviousPrice,
+			Stock:             item.Product.Stock,
+			IsAvailable:       item.Product.IsActive && item.Product.Stock > 0 && item.Product.Visibility == constants.ProductVisibilityAll,
+			RestockSubscribed: subscribed[item.ProductID],
+			ImageURL:          service.PrimaryImageURL(item.Product.Images),
+			CreatedAt:         item.CreatedAt,
+		}
+		responseItems = append(responseItems, resp)
+	}
+
+	util.RespondJSON(c, http.StatusOK, dto.GetWishlistResponse{
+		TotalRecords: total,
+		Page:         page,
+		Limit:        limit,
+		Items:        responseItems,
+	})
+}
+
+func (h *ShopHandler) AddWishlistItem(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	var req dto.RequestAddWishlist
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var product db.Product
+	if err := h.db.Where("id = ? AND is_active = ? AND visibility <> ?", req.ProductID, true, constants.ProductVisibilityAdminOnly).
+		First(&product).Error; err != nil {
+		util.RespondJSON(c, http.StatusNotFound, constants.ErrMsgProductNotFound)
+		return
+	}
+
+	var existing db.WishlistItem
+	if err := h.db.Where("user_id = ? AND product_id = ?", userID, product.ID).First(&existing).Error; err == nil {
+		util.RespondJSON(c, http.StatusOK, constants.MsgSuccessWishlistAdded)
+		return
+	}
+
+	item := db.WishlistItem{
+		UserID:    userID,
+		ProductID: product.ID,
+	}
+	if err := h.db.Create(&item).Error; err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	util.RespondJSON(c, http.StatusCreated, constants.MsgSuccessWishlistAdded)
+}
+
+func (h *ShopHandler) RemoveWishlistItem(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	productID, err := strconv.ParseUint(c.Param("product_id"), 10, 64)
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgBadRequest)
+		return
+	}
+
+	result := h.db.Unscoped().Where("user_id = ? AND product_id = ?", userID, productID).Delete(&db.WishlistItem{})
+	if result.Error != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+	if result.RowsAffected == 0 {
+		util.RespondJSON(c, http.StatusNotFound, constants.ErrMsgWishlistItemNotFound)
+		return
+	}
+
+	util.RespondJSON(c, http.StatusOK, constants.MsgSuccessWishlistRemoved)
+}
+
+// SubscribeRestock mendaftarkan pengguna untuk menerima notifikasi saat stok produk kembali tersedia
+func (h *ShopHandler) SubscribeRestock(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgBadRequest)
+		return
+	}
+
+	var product db.Product
+	if err := h.db.Where("id = ? AND is_active = ? AND visibility <> ?", productID, true, constants.ProductVisibilityAdminOnly).
+		First(&product).Error; err != nil {
+		util.RespondJSON(c, http.StatusNotFound, constants.ErrMsgProductNotFound)
+		return
+	}
+	if product.UserID == userID {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgProductSelfPurchase)
+		return
+	}
+	if product.Stock > 0 && product.Visibility == constants.ProductVisibilityAll {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgProductStillInStock)
+		return
+	}
+
+	var subscription db.RestockSubscription
+	if err := h.db.Where("user_id = ? AND product_id = ?", userID, product.ID).First(&subscription).Error; err == nil {
+		if subscription.NotifiedAt != nil {
+			h.db.Model(&subscription).Update("notified_at", nil)
+		}
+		util.RespondJSON(c, http.StatusOK, constants.MsgSuccessRestockSubscribed)
+		return
+	}
+
+	subscription = db.RestockSubscription{
+		UserID:    userID,
+		ProductID: product.ID,
+	}
+	if err := h.db.Create(&subscription).Error; err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	util.RespondJSON(c, http.StatusCreated, constants.MsgSuccessRestockSubscribed)
+}
+
+func (h *ShopHandler) UnsubscribeRestock(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.RespondJSON(c, http.StatusBadRequest, constants.ErrMsgBadRequest)
+		return
+	}
+
+	result := h.db.Unscoped().Where("user_id = ? AND product_id = ?", userID, productID).Delete(&db.RestockSubscription{})
+	if result.Error != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+	if result.RowsAffected == 0 {
+		util.RespondJSON(c, http.StatusNotFound, constants.ErrMsgRestockSubscriptionNotFound)
+		return
+	}
+
+	util.RespondJSON(c, http.StatusOK, constants.MsgSuccessRestockUnsubscribed)
+}
+
+func (h *ShopHandler) GetRestockSubscriptions(c *gin.Context) {
+	userIDRaw, exists := c.Get("ID")
+	if !exists {
+		util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgUnauthorized)
+		return
+	}
+	userID := userIDRaw.(uint)
+
+	var subscriptions []db.RestockSubscription
+	if err := h.db.Preload("Product").Where("user_id = ?", userID).
+		Order("created_at DESC").Find(&subscriptions).Error; err != nil {
+		util.RespondJSON(c, http.StatusInternalServerError, constants.ErrMsgInternalServerError)
+		return
+	}
+
+	response := make([]dto.RestockSubscriptionResponse, 0, len(subscriptions))
+	for _, sub := range subscriptions {
+		if sub.Product.ID == 0 {
+			continue
+		}
+		response = append(response, dto.RestockSubscriptionResponse{
+			ID:         sub.ID,
+			ProductID:  sub.ProductID,
+			Title:      sub.Product.Title,
+			Stock:      sub.Product.Stock,
+			NotifiedAt: sub.NotifiedAt,
+			CreatedAt:  sub.CreatedAt,
+		})
+	}
+
+	util.RespondJSON(c, http.StatusOK, response)
+}
@@ -0,0 +1,141 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"portolio-backend/configs/constants"
+)
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// Params adalah nilai untuk placeholder {nama} di dalam template pesan
+type Params map[string]interface{}
+
+// Message adalah pesan katalog beserta parameternya. RespondJSON menerjemahkannya ke
+// bahasa pembaca sebelum dikirim.
+type Message struct {
+	Key    string
+	Params Params
+}
+
+var (
+	catalogs = map[constants.Language]map[string]string{}
+	// messageTexts berisi konstanta pesan dari messageKeys, diurutkan dari yang terpanjang
+	// untuk pencocokan awalan pesan
+	messageTexts []string
+
+	placeholderPattern = regexp.MustCompile(`\{(\w+)\}`)
+)
+
+func init() {
+	for _, lang := range constants.Languages {
+		data, err := localeFS.ReadFile("locales/" + string(lang) + ".json")
+		if err != nil {
+			panic(fmt.Sprintf("katalog bahasa %s tidak ditemukan: %v", lang, err))
+		}
+		catalog := map[string]string{}
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			panic(fmt.Sprintf("katalog bahasa %s tidak valid: %v", lang, err))
+		}
+		catalogs[lang] = catalog
+	}
+
+	for text := range messageKeys {
+		messageTexts = append(messageTexts, text)
+	}
+	sort.Slice(messageTexts, func(i, j int) bool { return len(messageTexts[i]) > len(messageTexts[j]) })
+}
+
+// T merender pesan key dalam bahasa lang. Key yang belum diterjemahkan memakai bahasa
+// bawaan, dan key yang tidak dikenal dikembalikan apa adanya. Placeholder tanpa nilai
+// dibiarkan tetap tertulis. Parameter berupa konstanta pesan ErrMsg* atau Msg* ikut
+// diterjemahkan.
+func T(lang constants.Language, key string, params Params) string {
+	text, ok := catalogs[lang][key]
+	if !ok {
+		text, ok = catalogs[constants.DefaultLanguage][key]
+	}
+	if !ok {
+		return key
+	}
+	if len(params) == 0 {
+		return text
+	}
+	return placeholderPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		value, ok := params[placeholder[1:len(placeholder)-1]]
+		if !ok {
+			return placeholder
+		}
+		return formatParam(lang, value)
+	})
+}
+
+// Translate menerjemahkan konstanta pesan ErrMsg* dan Msg* ke bahasa lang melalui key
+// katalognya di messageKeys. Pesan yang diawali konstanta, misalnya konstanta yang diberi
+// tambahan ID, diterjemahkan bagian awalnya saja. Pesan lain dikembalikan apa adanya.
+func Translate(lang constants.Language, message string) string {
+	if lang == constants.DefaultLanguage || message == "" {
+		return message
+	}
+	if key, ok := messageKeys[message]; ok {
+		return T(lang, key, nil)
+	}
+	for _, text := range messageTexts {
+		if strings.HasPrefix(message, text) {
+			return T(lang, messageKeys[text], nil) + message[len(text):]
+		}
+	}
+	return message
+}
+
+// Ref membuat parameter yang berisi pesan katalog lain, misalnya label tipe notifikasi.
+// Bentuknya map biasa agar tetap dapat dibaca setelah disimpan sebagai JSONB.
+func Ref(key string, params Params) map[string]interface{} {
+	ref := map[string]interface{}{"key": key}
+	if len(params) > 0 {
+		ref["params"] = map[string]interface{}(params)
+	}
+	return ref
+}
+
+// Render merender Message dalam bahasa lang
+func (m Message) Render(lang constants.Language) string {
+	return T(lang, m.Key, m.Params)
+}
+
+// Has memeriksa apakah key terdaftar di katalog bahasa bawaan
+func Has(key string) bool {
+	_, ok := catalogs[constants.DefaultLanguage][key]
+	return ok
+}
+
+// formatParam menulis nilai parameter; angka dari JSONB (float64) ditulis tanpa notasi
+// eksponen, sedangkan teks katalog dan Ref dirender dalam bahasa lang
+func formatParam(lang constants.Language, value interface{}) string {
+	switch v := value.(type) {
+	case Message:
+		return v.Render(lang)
+	case map[string]interface{}:
+		key, _ := v["key"].(string)
+		params, _ := v["params"].(map[string]interface{})
+		return T(lang, key, params)
+	case string:
+		if key, ok := messageKeys[v]; ok && lang != constants.DefaultLanguage {
+			return T(lang, key, nil)
+		}
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,67 @@
+package i18n
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"portolio-backend/configs/constants"
+)
+
+// Normalize mengubah tag bahasa seperti "en-US" atau "ID" menjadi bahasa yang didukung.
+// Mengembalikan false bila bahasa tidak memiliki katalog.
+func Normalize(tag string) (constants.Language, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	lang := constants.Language(tag)
+	if !slices.Contains(constants.Languages, lang) {
+		return "", false
+	}
+	return lang, true
+}
+
+// FromAcceptLanguage memilih bahasa yang didukung dengan bobot q tertinggi dari header
+// Accept-Language. Mengembalikan false bila tidak ada yang cocok.
+func FromAcceptLanguage(header string) (constants.Language, bool) {
+	type candidate struct {
+		lang    constants.Language
+		quality float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		lang, ok := Normalize(tag)
+		if !ok || quality <= 0 {
+			continue
+		}
+		candidates = append(candidates, candidate{lang: lang, quality: quality})
+	}
+	if len(candidates) == 0 {
+		return "", false
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].quality > candidates[j].quality })
+	return candidates[0].lang, true
+}
+
+// Resolve menentukan bahasa pembaca: pengaturan profil lebih dulu, lalu Accept-Language,
+// lalu constants.DefaultLanguage
+func Resolve(preferred constants.Language, acceptLanguage string) constants.Language {
+	if lang, ok := Normalize(string(preferred)); ok {
+		return lang
+	}
+	if lang, ok := FromAcceptLanguage(acceptLanguage); ok {
+		return lang
+	}
+	return constants.DefaultLanguage
+}
@@ -0,0 +1,204 @@
+package i18n
+
+import "portolio-backend/configs/constants"
+
+// messageKeys memetakan konstanta pesan di configs/constants ke key katalognya sehingga
+// handler dapat tetap mengirim konstanta ErrMsg* dan Msg* ke RespondJSON. Setiap konstanta
+// pesan baru wajib ditambahkan di sini; TestMessageKeysCoverConstants memeriksanya.
+var messageKeys = map[string]string{
+	constants.MsgSuccessLogin:                          "success.login",
+	constants.MsgSuccessRegister:                       "success.register",
+	constants.MsgSuccessTopUp:                          "success.top_up",
+	constants.MsgSuccessWithdraw:                       "success.withdraw",
+	constants.MsgSuccessProductCreated:                 "success.product_created",
+	constants.MsgSuccessProductUpdated:                 "success.product_updated",
+	constants.MsgSuccessProductDeleted:                 "success.product_deleted",
+	constants.MsgSuccessPurchase:                       "success.purchase",
+	constants.MsgSuccessTransactionConfirmed:           "success.transaction_confirmed",
+	constants.MsgSuccessTransactionCanceled:            "success.transaction_canceled",
+	constants.MsgSuccessAccountUpdated:                 "success.account_updated",
+	constants.MsgSuccessAccountDeleted:                 "success.account_deleted",
+	constants.MsgSuccessUserSuspended:                  "success.user_suspended",
+	constants.MsgSuccessUserBanned:                     "success.user_banned",
+	constants.MsgSuccessUserUnbanned:                   "success.user_unbanned",
+	constants.MsgSuccessTicketCreated:                  "success.ticket_created",
+	constants.MsgSuccessTicketClaimed:                  "success.ticket_claimed",
+	constants.MsgSuccessTicketTransferred:              "success.ticket_transferred",
+	constants.MsgSuccessTicketClosed:                   "success.ticket_closed",
+	constants.MsgSuccessTicketCanceled:                 "success.ticket_canceled",
+	constants.MsgSuccessMessageSent:                    "success.message_sent",
+	constants.MsgSuccessVisibilityUpdated:              "success.visibility_updated",
+	constants.MsgSuccessAdminLogin:                     "success.admin_login",
+	constants.MsgSuccessFileUpload:                     "success.file_upload",
+	constants.MsgSuccessPriceScheduled:                 "success.price_scheduled",
+	constants.MsgSuccessPriceScheduleCanceled:          "success.price_schedule_canceled",
+	constants.MsgSuccessImportQueued:                   "success.import_queued",
+	constants.MsgSuccessCouponCreated:                  "success.coupon_created",
+	constants.MsgSuccessCouponUpdated:                  "success.coupon_updated",
+	constants.MsgSuccessWishlistAdded:                  "success.wishlist_added",
+	constants.MsgSuccessWishlistRemoved:                "success.wishlist_removed",
+	constants.MsgSuccessRestockSubscribed:              "success.restock_subscribed",
+	constants.MsgSuccessRestockUnsubscribed:            "success.restock_unsubscribed",
+	constants.MsgSuccessStockReserved:                  "success.stock_reserved",
+	constants.MsgSuccessReservationReleased:            "success.reservation_released",
+	constants.MsgSuccessStockAdjusted:                  "success.stock_adjusted",
+	constants.MsgSuccessProductImagesAdded:             "success.product_images_added",
+	constants.MsgSuccessProductImageUpdated:            "success.product_image_updated",
+	constants.MsgSuccessProductImageDeleted:            "success.product_image_deleted",
+	constants.MsgSuccessProductImagesReordered:         "success.product_images_reordered",
+	constants.MsgSuccessStorageQuotaUpdated:            "success.storage_quota_updated",
+	constants.MsgSuccessNotificationsRead:              "success.notifications_read",
+	constants.MsgSuccessNotificationDeleted:            "success.notification_deleted",
+	constants.MsgSuccessNotificationPreferencesUpdated: "success.notification_preferences_updated",
+	constants.MsgSuccessOutboxEventReplayed:            "success.outbox_event_replayed",
+	constants.MsgSuccessAdminDashboard:                 "success.admin_dashboard",
+	constants.MsgSuccessWebhookCreated:                 "success.webhook_created",
+	constants.MsgSuccessWebhookUpdated:                 "success.webhook_updated",
+	constants.MsgSuccessWebhookDeleted:                 "success.webhook_deleted",
+	constants.MsgSuccessWebhookSecretRotated:           "success.webhook_secret_rotated",
+	constants.MsgSuccessWebhookTested:                  "success.webhook_tested",
+	constants.MsgSuccessWebhookRedelivered:             "success.webhook_redelivered",
+	constants.MsgSuccessPushSubscribed:                 "success.push_subscribed",
+	constants.MsgSuccessPushUnsubscribed:               "success.push_unsubscribed",
+	constants.MsgSuccessChatMarkedRead:                 "success.chat_marked_read",
+	constants.ErrMsgInternalServerError:                "error.internal_server_error",
+	constants.ErrMsgValidationFailed:                   "error.validation_failed",
+	constants.ErrMsgJSONTypeMismatch:                   "error.json_type_mismatch",
+	constants.ErrMsgJSONSyntaxError:                    "error.json_syntax_error",
+	constants.ErrMsgUnauthorized:                       "error.unauthorized",
+	constants.ErrMsgForbidden:                          "error.forbidden",
+	constants.ErrMsgNotFound:                           "error.not_found",
+	constants.ErrMsgBadRequest:                         "error.bad_request",
+	constants.ErrMsgInvalidEmailFormat:                 "error.invalid_email_format",
+	constants.ErrMsgInvalidFullNameFormat:              "error.invalid_full_name_format",
+	constants.ErrMsgPasswordWeak:                       "error.password_weak",
+	constants.ErrMsgEmailAlreadyRegistered:             "error.email_already_registered",
+	constants.ErrMsgUserNotFound:                       "error.user_not_found",
+	constants.ErrMsgInvalidCredentials:                 "error.invalid_credentials",
+	constants.ErrMsgAmountInvalid:                      "error.amount_invalid",
+	constants.ErrMsgInsufficientBalance:                "error.insufficient_balance",
+	constants.ErrMsgMinWithdrawAmount:                  "error.min_withdraw_amount",
+	constants.ErrMsgProductNotFound:                    "error.product_not_found",
+	constants.ErrMsgProductSelfPurchase:                "error.product_self_purchase",
+	constants.ErrMsgInsufficientStock:                  "error.insufficient_stock",
+	constants.ErrMsgTransactionNotFound:                "error.transaction_not_found",
+	constants.ErrMsgTransactionNotCancellable:          "error.transaction_not_cancellable",
+	constants.ErrMsgTransactionNotConfirmable:          "error.transaction_not_confirmable",
+	constants.ErrMsgNotProductOwner:                    "error.not_product_owner",
+	constants.ErrMsgNotTransactionOwner:                "error.not_transaction_owner",
+	constants.ErrMsgFileTooLarge:                       "error.file_too_large",
+	constants.ErrMsgInvalidFileType:                    "error.invalid_file_type",
+	constants.ErrMsgAccountSuspended:                   "error.account_suspended",
+	constants.ErrMsgAccountBanned:                      "error.account_banned",
+	constants.ErrMsgAccountDeleted:                     "error.account_deleted",
+	constants.ErrMsgTicketNotFound:                     "error.ticket_not_found",
+	constants.ErrMsgTicketNotClaimable:                 "error.ticket_not_claimable",
+	constants.ErrMsgTicketAlreadyClaimed:               "error.ticket_already_claimed",
+	constants.ErrMsgTicketNotTransferable:              "error.ticket_not_transferable",
+	constants.ErrMsgTicketNotCancellable:               "error.ticket_not_cancellable",
+	constants.ErrMsgChatNotAllowed:                     "error.chat_not_allowed",
+	constants.ErrMsgInvalidOldPassword:                 "error.invalid_old_password",
+	constants.ErrMsgAdminSessionExpired:                "error.admin_session_expired",
+	constants.ErrMsgAdminSessionInvalid:                "error.admin_session_invalid",
+	constants.ErrMsgAdminNotAuthorized:                 "error.admin_not_authorized",
+	constants.ErrMsgAdminLoginFailed:                   "error.admin_login_failed",
+	constants.ErrMsgProductAlreadyHidden:               "error.product_already_hidden",
+	constants.ErrMsgProductAlreadyVisible:              "error.product_already_visible",
+	constants.ErrMsgNoFieldsToUpdate:                   "error.no_fields_to_update",
+	constants.ErrMsgPriceScheduleNotFound:              "error.price_schedule_not_found",
+	constants.ErrMsgPriceScheduleInvalidTime:           "error.price_schedule_invalid_time",
+	constants.ErrMsgPriceScheduleOverlap:               "error.price_schedule_overlap",
+	constants.ErrMsgPriceScheduleNotCancellable:        "error.price_schedule_not_cancellable",
+	constants.ErrMsgImportJobNotFound:                  "error.import_job_not_found",
+	constants.ErrMsgImportFormatInvalid:                "error.import_format_invalid",
+	constants.ErrMsgImportEmpty:                        "error.import_empty",
+	constants.ErrMsgImportTooManyRows:                  "error.import_too_many_rows",
+	constants.ErrMsgCouponNotFound:                     "error.coupon_not_found",
+	constants.ErrMsgCouponCodeTaken:                    "error.coupon_code_taken",
+	constants.ErrMsgCouponExpired:                      "error.coupon_expired",
+	constants.ErrMsgCouponUsageExceeded:                "error.coupon_usage_exceeded",
+	constants.ErrMsgCouponUserLimitExceeded:            "error.coupon_user_limit_exceeded",
+	constants.ErrMsgCouponMinSpend:                     "error.coupon_min_spend",
+	constants.ErrMsgCouponNotApplicable:                "error.coupon_not_applicable",
+	constants.ErrMsgCouponInvalidValue:                 "error.coupon_invalid_value",
+	constants.ErrMsgWishlistItemNotFound:               "error.wishlist_item_not_found",
+	constants.ErrMsgRestockSubscriptionNotFound:        "error.restock_subscription_not_found",
+	constants.ErrMsgProductStillInStock:                "error.product_still_in_stock",
+	constants.ErrMsgReservationNotFound:                "error.reservation_not_found",
+	constants.ErrMsgReservationExpired:                 "error.reservation_expired",
+	constants.ErrMsgReservationMismatch:                "error.reservation_mismatch",
+	constants.ErrMsgStockAdjustmentInvalid:             "error.stock_adjustment_invalid",
+	constants.ErrMsgProductImageNotFound:               "error.product_image_not_found",
+	constants.ErrMsgProductImageLimit:                  "error.product_image_limit",
+	constants.ErrMsgProductImageOrderInvalid:           "error.product_image_order_invalid",
+	constants.ErrMsgProductImageRequired:               "error.product_image_required",
+	constants.ErrMsgInvalidImage:                       "error.invalid_image",
+	constants.ErrMsgImageDimensionsTooLarge:            "error.image_dimensions_too_large",
+	constants.ErrMsgFileContentMismatch:                "error.file_content_mismatch",
+	constants.ErrMsgFileInfected:                       "error.file_infected",
+	constants.ErrMsgFileScanFailed:                     "error.file_scan_failed",
+	constants.ErrMsgRemoteURLBlocked:                   "error.remote_url_blocked",
+	constants.ErrMsgRemoteURLTooManyRedirects:          "error.remote_url_too_many_redirects",
+	constants.ErrMsgMediaSignatureInvalid:              "error.media_signature_invalid",
+	constants.ErrMsgUploadNotOwned:                     "error.upload_not_owned",
+	constants.ErrMsgStorageQuotaExceeded:               "error.storage_quota_exceeded",
+	constants.ErrMsgUploadRateLimited:                  "error.upload_rate_limited",
+	constants.ErrMsgNotificationNotFound:               "error.notification_not_found",
+	constants.ErrMsgInvalidNotificationType:            "error.invalid_notification_type",
+	constants.ErrMsgInvalidNotificationChannel:         "error.invalid_notification_channel",
+	constants.ErrMsgNotificationChannelRequired:        "error.notification_channel_required",
+	constants.ErrMsgInvalidQuietHours:                  "error.invalid_quiet_hours",
+	constants.ErrMsgOutboxEventNotFound:                "error.outbox_event_not_found",
+	constants.ErrMsgOutboxEventNotReplayable:           "error.outbox_event_not_replayable",
+	constants.ErrMsgAdminSelfDelete:                    "error.admin_self_delete",
+	constants.ErrMsgTicketNotAssigned:                  "error.ticket_not_assigned",
+	constants.ErrMsgPurchaseItemsEmpty:                 "error.purchase_items_empty",
+	constants.ErrMsgReservationItemsEmpty:              "error.reservation_items_empty",
+	constants.ErrMsgImportFileMissing:                  "error.import_file_missing",
+	constants.ErrMsgFileMissing:                        "error.file_missing",
+	constants.ErrMsgImageMissing:                       "error.image_missing",
+	constants.ErrMsgInvalidBuyerID:                     "error.invalid_buyer_id",
+	constants.ErrMsgProductIDRequired:                  "error.product_id_required",
+	constants.ErrMsgInvalidTransactionID:               "error.invalid_transaction_id",
+	constants.ErrMsgInvalidBalanceAction:               "error.invalid_balance_action",
+	constants.ErrMsgInvalidAdminID:                     "error.invalid_admin_id",
+	constants.ErrMsgInvalidProductID:                   "error.invalid_product_id",
+	constants.ErrMsgInvalidReceiptStatus:               "error.invalid_receipt_status",
+	constants.ErrMsgInvalidRole:                        "error.invalid_role",
+	constants.ErrMsgInvalidUserStatus:                  "error.invalid_user_status",
+	constants.ErrMsgInvalidBalanceStatus:               "error.invalid_balance_status",
+	constants.ErrMsgInvalidTransactionStatus:           "error.invalid_transaction_status",
+	constants.ErrMsgInvalidUserID:                      "error.invalid_user_id",
+	constants.ErrMsgOldPasswordRequired:                "error.old_password_required",
+	constants.ErrMsgCouponSellerRequired:               "error.coupon_seller_required",
+	constants.ErrMsgInvalidIsReadParam:                 "error.invalid_is_read_param",
+	constants.ErrMsgUserAlreadyActive:                  "error.user_already_active",
+	constants.ErrMsgUserAlreadyBanned:                  "error.user_already_banned",
+	constants.ErrMsgUserAlreadyDeleted:                 "error.user_already_deleted",
+	constants.ErrMsgUserAlreadySuspended:               "error.user_already_suspended",
+	constants.ErrMsgProductOutOfStockVisibility:        "error.product_out_of_stock_visibility",
+	constants.ErrMsgInvalidTransactionStatusFilter:     "error.invalid_transaction_status_filter",
+	constants.ErrMsgTicketClosed:                       "error.ticket_closed",
+	constants.ErrMsgCannotBanAdmin:                     "error.cannot_ban_admin",
+	constants.ErrMsgCannotSuspendAdmin:                 "error.cannot_suspend_admin",
+	constants.ErrMsgCannotUnbanAdmin:                   "error.cannot_unban_admin",
+	constants.ErrMsgInvalidEventType:                   "error.invalid_event_type",
+	constants.ErrMsgTokenExpired:                       "error.token_expired",
+	constants.ErrMsgVisibilityNotEditable:              "error.visibility_not_editable",
+	constants.ErrMsgInvalidVisibility:                  "error.invalid_visibility",
+	constants.ErrMsgInvalidVisibilityAdmin:             "error.invalid_visibility_admin",
+	constants.ErrMsgInvalidDigestFrequency:             "error.invalid_digest_frequency",
+	constants.ErrMsgDigestNotAllowed:                   "error.digest_not_allowed",
+	constants.ErrMsgWebhookNotFound:                    "error.webhook_not_found",
+	constants.ErrMsgWebhookDeliveryNotFound:            "error.webhook_delivery_not_found",
+	constants.ErrMsgInvalidWebhookURL:                  "error.invalid_webhook_url",
+	constants.ErrMsgInvalidWebhookEventType:            "error.invalid_webhook_event_type",
+	constants.ErrMsgWebhookLimitReached:                "error.webhook_limit_reached",
+	constants.ErrMsgWebhookDeliveryPending:             "error.webhook_delivery_pending",
+	constants.ErrMsgWebPushNotConfigured:               "error.web_push_not_configured",
+	constants.ErrMsgInvalidPushSubscription:            "error.invalid_push_subscription",
+	constants.ErrMsgPushSubscriptionNotFound:           "error.push_subscription_not_found",
+	constants.ErrMsgPushSubscriptionLimit:              "error.push_subscription_limit",
+	constants.ErrMsgInvalidChatCursor:                  "error.invalid_chat_cursor",
+	constants.ErrMsgNotChatParticipant:                 "error.not_chat_participant",
+}
@@ -0,0 +1,80 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"testing"
+
+	"portolio-backend/configs/constants"
+)
+
+// messageConstants membaca nama dan nilai setiap konstanta pesan di configs/constants
+func messageConstants(t *testing.T) map[string]string {
+	t.Helper()
+	file, err := parser.ParseFile(token.NewFileSet(), "../../configs/constants/messages.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	consts := map[string]string{}
+	ast.Inspect(file, func(node ast.Node) bool {
+		spec, ok := node.(*ast.ValueSpec)
+		if !ok {
+			return true
+		}
+		for i, name := range spec.Names {
+			lit, ok := spec.Values[i].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				t.Errorf("%s bukan literal string", name.Name)
+				continue
+			}
+			consts[name.Name], _ = strconv.Unquote(lit.Value)
+		}
+		return false
+	})
+	if len(consts) == 0 {
+		t.Fatal("tidak ada konstanta pesan yang terbaca")
+	}
+	return consts
+}
+
+func TestMessageKeysCoverConstants(t *testing.T) {
+	for name, text := range messageConstants(t) {
+		key, ok := messageKeys[text]
+		if !ok {
+			t.Errorf("constants.%s belum terdaftar di messageKeys", name)
+			continue
+		}
+		if got := catalogs[constants.DefaultLanguage][key]; got != text {
+			t.Errorf("constants.%s dipetakan ke %s = %q, ingin teks konstanta %q", name, key, got, text)
+		}
+		for _, lang := range constants.Languages {
+			if _, ok := catalogs[lang][key]; !ok {
+				t.Errorf("key %s untuk constants.%s tidak ada di katalog %s", key, name, lang)
+			}
+		}
+	}
+}
+
+func TestTranslateConstants(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{message: constants.ErrMsgProductNotFound, want: T(constants.LanguageEnglish, "error.product_not_found", nil)},
+		{message: constants.ErrMsgProductNotFound + ": 42", want: T(constants.LanguageEnglish, "error.product_not_found", nil) + ": 42"},
+		{message: constants.MsgSuccessLogin, want: T(constants.LanguageEnglish, "success.login", nil)},
+		// Teks bebas yang kebetulan sama dengan teks katalog tanpa konstanta tidak diterjemahkan
+		{message: "Operasi berhasil.", want: "Operasi berhasil."},
+		{message: "pesan lain", want: "pesan lain"},
+	}
+	for _, tt := range tests {
+		if got := Translate(constants.LanguageEnglish, tt.message); got != tt.want {
+			t.Errorf("Translate(%q) = %q, ingin %q", tt.message, got, tt.want)
+		}
+	}
+	if got := Translate(constants.DefaultLanguage, constants.ErrMsgProductNotFound); got != constants.ErrMsgProductNotFound {
+		t.Errorf("Translate bahasa bawaan = %q", got)
+	}
+}
@@ -0,0 +1,114 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/mailer"
+	"portolio-backend/internal/model/db"
+)
+
+const (
+	emailOutboxBatchSize = 50
+	emailRetryBaseDelay  = time.Minute
+	emailRetryMaxDelay   = time.Hour
+	// emailStuckAfter adalah batas umur status "sending" sebelum email dianggap tertinggal
+	// oleh instance yang berhenti di tengah pengiriman
+	emailStuckAfter = 10 * time.Minute
+)
+
+// RunEmailOutbox mengirim email dari tabel EmailOutbox secara berkala. Email yang gagal
+// dicoba lagi dengan jeda bertambah hingga maxAttempts, lalu ditandai failed.
+func RunEmailOutbox(dbConn *gorm.DB, m mailer.Mailer, interval time.Duration, maxAttempts int, timeout time.Duration) {
+	if interval <= 0 {
+		interval = constants.DefaultEmailOutboxInterval
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = constants.DefaultEmailMaxAttempts
+	}
+	if timeout <= 0 {
+		timeout = constants.DefaultMailTimeout
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		ProcessEmailOutbox(dbConn, m, maxAttempts, timeout)
+		<-ticker.C
+	}
+}
+
+// ProcessEmailOutbox mengirim satu batch email yang sudah jatuh tempo. Setiap email diklaim
+// dengan mengubah statusnya menjadi sending sehingga beberapa instance API tidak
+// mengirim email yang sama.
+func ProcessEmailOutbox(dbConn *gorm.DB, m mailer.Mailer, maxAttempts int, timeout time.Duration) {
+	now := time.Now()
+
+	if err := dbConn.Model(&db.EmailOutbox{}).
+		Where("status = ? AND updated_at < ?", constants.EmailSending, now.Add(-emailStuckAfter)).
+		Update("status", constants.EmailPending).Error; err != nil {
+		log.Printf("❌ Gagal memulihkan email yang tertunda: %v", err)
+	}
+
+	var emails []db.EmailOutbox
+	if err := dbConn.Where("status = ? AND next_attempt_at <= ?", constants.EmailPending, now).
+		Order("next_attempt_at ASC").Limit(emailOutboxBatchSize).
+		Find(&emails).Error; err != nil {
+		log.Printf("❌ Gagal mengambil antrean email: %v", err)
+		return
+	}
+
+	for _, email := range emails {
+		claim := dbConn.Model(&db.EmailOutbox{}).
+			Where("id = ? AND status = ?", email.ID, constants.EmailPending).
+			Update("status", constants.EmailSending)
+		if claim.Error != nil || claim.RowsAffected == 0 {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := m.Send(ctx, mailer.Message{
+			To:      email.ToAddress,
+			Subject: email.Subject,
+			HTML:    email.HTMLBody,
+			Text:    email.TextBody,
+		})
+		cancel()
+
+		attempts := email.Attempts + 1
+		updates := map[string]interface{}{"attempts": attempts}
+		switch {
+		case err == nil:
+			sentAt := time.Now()
+			updates["status"] = constants.EmailSent
+			updates["sent_at"] = &sentAt
+			updates["last_error"] = ""
+		case attempts >= maxAttempts:
+			updates["status"] = constants.EmailFailed
+			updates["last_error"] = err.Error()
+			log.Printf("❌ Email #%d ke %s gagal dikirim setelah %d percobaan: %v", email.ID, email.ToAddress, attempts, err)
+		default:
+			updates["status"] = constants.EmailPending
+			updates["last_error"] = err.Error()
+			updates["next_attempt_at"] = time.Now().Add(emailRetryDelay(attempts))
+		}
+
+		if err := dbConn.Model(&db.EmailOutbox{}).Where("id = ?", email.ID).Updates(updates).Error; err != nil {
+			log.Printf("❌ Gagal memperbarui status email #%d: %v", email.ID, err)
+		}
+	}
+}
+
+// emailRetryDelay menggandakan jeda setiap percobaan: 1m, 2m, 4m, ... maksimal 1 jam
+func emailRetryDelay(attempts int) time.Duration {
+	delay := emailRetryBaseDelay
+	for i := 1; i < attempts && delay < emailRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, emailRetryMaxDelay)
+}
@@ -0,0 +1,110 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/events"
+	"portolio-backend/internal/model/db"
+)
+
+const (
+	eventRelayBatchSize = 100
+	eventHandlerTimeout = 30 * time.Second
+	eventRetryBaseDelay = 5 * time.Second
+	eventRetryMaxDelay  = time.Hour
+	eventStuckAfter     = 10 * time.Minute
+)
+
+// RunEventRelay meneruskan event dari outbox ke subscriber secara berkala. Event yang
+// subscribernya gagal dicoba lagi dengan jeda bertambah hingga maxAttempts, lalu ditandai
+// failed dan dapat dikirim ulang admin.
+func RunEventRelay(dbConn *gorm.DB, interval time.Duration, maxAttempts int) {
+	if interval <= 0 {
+		interval = constants.DefaultEventRelayInterval
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = constants.DefaultEventMaxAttempts
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		RelayOutboxEvents(dbConn, maxAttempts)
+		<-ticker.C
+	}
+}
+
+// RelayOutboxEvents memproses satu batch event yang sudah jatuh tempo sesuai urutan
+// penulisan. Setiap event diklaim dengan status processing agar tidak diproses dua
+// instance API sekaligus.
+func RelayOutboxEvents(dbConn *gorm.DB, maxAttempts int) {
+	now := time.Now()
+
+	if err := dbConn.Model(&db.OutboxEvent{}).
+		Where("status = ? AND updated_at < ?", constants.OutboxProcessing, now.Add(-eventStuckAfter)).
+		Update("status", constants.OutboxPending).Error; err != nil {
+		log.Printf("❌ Gagal memulihkan event outbox yang tertunda: %v", err)
+	}
+
+	var outboxEvents []db.OutboxEvent
+	if err := dbConn.Where("status = ? AND next_attempt_at <= ?", constants.OutboxPending, now).
+		Order("id ASC").Limit(eventRelayBatchSize).
+		Find(&outboxEvents).Error; err != nil {
+		log.Printf("❌ Gagal mengambil event outbox: %v", err)
+		return
+	}
+
+	for _, outbox := range outboxEvents {
+		claim := dbConn.Model(&db.OutboxEvent{}).
+			Where("id = ? AND status = ?", outbox.ID, constants.OutboxPending).
+			Update("status", constants.OutboxProcessing)
+		if claim.Error != nil || claim.RowsAffected == 0 {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), eventHandlerTimeout)
+		completed, err := events.Dispatch(ctx, outbox)
+		cancel()
+
+		attempts := outbox.Attempts + 1
+		updates := map[string]interface{}{
+			"attempts":              attempts,
+			"completed_subscribers": strings.Join(completed, ","),
+		}
+		switch {
+		case err == nil:
+			publishedAt := time.Now()
+			updates["status"] = constants.OutboxPublished
+			updates["published_at"] = &publishedAt
+			updates["last_error"] = ""
+		case attempts >= maxAttempts:
+			updates["status"] = constants.OutboxFailed
+			updates["last_error"] = err.Error()
+			log.Printf("❌ Event #%d (%s) gagal diteruskan setelah %d percobaan: %v", outbox.ID, outbox.EventType, attempts, err)
+		default:
+			updates["status"] = constants.OutboxPending
+			updates["last_error"] = err.Error()
+			updates["next_attempt_at"] = time.Now().Add(eventRetryDelay(attempts))
+		}
+
+		if err := dbConn.Model(&db.OutboxEvent{}).Where("id = ?", outbox.ID).Updates(updates).Error; err != nil {
+			log.Printf("❌ Gagal memperbarui status event #%d: %v", outbox.ID, err)
+		}
+	}
+}
+
+// eventRetryDelay menggandakan jeda setiap percobaan: 5s, 10s, 20s, ... maksimal 1 jam
+func eventRetryDelay(attempts int) time.Duration {
+	delay := eventRetryBaseDelay
+	for i := 1; i < attempts && delay < eventRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, eventRetryMaxDelay)
+}
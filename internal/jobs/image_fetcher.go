@@ -0,0 +1,133 @@
+package jobs
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/service"
+)
+
+// imageFetchStuckAfter adalah batas umur klaim processing sebelum gambar dianggap
+// tertinggal oleh instance yang berhenti di tengah unduhan
+const imageFetchStuckAfter = 10 * time.Minute
+
+var imageFetchWake = make(chan struct{}, 1)
+
+// WakeImageFetcher membangunkan RunImageFetcher tanpa menunggu interval berikutnya,
+// dipanggil setelah transaksi yang membuat gambar pending berhasil di-commit.
+func WakeImageFetcher() {
+	select {
+	case imageFetchWake <- struct{}{}:
+	default:
+	}
+}
+
+// RunImageFetcher mengunduh gambar produk dari images_links di latar belakang
+// sehingga pembuatan produk tidak menunggu host yang lambat.
+func RunImageFetcher(dbConn *gorm.DB, interval time.Duration, workers int) {
+	if interval <= 0 {
+		interval = constants.DefaultImageFetchInterval
+	}
+	if workers <= 0 {
+		workers = constants.DefaultImageFetchWorkers
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		FetchPendingImages(dbConn, workers)
+		select {
+		case <-ticker.C:
+		case <-imageFetchWake:
+		}
+	}
+}
+
+// FetchPendingImages memproses gambar berstatus pending. Setiap gambar diklaim dengan
+// mengubah statusnya menjadi processing sehingga aman bila beberapa instance berjalan.
+// Hanya klaim yang lebih tua dari imageFetchStuckAfter yang dikembalikan ke antrean, agar
+// instance yang baru dinyalakan tidak mengambil alih unduhan instance lain.
+func FetchPendingImages(dbConn *gorm.DB, workers int) {
+	if err := dbConn.Model(&db.ProductImage{}).
+		Where("status = ? AND (claimed_at IS NULL OR claimed_at < ?)", constants.ProductImageProcessing, time.Now().Add(-imageFetchStuckAfter)).
+		Update("status", constants.ProductImagePending).Error; err != nil {
+		log.Printf("❌ Gagal mengembalikan gambar yang tertunda ke antrean: %v", err)
+	}
+
+	var pendingIDs []uint
+	if err := dbConn.Model(&db.ProductImage{}).
+		Where("status = ?", constants.ProductImagePending).
+		Order("id ASC").Limit(100).
+		Pluck("id", &pendingIDs).Error; err != nil {
+		log.Printf("❌ Gagal mengambil gambar yang menunggu diunduh: %v", err)
+		return
+	}
+
+	ids := make(chan uint)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range ids {
+				fetchProductImage(dbConn, id)
+			}
+		}()
+	}
+	for _, id := range pendingIDs {
+		ids <- id
+	}
+	close(ids)
+	wg.Wait()
+}
+
+func fetchProductImage(dbConn *gorm.DB, id uint) {
+	result := dbConn.Model(&db.ProductImage{}).
+		Where("id = ? AND status = ?", id, constants.ProductImagePending).
+		Updates(map[string]interface{}{
+			"status":     constants.ProductImageProcessing,
+			"claimed_at": time.Now(),
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return
+	}
+
+	var image db.ProductImage
+	if err := dbConn.First(&image, id).Error; err != nil {
+		return
+	}
+
+	fetched, err := service.DownloadProductImage(image.SourceURL)
+	if err != nil {
+		log.Printf("❌ Gagal mengunduh gambar produk #%d dari %s: %v", image.ID, image.SourceURL, err)
+		dbConn.Model(&db.ProductImage{}).
+			Where("id = ? AND status = ?", id, constants.ProductImageProcessing).
+			Updates(map[string]interface{}{
+				"status":      constants.ProductImageFailed,
+				"fetch_error": err.Error(),
+			})
+		return
+	}
+
+	result = dbConn.Model(&db.ProductImage{}).
+		Where("id = ? AND status = ?", id, constants.ProductImageProcessing).
+		Updates(map[string]interface{}{
+			"status":        constants.ProductImageReady,
+			"image_url":     fetched.ImageURL,
+			"medium_url":    fetched.MediumURL,
+			"thumbnail_url": fetched.ThumbnailURL,
+			"fetch_error":   "",
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		// Gambar dihapus penjual selama diunduh, file hasil unduhan tidak lagi dipakai.
+		if err := service.RemoveProductImageFiles(dbConn, fetched); err != nil {
+			log.Printf("❌ Gagal menghapus file gambar produk %s: %v", fetched.ImageURL, err)
+		}
+	}
+}
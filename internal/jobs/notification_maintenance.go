@@ -0,0 +1,136 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/notify"
+)
+
+// RunNotificationMaintenance mengirim ringkasan notifikasi yang jatuh tempo setiap interval
+// dan menjalankan kebijakan retensi paling sering sekali per jam. retentionDays 0 menonaktifkan
+// retensi.
+func RunNotificationMaintenance(dbConn *gorm.DB, interval time.Duration, retentionDays int, mode constants.NotificationRetentionMode) {
+	if interval <= 0 {
+		interval = constants.DefaultNotificationMaintenanceInterval
+	}
+	if mode != constants.RetentionDelete {
+		mode = constants.RetentionArchive
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastRetention time.Time
+	for {
+		now := time.Now()
+		FlushNotificationDigests(dbConn, now)
+		if retentionDays > 0 && now.Sub(lastRetention) >= time.Hour {
+			ApplyNotificationRetention(dbConn, now.AddDate(0, 0, -retentionDays), mode)
+			lastRetention = now
+		}
+		<-ticker.C
+	}
+}
+
+// FlushNotificationDigests menggabungkan item ringkasan yang sudah mencapai DeliverAt per
+// pengguna, tipe, dan frekuensi. Setiap kelompok diproses dalam transaksi sendiri dengan
+// SKIP LOCKED sehingga satu item tidak terkirim dua kali bila beberapa instance berjalan.
+func FlushNotificationDigests(dbConn *gorm.DB, now time.Time) {
+	type digestGroup struct {
+		UserID    uint
+		Type      constants.NotificationType
+		Frequency constants.DigestFrequency
+	}
+	var groups []digestGroup
+	if err := dbConn.Model(&db.NotificationDigestItem{}).
+		Select("user_id, type, frequency").
+		Where("deliver_at <= ?", now).
+		Group("user_id, type, frequency").
+		Scan(&groups).Error; err != nil {
+		log.Printf("❌ Gagal mengambil ringkasan notifikasi yang jatuh tempo: %v", err)
+		return
+	}
+
+	for _, group := range groups {
+		err := notify.Transaction(dbConn, func(tx *gorm.DB) error {
+			var items []db.NotificationDigestItem
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("user_id = ? AND type = ? AND frequency = ? AND deliver_at <= ?", group.UserID, group.Type, group.Frequency, now).
+				Order("id ASC").
+				Find(&items).Error; err != nil || len(items) == 0 {
+				return err
+			}
+			if err := notify.SendDigest(tx, group.UserID, group.Type, group.Frequency, items); err != nil {
+				return err
+			}
+			return tx.Unscoped().Delete(&items).Error
+		})
+		if err != nil {
+			log.Printf("❌ Gagal mengirim ringkasan %s %s untuk user %d: %v", group.Frequency, group.Type, group.UserID, err)
+		}
+	}
+}
+
+// ApplyNotificationRetention memindahkan (archive) atau menghapus (delete) notifikasi yang
+// sudah dibaca dan dibuat sebelum cutoff, per batch agar tabel tidak terkunci lama.
+// Notifikasi yang sudah dihapus pengguna sebelum cutoff selalu dihapus permanen.
+func ApplyNotificationRetention(dbConn *gorm.DB, cutoff time.Time, mode constants.NotificationRetentionMode) {
+	const batchSize = 1000
+
+	for {
+		var processed int
+		err := dbConn.Transaction(func(tx *gorm.DB) error {
+			var notifications []db.Notification
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("is_read = ? AND created_at < ?", true, cutoff).
+				Order("id ASC").Limit(batchSize).
+				Find(&notifications).Error; err != nil || len(notifications) == 0 {
+				return err
+			}
+
+			if mode == constants.RetentionArchive {
+				archivedAt := time.Now()
+				archives := make([]db.NotificationArchive, 0, len(notifications))
+				for _, notification := range notifications {
+					archives = append(archives, db.NotificationArchive{
+						ID:            notification.ID,
+						UserID:        notification.UserID,
+						Type:          notification.Type,
+						Message:       notification.Message,
+						MessageKey:    notification.MessageKey,
+						MessageParams: notification.MessageParams,
+						RelatedID:     notification.RelatedID,
+						IsRead:        notification.IsRead,
+						CreatedAt:     notification.CreatedAt,
+						ArchivedAt:    archivedAt,
+					})
+				}
+				if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&archives).Error; err != nil {
+					return err
+				}
+			}
+
+			processed = len(notifications)
+			return tx.Unscoped().Delete(&notifications).Error
+		})
+		if err != nil {
+			log.Printf("❌ Gagal menerapkan retensi notifikasi (%s): %v", mode, err)
+			return
+		}
+		if processed < batchSize {
+			break
+		}
+	}
+
+	if err := dbConn.Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Delete(&db.Notification{}).Error; err != nil {
+		log.Printf("❌ Gagal menghapus permanen notifikasi yang sudah dihapus: %v", err)
+	}
+}
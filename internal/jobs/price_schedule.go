@@ -0,0 +1,88 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/service"
+)
+
+// RunPriceScheduler menjalankan ApplyDuePriceSchedules secara berkala.
+// Dijalankan sebagai goroutine dari main dan berhenti saat proses berhenti.
+func RunPriceScheduler(dbConn *gorm.DB, interval time.Duration) {
+	if interval <= 0 {
+		interval = constants.DefaultPriceSchedulerInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		ApplyDuePriceSchedules(dbConn, time.Now())
+		<-ticker.C
+	}
+}
+
+// ApplyDuePriceSchedules mengakhiri jadwal yang sudah lewat waktu berakhirnya,
+// lalu mengaktifkan jadwal yang sudah mencapai waktu mulainya.
+// Setiap jadwal diproses dalam transaksi sendiri dengan SKIP LOCKED
+// sehingga aman bila beberapa instance berjalan bersamaan.
+func ApplyDuePriceSchedules(dbConn *gorm.DB, now time.Time) {
+	var expiredIDs []uint
+	if err := dbConn.Model(&db.ProductPriceSchedule{}).
+		Where("status = ? AND end_at IS NOT NULL AND end_at <= ?", constants.PriceScheduleActive, now).
+		Order("end_at ASC").
+		Pluck("id", &expiredIDs).Error; err != nil {
+		log.Printf("❌ Gagal mengambil jadwal harga yang berakhir: %v", err)
+		return
+	}
+	for _, id := range expiredIDs {
+		err := dbConn.Transaction(func(tx *gorm.DB) error {
+			var schedule db.ProductPriceSchedule
+			result := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("id = ? AND status = ?", id, constants.PriceScheduleActive).
+				Limit(1).Find(&schedule)
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			return service.EndPriceSchedule(tx, &schedule, constants.PriceScheduleCompleted, constants.PriceChangeScheduleEnd, nil)
+		})
+		if err != nil {
+			log.Printf("❌ Gagal mengakhiri jadwal harga #%d: %v", id, err)
+		}
+	}
+
+	var dueIDs []uint
+	if err := dbConn.Model(&db.ProductPriceSchedule{}).
+		Where("status = ? AND start_at <= ?", constants.PriceScheduleScheduled, now).
+		Order("start_at ASC").
+		Pluck("id", &dueIDs).Error; err != nil {
+		log.Printf("❌ Gagal mengambil jadwal harga yang jatuh tempo: %v", err)
+		return
+	}
+	for _, id := range dueIDs {
+		err := dbConn.Transaction(func(tx *gorm.DB) error {
+			var schedule db.ProductPriceSchedule
+			result := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("id = ? AND status = ?", id, constants.PriceScheduleScheduled).
+				Limit(1).Find(&schedule)
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+
+			// Jadwal yang seluruh rentangnya sudah terlewat (misalnya server mati) tidak diterapkan.
+			if schedule.EndAt != nil && !schedule.EndAt.After(now) {
+				return tx.Model(&schedule).Update("status", constants.PriceScheduleCompleted).Error
+			}
+			return service.StartPriceSchedule(tx, &schedule)
+		})
+		if err != nil {
+			log.Printf("❌ Gagal menerapkan jadwal harga #%d: %v", id, err)
+		}
+	}
+}
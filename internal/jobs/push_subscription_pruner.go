@@ -0,0 +1,37 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+)
+
+// RunPushSubscriptionPruner menghapus langganan web push yang tidak berlaku secara berkala
+func RunPushSubscriptionPruner(dbConn *gorm.DB, interval time.Duration) {
+	if interval <= 0 {
+		interval = constants.DefaultPushPruneInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		PrunePushSubscriptions(dbConn, time.Now())
+		<-ticker.C
+	}
+}
+
+// PrunePushSubscriptions menghapus permanen langganan yang melewati expirationTime dari
+// browser atau gagal dikirimi berturut-turut sebanyak constants.DefaultWebPushMaxFailures.
+// Langganan yang dibalas 404/410 oleh layanan push sudah dihapus saat pengiriman.
+func PrunePushSubscriptions(dbConn *gorm.DB, now time.Time) {
+	if err := dbConn.Unscoped().
+		Where("(expires_at IS NOT NULL AND expires_at <= ?) OR failure_count >= ?", now, constants.DefaultWebPushMaxFailures).
+		Delete(&db.PushSubscription{}).Error; err != nil {
+		log.Printf("❌ Gagal menghapus langganan web push yang kedaluwarsa: %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/service"
+)
+
+// RunReservationSweeper melepas reservasi stok yang sudah kedaluwarsa secara berkala
+func RunReservationSweeper(dbConn *gorm.DB, interval time.Duration) {
+	if interval <= 0 {
+		interval = constants.DefaultReservationSweepInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		ReleaseExpiredReservations(dbConn, time.Now())
+		<-ticker.C
+	}
+}
+
+// ReleaseExpiredReservations mengembalikan stok yang ditahan reservasi yang melewati ExpiresAt.
+// Reservasi yang produk atau barisnya sedang dikunci oleh proses pembelian dilewati dan
+// diperiksa di putaran berikutnya.
+func ReleaseExpiredReservations(dbConn *gorm.DB, now time.Time) {
+	var expiredIDs []uint
+	if err := dbConn.Model(&db.StockReservation{}).
+		Where("status = ? AND expires_at <= ?", constants.ReservationActive, now).
+		Pluck("id", &expiredIDs).Error; err != nil {
+		log.Printf("❌ Gagal mengambil reservasi stok kedaluwarsa: %v", err)
+		return
+	}
+
+	for _, id := range expiredIDs {
+		err := dbConn.Transaction(func(tx *gorm.DB) error {
+			reservation, err := service.LockReservation(tx, id, 0, true)
+			if err != nil || reservation == nil {
+				return err
+			}
+			return service.ReleaseReservation(tx, reservation, constants.ReservationExpired)
+		})
+		if err != nil {
+			log.Printf("❌ Gagal melepas reservasi stok #%d: %v", id, err)
+		}
+	}
+}
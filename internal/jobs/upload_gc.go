@@ -0,0 +1,83 @@
+package jobs
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/service"
+	"portolio-backend/internal/util"
+)
+
+// collectedUploadPurposes adalah tujuan unggahan yang setiap pemakaiannya dicatat lewat
+// service.ClaimUpload. Unggahan general dan product dapat dirujuk dari kolom lain, misalnya
+// gambar produk atau teks bebas, tanpa referenced_by sehingga tidak ikut dikumpulkan.
+var collectedUploadPurposes = []constants.UploadPurpose{constants.UploadPurposeChat, constants.UploadPurposeSupport}
+
+// RunUploadGC menghapus unggahan yang tidak pernah dipakai pesan secara berkala
+func RunUploadGC(dbConn *gorm.DB, interval, gracePeriod time.Duration) {
+	if interval <= 0 {
+		interval = constants.DefaultUploadGCInterval
+	}
+	if gracePeriod <= 0 {
+		gracePeriod = constants.DefaultUploadGCGracePeriod
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		CollectOrphanedUploads(dbConn, time.Now().Add(-gracePeriod))
+		<-ticker.C
+	}
+}
+
+// CollectOrphanedUploads menghapus file dan catatan Upload chat dan dukungan yang belum
+// direferensikan dan dibuat sebelum cutoff, lalu mengembalikan kuota penyimpanan pemiliknya. Catatan dihapus
+// lebih dulu dengan syarat referenced_by masih kosong, sehingga file yang diklaim pesan
+// di saat bersamaan tidak ikut terhapus.
+func CollectOrphanedUploads(dbConn *gorm.DB, cutoff time.Time) {
+	orphans, err := orphanedUploads(dbConn, cutoff)
+	if err != nil {
+		log.Printf("❌ Gagal mengambil unggahan yang tidak terpakai: %v", err)
+		return
+	}
+
+	for _, upload := range orphans {
+		deleted := false
+		err := dbConn.Transaction(func(tx *gorm.DB) error {
+			result := tx.Unscoped().
+				Where("id = ? AND (referenced_by = '' OR referenced_by IS NULL)", upload.ID).
+				Delete(&db.Upload{})
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			deleted = true
+			return service.ReleaseStorage(tx, upload.UserID, upload.FileSize)
+		})
+		if err != nil {
+			log.Printf("❌ Gagal menghapus catatan unggahan #%d: %v", upload.ID, err)
+			continue
+		}
+		if !deleted {
+			continue
+		}
+		if err := util.DeleteMedia(strings.TrimPrefix(upload.FileURL, "/")); err != nil {
+			log.Printf("❌ Gagal menghapus file unggahan %s: %v", upload.FileURL, err)
+		}
+	}
+}
+
+// orphanedUploads memuat paling banyak 500 unggahan dari collectedUploadPurposes yang belum
+// direferensikan dan dibuat sebelum cutoff
+func orphanedUploads(dbConn *gorm.DB, cutoff time.Time) ([]db.Upload, error) {
+	var orphans []db.Upload
+	err := dbConn.Where("(referenced_by = '' OR referenced_by IS NULL) AND purpose IN ? AND created_at <= ?", collectedUploadPurposes, cutoff).
+		Order("id ASC").Limit(500).
+		Find(&orphans).Error
+	return orphans, err
+}
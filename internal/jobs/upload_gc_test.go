@@ -0,0 +1,44 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+)
+
+func TestOrphanedUploadsOnlyCollectsTrackedPurposes(t *testing.T) {
+	dbConn := openTestDB(t, &db.Upload{})
+	now := time.Now()
+	old := now.Add(-48 * time.Hour)
+
+	uploads := []db.Upload{
+		{Purpose: constants.UploadPurposeChat, FileURL: "/media/chat/lama.jpg"},
+		{Purpose: constants.UploadPurposeSupport, FileURL: "/media/support/lama.pdf"},
+		{Purpose: constants.UploadPurposeChat, FileURL: "/media/chat/dipakai.jpg", ReferencedBy: "chat_message:1"},
+		// Unggahan general dan product dapat dipakai tanpa ClaimUpload sehingga tidak boleh dihapus
+		{Purpose: constants.UploadPurposeGeneral, FileURL: "/media/general/lama.jpg"},
+		{Purpose: constants.UploadPurposeProduct, FileURL: "/media/products/lama.jpg"},
+	}
+	for i := range uploads {
+		uploads[i].UserID, uploads[i].FileSize = 1, 100
+		uploads[i].CreatedAt = old
+	}
+	uploads = append(uploads, db.Upload{UserID: 1, Purpose: constants.UploadPurposeChat, FileURL: "/media/chat/baru.jpg", FileSize: 100})
+	if err := dbConn.Create(&uploads).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	orphans, err := orphanedUploads(dbConn, now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("orphanedUploads: %v", err)
+	}
+	var got []string
+	for _, upload := range orphans {
+		got = append(got, upload.FileURL)
+	}
+	if len(got) != 2 || got[0] != "/media/chat/lama.jpg" || got[1] != "/media/support/lama.pdf" {
+		t.Fatalf("unggahan yang dikumpulkan = %v, ingin hanya unggahan chat dan dukungan lama yang tidak dipakai", got)
+	}
+}
@@ -0,0 +1,90 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/webhook"
+)
+
+const (
+	webhookBatchSize      = 50
+	webhookRetryBaseDelay = 10 * time.Second
+	webhookRetryMaxDelay  = 6 * time.Hour
+	webhookStuckAfter     = 10 * time.Minute
+)
+
+// RunWebhookDispatcher mengirim WebhookDelivery yang tertunda secara berkala. Pengiriman
+// yang gagal dicoba lagi dengan jeda bertambah hingga maxAttempts, lalu ditandai failed.
+func RunWebhookDispatcher(dbConn *gorm.DB, client *webhook.Client, interval time.Duration, maxAttempts int) {
+	if interval <= 0 {
+		interval = constants.DefaultWebhookInterval
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = constants.DefaultWebhookMaxAttempts
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		ProcessWebhookDeliveries(dbConn, client, maxAttempts)
+		<-ticker.C
+	}
+}
+
+// ProcessWebhookDeliveries mengirim satu batch pengiriman yang sudah jatuh tempo. Setiap
+// pengiriman diklaim dengan status sending agar tidak dikirim dua instance sekaligus.
+func ProcessWebhookDeliveries(dbConn *gorm.DB, client *webhook.Client, maxAttempts int) {
+	now := time.Now()
+
+	if err := dbConn.Model(&db.WebhookDelivery{}).
+		Where("status = ? AND updated_at < ?", constants.WebhookDeliverySending, now.Add(-webhookStuckAfter)).
+		Update("status", constants.WebhookDeliveryPending).Error; err != nil {
+		log.Printf("❌ Gagal memulihkan pengiriman webhook yang tertunda: %v", err)
+	}
+
+	var deliveries []db.WebhookDelivery
+	if err := dbConn.Preload("Webhook", func(tx *gorm.DB) *gorm.DB { return tx.Unscoped() }).
+		Where("status = ? AND next_attempt_at <= ?", constants.WebhookDeliveryPending, now).
+		Order("next_attempt_at ASC").Limit(webhookBatchSize).
+		Find(&deliveries).Error; err != nil {
+		log.Printf("❌ Gagal mengambil antrean webhook: %v", err)
+		return
+	}
+
+	for _, delivery := range deliveries {
+		claim := dbConn.Model(&db.WebhookDelivery{}).
+			Where("id = ? AND status = ?", delivery.ID, constants.WebhookDeliveryPending).
+			Update("status", constants.WebhookDeliverySending)
+		if claim.Error != nil || claim.RowsAffected == 0 {
+			continue
+		}
+
+		// Webhook yang sudah dihapus atau dinonaktifkan tidak dikirimi lagi
+		if delivery.Webhook.DeletedAt.Valid || !delivery.Webhook.IsActive {
+			dbConn.Model(&db.WebhookDelivery{}).Where("id = ?", delivery.ID).Updates(map[string]interface{}{
+				"status":     constants.WebhookDeliveryFailed,
+				"last_error": constants.ErrMsgWebhookNotFound,
+			})
+			continue
+		}
+
+		delivery.Attempts++
+		webhook.Attempt(context.Background(), dbConn, client, delivery.Webhook, delivery, webhookRetryDelay(delivery.Attempts), delivery.Attempts >= maxAttempts)
+	}
+}
+
+// webhookRetryDelay menggandakan jeda setiap percobaan: 10s, 20s, 40s, ... maksimal 6 jam
+func webhookRetryDelay(attempts int) time.Duration {
+	delay := webhookRetryBaseDelay
+	for i := 1; i < attempts && delay < webhookRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, webhookRetryMaxDelay)
+}
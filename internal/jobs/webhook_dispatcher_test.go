@@ -0,0 +1,200 @@
+package jobs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/webhook"
+)
+
+func openTestDB(t *testing.T, models ...interface{}) *gorm.DB {
+	t.Helper()
+	dbConn, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger:                                   logger.Default.LogMode(logger.Silent),
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	if err != nil {
+		t.Fatalf("membuka database: %v", err)
+	}
+	if err := dbConn.AutoMigrate(models...); err != nil {
+		t.Fatalf("migrasi: %v", err)
+	}
+	return dbConn
+}
+
+// newWebhookDelivery membuat webhook ke url beserta satu pengiriman yang langsung jatuh tempo
+func newWebhookDelivery(t *testing.T, dbConn *gorm.DB, url string) db.WebhookDelivery {
+	t.Helper()
+	hook := db.Webhook{UserID: 1, URL: url, Secret: "whsec_test", EventTypes: string(constants.EventPurchaseCreated), IsActive: true}
+	if err := dbConn.Create(&hook).Error; err != nil {
+		t.Fatal(err)
+	}
+	delivery := db.WebhookDelivery{
+		WebhookID:     hook.ID,
+		EventType:     constants.EventPurchaseCreated,
+		Payload:       db.JSONB{"transaction_id": 123},
+		Status:        constants.WebhookDeliveryPending,
+		NextAttemptAt: time.Now().Add(-time.Second),
+	}
+	if err := dbConn.Create(&delivery).Error; err != nil {
+		t.Fatal(err)
+	}
+	return delivery
+}
+
+// makeDue memajukan jadwal percobaan berikutnya agar batch selanjutnya langsung mengirim
+func makeDue(t *testing.T, dbConn *gorm.DB, id uint) {
+	t.Helper()
+	if err := dbConn.Model(&db.WebhookDelivery{}).Where("id = ?", id).
+		Update("next_attempt_at", time.Now().Add(-time.Second)).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func loadDelivery(t *testing.T, dbConn *gorm.DB, id uint) db.WebhookDelivery {
+	t.Helper()
+	var delivery db.WebhookDelivery
+	if err := dbConn.First(&delivery, id).Error; err != nil {
+		t.Fatal(err)
+	}
+	return delivery
+}
+
+func assertNextAttempt(t *testing.T, delivery db.WebhookDelivery, before time.Time, delay time.Duration) {
+	t.Helper()
+	earliest, latest := before.Add(delay), time.Now().Add(delay)
+	if delivery.NextAttemptAt.Before(earliest.Add(-time.Second)) || delivery.NextAttemptAt.After(latest.Add(time.Second)) {
+		t.Errorf("next_attempt_at = %v, ingin sekitar %v setelah percobaan", delivery.NextAttemptAt, delay)
+	}
+}
+
+func TestProcessWebhookDeliveriesRetriesServerErrors(t *testing.T) {
+	dbConn := openTestDB(t, &db.Webhook{}, &db.WebhookDelivery{})
+
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			http.Error(w, "sedang gangguan", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer receiver.Close()
+
+	client := webhook.NewClient(webhook.Config{AllowPrivate: true, Timeout: time.Second})
+	delivery := newWebhookDelivery(t, dbConn, receiver.URL)
+
+	for attempt, delay := range []time.Duration{10 * time.Second, 20 * time.Second} {
+		before := time.Now()
+		ProcessWebhookDeliveries(dbConn, client, 5)
+
+		got := loadDelivery(t, dbConn, delivery.ID)
+		if got.Status != constants.WebhookDeliveryPending || got.Attempts != attempt+1 {
+			t.Fatalf("percobaan %d: status = %s, attempts = %d", attempt+1, got.Status, got.Attempts)
+		}
+		if got.ResponseCode != http.StatusServiceUnavailable || got.ResponseBody != "sedang gangguan\n" || got.LastError == "" {
+			t.Fatalf("percobaan %d: response_code = %d, response_body = %q, last_error = %q", attempt+1, got.ResponseCode, got.ResponseBody, got.LastError)
+		}
+		assertNextAttempt(t, got, before, delay)
+
+		// Pengiriman belum jatuh tempo tidak dikirim ulang
+		ProcessWebhookDeliveries(dbConn, client, 5)
+		if n := calls.Load(); n != int32(attempt+1) {
+			t.Fatalf("receiver dipanggil %d kali sebelum jatuh tempo, ingin %d", n, attempt+1)
+		}
+		makeDue(t, dbConn, delivery.ID)
+	}
+
+	ProcessWebhookDeliveries(dbConn, client, 5)
+	got := loadDelivery(t, dbConn, delivery.ID)
+	if got.Status != constants.WebhookDeliverySuccess || got.Attempts != 3 || got.ResponseCode != http.StatusOK {
+		t.Fatalf("status = %s, attempts = %d, response_code = %d", got.Status, got.Attempts, got.ResponseCode)
+	}
+	if got.DeliveredAt == nil || got.LastError != "" || got.ResponseBody != "ok" {
+		t.Fatalf("delivered_at = %v, last_error = %q, response_body = %q", got.DeliveredAt, got.LastError, got.ResponseBody)
+	}
+}
+
+func TestProcessWebhookDeliveriesRetriesTimeout(t *testing.T) {
+	dbConn := openTestDB(t, &db.Webhook{}, &db.WebhookDelivery{})
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer receiver.Close()
+
+	client := webhook.NewClient(webhook.Config{AllowPrivate: true, Timeout: 50 * time.Millisecond})
+	delivery := newWebhookDelivery(t, dbConn, receiver.URL)
+
+	before := time.Now()
+	ProcessWebhookDeliveries(dbConn, client, 5)
+
+	got := loadDelivery(t, dbConn, delivery.ID)
+	if got.Status != constants.WebhookDeliveryPending || got.Attempts != 1 {
+		t.Fatalf("status = %s, attempts = %d", got.Status, got.Attempts)
+	}
+	if got.ResponseCode != 0 || got.LastError == "" || got.DeliveredAt != nil {
+		t.Fatalf("response_code = %d, last_error = %q, delivered_at = %v", got.ResponseCode, got.LastError, got.DeliveredAt)
+	}
+	assertNextAttempt(t, got, before, 10*time.Second)
+}
+
+func TestProcessWebhookDeliveriesFailsAfterMaxAttempts(t *testing.T) {
+	dbConn := openTestDB(t, &db.Webhook{}, &db.WebhookDelivery{})
+
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	client := webhook.NewClient(webhook.Config{AllowPrivate: true, Timeout: time.Second})
+	delivery := newWebhookDelivery(t, dbConn, receiver.URL)
+
+	for range 3 {
+		ProcessWebhookDeliveries(dbConn, client, 2)
+		makeDue(t, dbConn, delivery.ID)
+	}
+
+	got := loadDelivery(t, dbConn, delivery.ID)
+	if got.Status != constants.WebhookDeliveryFailed || got.Attempts != 2 || got.ResponseCode != http.StatusInternalServerError {
+		t.Fatalf("status = %s, attempts = %d, response_code = %d", got.Status, got.Attempts, got.ResponseCode)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("receiver dipanggil %d kali, ingin 2", n)
+	}
+}
+
+func TestWebhookRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{3, 40 * time.Second},
+		{11, 10240 * time.Second},
+		{12, 20480 * time.Second},
+		{13, 6 * time.Hour},
+		{100, 6 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := webhookRetryDelay(tt.attempts); got != tt.want {
+			t.Errorf("webhookRetryDelay(%d) = %v, ingin %v", tt.attempts, got, tt.want)
+		}
+	}
+}
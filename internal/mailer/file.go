@@ -0,0 +1,47 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+var unsafeFilenameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// FileMailer menulis setiap email sebagai file .eml di dir, dipakai saat pengembangan agar
+// email dapat diperiksa tanpa server SMTP. File dapat dibuka langsung dengan klien email.
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+func NewFileMailer(dir, from string) *FileMailer {
+	if dir == "" {
+		dir = "tmp/mail"
+	}
+	return &FileMailer{dir: dir, from: from}
+}
+
+func (m *FileMailer) Name() string {
+	return "file"
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	raw, err := buildMIME(m.from, msg)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return err
+	}
+
+	to, err := envelopeAddress(msg.To)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102T150405.000000000"), unsafeFilenameChars.ReplaceAllString(to, "_"))
+	return os.WriteFile(filepath.Join(m.dir, name), raw, 0644)
+}
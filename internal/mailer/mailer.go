@@ -0,0 +1,148 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// Message adalah satu email yang siap dikirim
+type Message struct {
+	To      string
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Mailer mengirim email transaksional
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+	Name() string
+}
+
+// Config berisi pengaturan pengiriman email, dibaca dari env di main
+type Config struct {
+	Driver string
+	From   string
+
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	// SMTPTLS bernilai "none", "starttls", atau "tls" (TLS langsung, biasanya port 465)
+	SMTPTLS string
+	Timeout time.Duration
+
+	// DevDir adalah direktori tujuan driver file
+	DevDir string
+}
+
+// New membuat mailer sesuai cfg.Driver: "smtp" untuk server SMTP (termasuk MailHog),
+// "file" untuk menulis email ke disk saat pengembangan, selain itu NoopMailer.
+func New(cfg Config) Mailer {
+	switch strings.ToLower(cfg.Driver) {
+	case "smtp":
+		return NewSMTPMailer(cfg)
+	case "file", "dev":
+		return NewFileMailer(cfg.DevDir, cfg.From)
+	default:
+		return NoopMailer{}
+	}
+}
+
+// NoopMailer membuang semua email, dipakai saat pengiriman email tidak dikonfigurasi
+type NoopMailer struct{}
+
+func (NoopMailer) Send(ctx context.Context, msg Message) error {
+	return nil
+}
+
+func (NoopMailer) Name() string {
+	return "noop"
+}
+
+// buildMIME menyusun email multipart/alternative berisi versi teks dan HTML
+func buildMIME(from string, msg Message) ([]byte, error) {
+	if _, err := mail.ParseAddress(msg.To); err != nil {
+		return nil, fmt.Errorf("alamat tujuan tidak valid: %w", err)
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=UTF-8", msg.Text},
+		{"text/html; charset=UTF-8", msg.HTML},
+	}
+	for _, part := range parts {
+		if part.content == "" {
+			continue
+		}
+		w, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	headers := [][2]string{
+		{"From", from},
+		{"To", msg.To},
+		{"Subject", mime.QEncoding.Encode("UTF-8", msg.Subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", messageID(from)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "multipart/alternative; boundary=" + writer.Boundary()},
+	}
+	for _, header := range headers {
+		fmt.Fprintf(&buf, "%s: %s\r\n", header[0], header[1])
+	}
+	buf.WriteString("\r\n")
+	buf.Write(body.Bytes())
+	return buf.Bytes(), nil
+}
+
+func messageID(from string) string {
+	domain := "localhost"
+	if addr, err := mail.ParseAddress(from); err == nil {
+		if at := strings.LastIndex(addr.Address, "@"); at >= 0 {
+			domain = addr.Address[at+1:]
+		}
+	}
+	random := make([]byte, 12)
+	rand.Read(random)
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(random), domain)
+}
+
+// envelopeAddress mengambil alamat email saja dari "Nama <alamat>"
+func envelopeAddress(address string) (string, error) {
+	addr, err := mail.ParseAddress(address)
+	if err != nil {
+		return "", err
+	}
+	return addr.Address, nil
+}
@@ -0,0 +1,115 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPMailer mengirim email melalui server SMTP. Untuk pengembangan dapat diarahkan ke
+// MailHog (MAIL_SMTP_HOST=localhost, MAIL_SMTP_PORT=1025, MAIL_SMTP_TLS=none).
+type SMTPMailer struct {
+	addr     string
+	host     string
+	from     string
+	username string
+	password string
+	tlsMode  string
+	timeout  time.Duration
+}
+
+func NewSMTPMailer(cfg Config) *SMTPMailer {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	return &SMTPMailer{
+		addr:     net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(cfg.SMTPPort)),
+		host:     cfg.SMTPHost,
+		from:     cfg.From,
+		username: cfg.SMTPUsername,
+		password: cfg.SMTPPassword,
+		tlsMode:  strings.ToLower(cfg.SMTPTLS),
+		timeout:  timeout,
+	}
+}
+
+func (m *SMTPMailer) Name() string {
+	return "smtp"
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	raw, err := buildMIME(m.from, msg)
+	if err != nil {
+		return err
+	}
+	from, err := envelopeAddress(m.from)
+	if err != nil {
+		return fmt.Errorf("MAIL_FROM tidak valid: %w", err)
+	}
+	to, err := envelopeAddress(msg.To)
+	if err != nil {
+		return err
+	}
+
+	conn, err := m.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("gagal terhubung ke server SMTP %s: %w", m.addr, err)
+	}
+	deadline := time.Now().Add(m.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if m.tlsMode == "starttls" {
+		if err := client.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+			return fmt.Errorf("STARTTLS gagal: %w", err)
+		}
+	}
+	if m.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.username, m.password, m.host)); err != nil {
+			return fmt.Errorf("autentikasi SMTP gagal: %w", err)
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(raw); err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func (m *SMTPMailer) dial(ctx context.Context) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: m.timeout}
+	if m.tlsMode == "tls" {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: m.host}}
+		return tlsDialer.DialContext(ctx, "tcp", m.addr)
+	}
+	return dialer.DialContext(ctx, "tcp", m.addr)
+}
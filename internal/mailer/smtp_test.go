@@ -0,0 +1,189 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"portolio-backend/configs/constants"
+)
+
+// smtpSession adalah isi satu percakapan yang diterima smtpSink
+type smtpSession struct {
+	mailFrom string
+	rcptTo   []string
+	data     string
+}
+
+// smtpSink adalah server SMTP minimal yang menerima satu email lalu mengirimkannya ke channel
+func smtpSink(t *testing.T) (host string, port int, sessions <-chan smtpSession) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	out := make(chan smtpSession, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+		r := bufio.NewReader(conn)
+		reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+		reply("220 sink.test ESMTP")
+
+		var session smtpSession
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			command := strings.ToUpper(line)
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 sink.test")
+			case strings.HasPrefix(command, "MAIL FROM:"):
+				session.mailFrom = line[len("MAIL FROM:"):]
+				reply("250 OK")
+			case strings.HasPrefix(command, "RCPT TO:"):
+				session.rcptTo = append(session.rcptTo, line[len("RCPT TO:"):])
+				reply("250 OK")
+			case command == "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				var data strings.Builder
+				for {
+					dataLine, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if dataLine == ".\r\n" {
+						break
+					}
+					data.WriteString(strings.TrimPrefix(dataLine, "."))
+				}
+				session.data = data.String()
+				reply("250 OK: queued")
+			case command == "QUIT":
+				reply("221 Bye")
+				out <- session
+				return
+			default:
+				reply("502 Command not implemented")
+			}
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, out
+}
+
+func TestSMTPMailerSendsRenderedTemplate(t *testing.T) {
+	host, port, sessions := smtpSink(t)
+
+	relatedID := uint(42)
+	subject, html, text, err := Render(constants.DefaultLanguage, "shipment", TemplateData{
+		AppName:       "Portolio",
+		RecipientName: "Budi",
+		Message:       "Pesanan 'Kemeja Batik' senilai Rp150.000 sedang dikirim — terima kasih.",
+		RelatedID:     &relatedID,
+		ActionURL:     "https://portolio.test/cart",
+		ActionText:    "Lihat pesanan",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject != "Pesanan #42 sedang dikirim" {
+		t.Fatalf("subject = %q, ingin dari blok subject template", subject)
+	}
+
+	m := NewSMTPMailer(Config{
+		From:     "Portolio <no-reply@portolio.test>",
+		SMTPHost: host,
+		SMTPPort: port,
+		SMTPTLS:  "none",
+		Timeout:  5 * time.Second,
+	})
+	if err := m.Send(context.Background(), Message{To: "Budi <budi@example.com>", Subject: subject, HTML: html, Text: text}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	var session smtpSession
+	select {
+	case session = <-sessions:
+	case <-time.After(5 * time.Second):
+		t.Fatal("sink tidak menerima email")
+	}
+
+	if session.mailFrom != "<no-reply@portolio.test>" {
+		t.Errorf("MAIL FROM = %q", session.mailFrom)
+	}
+	if len(session.rcptTo) != 1 || session.rcptTo[0] != "<budi@example.com>" {
+		t.Errorf("RCPT TO = %q", session.rcptTo)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(session.data))
+	if err != nil {
+		t.Fatalf("membaca email: %v", err)
+	}
+	gotSubject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || gotSubject != subject {
+		t.Errorf("Subject = %q (%v), ingin %q", gotSubject, err, subject)
+	}
+	if msg.Header.Get("To") != "Budi <budi@example.com>" || msg.Header.Get("From") != "Portolio <no-reply@portolio.test>" {
+		t.Errorf("From/To = %q / %q", msg.Header.Get("From"), msg.Header.Get("To"))
+	}
+	if msg.Header.Get("Message-ID") == "" || !strings.HasSuffix(msg.Header.Get("Message-ID"), "@portolio.test>") {
+		t.Errorf("Message-ID = %q", msg.Header.Get("Message-ID"))
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q (%v)", msg.Header.Get("Content-Type"), err)
+	}
+	parts := map[string]string{}
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(part)
+		// Bagian teks dikirim dengan akhir baris CRLF sesuai bentuk kanonik MIME
+		parts[partType] = strings.ReplaceAll(string(body), "\r\n", "\n")
+	}
+
+	if len(parts) != 2 {
+		t.Fatalf("bagian email = %d, ingin text/plain dan text/html", len(parts))
+	}
+	if parts["text/plain"] != text {
+		t.Errorf("text/plain = %q, ingin %q", parts["text/plain"], text)
+	}
+	if parts["text/html"] != html {
+		t.Errorf("text/html tidak sama dengan hasil render")
+	}
+	for _, want := range []string{"Halo Budi", "Rp150.000 sedang dikirim — terima kasih.", "Lihat pesanan: https://portolio.test/cart"} {
+		if !strings.Contains(parts["text/plain"], want) {
+			t.Errorf("text/plain tidak berisi %q", want)
+		}
+	}
+	if !strings.Contains(parts["text/html"], `href="https://portolio.test/cart"`) {
+		t.Errorf("text/html tidak berisi tautan aksi")
+	}
+}
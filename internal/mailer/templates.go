@@ -0,0 +1,83 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+
+	"portolio-backend/configs/constants"
+)
+
+//go:embed templates/*.html templates/*.txt templates/en/*.html templates/en/*.txt
+var templateFS embed.FS
+
+// DefaultTemplate dipakai untuk event yang tidak memiliki template sendiri
+const DefaultTemplate = "notification"
+
+// TemplateData adalah data yang tersedia untuk setiap template email
+type TemplateData struct {
+	AppName       string
+	RecipientName string
+	Message       string
+	RelatedID     *uint
+	ActionURL     string
+	ActionText    string
+}
+
+// Render menyusun subjek, isi HTML, dan isi teks email untuk event name dalam bahasa lang.
+// Setiap event memiliki templates/<name>.html yang dibungkus layout.html dan
+// templates/<name>.txt; keduanya mendefinisikan blok "subject". Terjemahan disimpan di
+// templates/<lang>/ dengan nama yang sama, dan bahasa bawaan memakai templates/ langsung.
+// Event tanpa template memakai DefaultTemplate.
+func Render(lang constants.Language, name string, data TemplateData) (subject, html, text string, err error) {
+	if !HasTemplate(name) {
+		name = DefaultTemplate
+	}
+
+	textTmpl, err := texttemplate.ParseFS(templateFS, templatePath(lang, name+".txt"))
+	if err != nil {
+		return "", "", "", fmt.Errorf("gagal memuat template email %s: %w", name, err)
+	}
+	var subjectBuf, textBuf bytes.Buffer
+	if err := textTmpl.ExecuteTemplate(&subjectBuf, "subject", data); err != nil {
+		return "", "", "", err
+	}
+	if err := textTmpl.Execute(&textBuf, data); err != nil {
+		return "", "", "", err
+	}
+
+	htmlTmpl, err := htmltemplate.ParseFS(templateFS, templatePath(lang, "layout.html"), templatePath(lang, name+".html"))
+	if err != nil {
+		return "", "", "", fmt.Errorf("gagal memuat template email %s: %w", name, err)
+	}
+	var htmlBuf bytes.Buffer
+	if err := htmlTmpl.ExecuteTemplate(&htmlBuf, "layout.html", data); err != nil {
+		return "", "", "", err
+	}
+
+	return strings.TrimSpace(subjectBuf.String()), htmlBuf.String(), strings.TrimSpace(textBuf.String()) + "\n", nil
+}
+
+// HasTemplate memeriksa apakah event memiliki template sendiri
+func HasTemplate(name string) bool {
+	if name == "" || name == "layout" || strings.ContainsAny(name, "/\\.") {
+		return false
+	}
+	_, errHTML := templateFS.Open("templates/" + name + ".html")
+	_, errText := templateFS.Open("templates/" + name + ".txt")
+	return errHTML == nil && errText == nil
+}
+
+// templatePath memilih file template terjemahan bila ada, atau versi bahasa bawaan
+func templatePath(lang constants.Language, file string) string {
+	if lang != "" && lang != constants.DefaultLanguage {
+		localized := "templates/" + string(lang) + "/" + file
+		if _, err := templateFS.Open(localized); err == nil {
+			return localized
+		}
+	}
+	return "templates/" + file
+}
@@ -13,7 +13,7 @@ import (
 	"portolio-backend/configs"
 	"portolio-backend/configs/constants"
 	"portolio-backend/internal/model/db"
-	"portolio-backend/internal/model/dto"
+	"portolio-backend/internal/notify"
 	"portolio-backend/internal/util"
 )
 
@@ -110,7 +110,7 @@ func JWTMiddleware() gin.HandlerFunc {
 					c.Next()
 					return
 				} else {
-					util.RespondJSON(c, http.StatusUnauthorized, "Token kadaluarsa, mohon login kembali.")
+					util.RespondJSON(c, http.StatusUnauthorized, constants.ErrMsgTokenExpired)
 					return
 				}
 			}
@@ -158,6 +158,7 @@ func Authorize(dbConn *gorm.DB) gin.HandlerFunc {
 		}
 
 		c.Set("ROLE", user.Role)
+		c.Set("LANGUAGE", user.Language)
 		c.Next()
 	}
 }
@@ -242,19 +243,11 @@ func CheckUserStatus(dbConn *gorm.DB) gin.HandlerFunc {
 				user.PenaltyWarnings = 0
 				dbConn.Save(&user)
 
-				notification := dto.NotificationResponse{
-					UserID:    user.ID,
-					Type:      constants.NotifTypeAccount,
-					Message:   "Masa pemblokiran akun Anda telah berakhir. Akun Anda sekarang aktif kembali.",
-					RelatedID: &user.ID,
-					CreatedAt: time.Now(),
-				}
-				util.SendNotificationToUser(user.ID, notification)
-				dbConn.Create(&db.Notification{
-					UserID:    notification.UserID,
-					Type:      notification.Type,
-					Message:   notification.Message,
-					RelatedID: notification.RelatedID,
+				notify.Send(dbConn, &db.Notification{
+					UserID:     user.ID,
+					Type:       constants.NotifTypeAccount,
+					MessageKey: "notification.account_ban_expired",
+					RelatedID:  &user.ID,
 				})
 
 				c.Next()
@@ -271,19 +264,12 @@ func CheckUserStatus(dbConn *gorm.DB) gin.HandlerFunc {
 			user.BanReason = fmt.Sprintf("Akun ditangguhkan otomatis karena mencapai %d peringatan penalti.", penaltyLimit)
 			dbConn.Save(&user)
 
-			notification := dto.NotificationResponse{
-				UserID:    user.ID,
-				Type:      constants.NotifTypeAccount,
-				Message:   fmt.Sprintf("Akun Anda telah ditangguhkan secara permanen karena mencapai batas %d peringatan penalti. Mohon hubungi dukungan.", penaltyLimit),
-				RelatedID: &user.ID,
-				CreatedAt: time.Now(),
-			}
-			util.SendNotificationToUser(user.ID, notification)
-			dbConn.Create(&db.Notification{
-				UserID:    notification.UserID,
-				Type:      notification.Type,
-				Message:   notification.Message,
-				RelatedID: notification.RelatedID,
+			notify.Send(dbConn, &db.Notification{
+				UserID:        user.ID,
+				Type:          constants.NotifTypeAccount,
+				MessageKey:    "notification.account_auto_suspended",
+				MessageParams: db.JSONB{"limit": penaltyLimit},
+				RelatedID:     &user.ID,
 			})
 
 			util.RespondJSON(c, http.StatusForbidden, constants.ErrMsgAccountSuspended)
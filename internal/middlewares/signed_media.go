@@ -0,0 +1,48 @@
+package middlewares
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/util"
+)
+
+// SignedMediaKey ditandai di context saat request media memakai URL bertanda tangan
+const SignedMediaKey = "MEDIA_SIGNED"
+
+// SignedMedia memverifikasi URL media bertanda tangan (uid, exp, sig) tanpa query database.
+// Request tanpa sig diteruskan ke middleware JWT seperti biasa.
+func SignedMedia() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sig := c.Query("sig")
+		if sig == "" {
+			c.Next()
+			return
+		}
+
+		userID, expiresAt, ok := util.VerifyMediaSignature(c.Request.URL.Path, c.Query("uid"), c.Query("exp"), sig, time.Now())
+		if !ok {
+			util.RespondJSON(c, http.StatusForbidden, constants.ErrMsgMediaSignatureInvalid)
+			return
+		}
+
+		c.Set("ID", userID)
+		c.Set(SignedMediaKey, true)
+		c.Set("MEDIA_EXPIRES_AT", expiresAt)
+		c.Next()
+	}
+}
+
+// UnlessSignedMedia melewati middleware next untuk request yang sudah lolos SignedMedia
+func UnlessSignedMedia(next gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetBool(SignedMediaKey) {
+			c.Next()
+			return
+		}
+		next(c)
+	}
+}
@@ -22,6 +22,11 @@ type User struct {
 	BanUntil        *int64 `gorm:"type:bigint" json:"ban_until,omitempty"`
 	BanReason       string `gorm:"type:text" json:"ban_reason,omitempty"`
 	PenaltyWarnings uint   `gorm:"default:0" json:"penalty_warnings"`
+	// StorageQuotaBytes menimpa kuota bawaan role bila diisi admin
+	StorageQuotaBytes *int64 `gorm:"type:bigint" json:"storage_quota_bytes,omitempty"`
+	StorageUsedBytes  int64  `gorm:"type:bigint;default:0" json:"storage_used_bytes"`
+	// Language adalah bahasa pesan pilihan pengguna; kosong berarti mengikuti Accept-Language
+	Language constants.Language `gorm:"type:varchar(10)" json:"language,omitempty"`
 
 	Products           []Product           `gorm:"foreignKey:UserID" json:"products,omitempty"`
 	TransactionHistories []TransactionHistory `gorm:"foreignKey:UserID" json:"transaction_histories,omitempty"`
@@ -41,8 +46,12 @@ type Product struct {
 	Price      uint   `gorm:"not null" json:"price"`
 	Stock      uint   `gorm:"not null" json:"stock"`
 	Visibility constants.ProductVisibility `gorm:"type:varchar(50);default:'all'" json:"visibility"`
+	VisibilityReason constants.VisibilityReason `gorm:"type:varchar(50)" json:"visibility_reason,omitempty"`
 	Categories string `gorm:"type:text" json:"categories"`
 	IsActive   bool   `gorm:"default:true" json:"is_active"`
+	PreviousPrice *uint `json:"previous_price,omitempty"`
+	ReservedStock uint  `gorm:"default:0" json:"reserved_stock"`
+	LowStockThreshold uint `gorm:"default:0" json:"low_stock_threshold"`
 
 	User                 User                  `gorm:"foreignKey:UserID" json:"user,omitempty"`
 	Images               []ProductImage        `gorm:"foreignKey:ProductID" json:"images,omitempty"`
@@ -54,6 +63,16 @@ type ProductImage struct {
 	gorm.Model
 	ProductID uint   `json:"product_id"`
 	ImageURL  string `json:"image_url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	MediumURL    string `json:"medium_url"`
+	Position  int    `gorm:"not null;default:0;index" json:"position"`
+	IsPrimary bool   `gorm:"not null;default:false" json:"is_primary"`
+	AltText   string `gorm:"size:255" json:"alt_text"`
+	Status    constants.ProductImageStatus `gorm:"size:20;not null;default:'ready';index" json:"status"`
+	SourceURL string `json:"source_url,omitempty"`
+	FetchError string `json:"fetch_error,omitempty"`
+	// ClaimedAt dicatat saat jobs.RunImageFetcher mengklaim gambar untuk diunduh
+	ClaimedAt *time.Time `json:"-"`
 
 	Product Product `gorm:"foreignKey:ProductID" json:"-"`
 }
@@ -69,11 +88,22 @@ type TransactionHistory struct {
 	Status        constants.TransactionStatus `gorm:"type:varchar(50);not null" json:"status"`
 	IsSolved      bool   `gorm:"default:false" json:"is_solved"`
 	ReceiptStatus constants.ReceiptStatus `gorm:"type:varchar(50);default:'PENDING_PROCESS'" json:"receipt_status"`
+	CouponID         *uint `json:"coupon_id,omitempty"`
+	DiscountAmount   uint  `gorm:"default:0" json:"discount_amount"`
+	PlatformDiscount uint  `gorm:"default:0" json:"platform_discount"`
+	// CouponRedemptionID menunjuk redemption yang dipakai bersama seluruh item pesanan
+	CouponRedemptionID *uint `gorm:"index" json:"coupon_redemption_id,omitempty"`
 
 	Product Product `gorm:"foreignKey:ProductID" json:"product,omitempty"`
 	User    User    `gorm:"foreignKey:UserID" json:"user,omitempty"`
 }
 
+// SellerPayoutAmount menghitung dana yang diterima penjual dari transaksi.
+// Potongan kupon yang ditanggung platform tetap dibayarkan ke penjual.
+func (trx TransactionHistory) SellerPayoutAmount() uint {
+	return trx.TotalPrice + trx.PlatformDiscount - trx.EcommerceTax
+}
+
 type BalanceHistory struct {
 	gorm.Model
 	UserID       uint        `json:"user_id"`
@@ -99,24 +129,29 @@ type Review struct {
 
 type Notification struct {
 	gorm.Model
-	UserID    uint             `gorm:"not null" json:"user_id"`
+	UserID    uint             `gorm:"not null;index:idx_notification_user_read" json:"user_id"`
 	Type      constants.NotificationType `gorm:"type:varchar(50);not null" json:"type"`
 	Message   string           `gorm:"type:text;not null" json:"message"`
+	// MessageKey dan MessageParams menyimpan template katalog i18n agar pesan dapat
+	// dirender ulang dalam bahasa pembaca. Message berisi hasil render bahasa bawaan.
+	MessageKey    string `gorm:"type:varchar(100)" json:"message_key,omitempty"`
+	MessageParams JSONB  `gorm:"type:jsonb" json:"message_params,omitempty"`
 	RelatedID *uint            `json:"related_id,omitempty"`
-	IsRead    bool             `gorm:"default:false" json:"is_read"`
+	IsRead    bool             `gorm:"default:false;index:idx_notification_user_read" json:"is_read"`
 
 	User User `gorm:"foreignKey:UserID" json:"user,omitempty"`
 }
 
 type ChatMessage struct {
 	gorm.Model
-	TransactionID uint            `gorm:"not null" json:"transaction_id"`
+	TransactionID uint            `gorm:"not null;index" json:"transaction_id"`
 	SenderID      uint            `gorm:"not null" json:"sender_id"`
 	ReceiverID    uint            `gorm:"not null" json:"receiver_id"`
 	MessageType   constants.ChatMessageType `gorm:"type:varchar(50);not null" json:"message_type"`
 	Content       string          `gorm:"type:text" json:"content"`
 	FileURL       string          `gorm:"type:varchar(255)" json:"file_url,omitempty"`
 	IsRead        bool            `gorm:"default:false" json:"is_read"`
+	ReadAt        *time.Time      `json:"read_at,omitempty"`
 
 	Sender      User                `gorm:"foreignKey:SenderID" json:"sender,omitempty"`
 	Transaction TransactionHistory `gorm:"foreignKey:TransactionID" json:"transaction,omitempty"`
@@ -169,6 +204,10 @@ func (j JSONB) Value() (driver.Value, error) {
 }
 
 func (j *JSONB) Scan(value interface{}) error {
+	if value == nil {
+		*j = nil
+		return nil
+	}
 	bytes, ok := value.([]byte)
 	if !ok {
 		return errors.New("failed to unmarshal JSONB value")
@@ -186,4 +225,313 @@ type AdminLog struct {
 	IPAddress  string `gorm:"type:varchar(50)" json:"ip_address"`
 
 	Admin User `gorm:"foreignKey:AdminID" json:"admin,omitempty"`
-}
\ No newline at end of file
+}
+type ProductImportJob struct {
+	gorm.Model
+	UserID      uint                      `gorm:"not null;index" json:"user_id"`
+	FileName    string                    `gorm:"type:varchar(255)" json:"file_name"`
+	Format      constants.ImportFormat    `gorm:"type:varchar(20);not null" json:"format"`
+	Status      constants.ImportJobStatus `gorm:"type:varchar(50);default:'pending'" json:"status"`
+	TotalRows   uint                      `gorm:"default:0" json:"total_rows"`
+	SuccessRows uint                      `gorm:"default:0" json:"success_rows"`
+	FailedRows  uint                      `gorm:"default:0" json:"failed_rows"`
+	StartedAt   *time.Time                `json:"started_at,omitempty"`
+	FinishedAt  *time.Time                `json:"finished_at,omitempty"`
+
+	User   User                 `gorm:"foreignKey:UserID" json:"-"`
+	Errors []ProductImportError `gorm:"foreignKey:JobID" json:"errors,omitempty"`
+}
+
+type ProductImportError struct {
+	gorm.Model
+	JobID     uint   `gorm:"not null;index" json:"job_id"`
+	RowNumber uint   `gorm:"not null" json:"row_number"`
+	Field     string `gorm:"type:varchar(100)" json:"field"`
+	Reason    string `gorm:"type:text" json:"reason"`
+}
+
+type ProductPriceHistory struct {
+	gorm.Model
+	ProductID  uint                        `gorm:"not null;index" json:"product_id"`
+	OldPrice   uint                        `gorm:"not null" json:"old_price"`
+	NewPrice   uint                        `gorm:"not null" json:"new_price"`
+	Reason     constants.PriceChangeReason `gorm:"type:varchar(50);not null" json:"reason"`
+	ChangedBy  *uint                       `json:"changed_by,omitempty"`
+	ScheduleID *uint                       `json:"schedule_id,omitempty"`
+
+	Product Product `gorm:"foreignKey:ProductID" json:"-"`
+}
+
+type ProductPriceSchedule struct {
+	gorm.Model
+	ProductID     uint                          `gorm:"not null;index" json:"product_id"`
+	UserID        uint                          `gorm:"not null" json:"user_id"`
+	Price         uint                          `gorm:"not null" json:"price"`
+	OriginalPrice uint                          `gorm:"default:0" json:"original_price"`
+	StartAt       time.Time                     `gorm:"not null;index" json:"start_at"`
+	EndAt         *time.Time                    `gorm:"index" json:"end_at,omitempty"`
+	Status        constants.PriceScheduleStatus `gorm:"type:varchar(50);default:'scheduled';index" json:"status"`
+
+	Product Product `gorm:"foreignKey:ProductID" json:"-"`
+}
+
+type Coupon struct {
+	gorm.Model
+	Code          string                       `gorm:"type:varchar(50);uniqueIndex;not null" json:"code"`
+	Description   string                       `gorm:"type:text" json:"description"`
+	DiscountType  constants.CouponDiscountType `gorm:"type:varchar(50);not null" json:"discount_type"`
+	DiscountValue uint                         `gorm:"not null" json:"discount_value"`
+	MaxDiscount   uint                         `gorm:"default:0" json:"max_discount"`
+	FundedBy      constants.CouponFundedBy     `gorm:"type:varchar(50);not null" json:"funded_by"`
+	SellerID      *uint                        `gorm:"index" json:"seller_id,omitempty"`
+	CreatedBy     uint                         `gorm:"not null" json:"created_by"`
+	MinSpend      uint                         `gorm:"default:0" json:"min_spend"`
+	UsageLimit    uint                         `gorm:"default:0" json:"usage_limit"`
+	PerUserLimit  uint                         `gorm:"default:0" json:"per_user_limit"`
+	UsedCount     uint                         `gorm:"default:0" json:"used_count"`
+	StartsAt      *time.Time                   `json:"starts_at,omitempty"`
+	ExpiresAt     *time.Time                   `json:"expires_at,omitempty"`
+	ProductIDs    string                       `gorm:"type:text" json:"product_ids"`
+	Categories    string                       `gorm:"type:text" json:"categories"`
+	IsActive      bool                         `gorm:"default:true" json:"is_active"`
+
+	Redemptions []CouponRedemption `gorm:"foreignKey:CouponID" json:"redemptions,omitempty"`
+}
+
+type CouponRedemption struct {
+	gorm.Model
+	CouponID       uint                             `gorm:"not null;index" json:"coupon_id"`
+	UserID         uint                             `gorm:"not null;index" json:"user_id"`
+	TransactionID  uint                             `gorm:"not null;index" json:"transaction_id"`
+	DiscountAmount uint                             `gorm:"not null" json:"discount_amount"`
+	Status         constants.CouponRedemptionStatus `gorm:"type:varchar(50);default:'applied'" json:"status"`
+
+	Coupon      Coupon             `gorm:"foreignKey:CouponID" json:"-"`
+	Transaction TransactionHistory `gorm:"foreignKey:TransactionID" json:"-"`
+}
+
+type WishlistItem struct {
+	gorm.Model
+	UserID    uint `gorm:"not null;uniqueIndex:idx_wishlist_user_product" json:"user_id"`
+	ProductID uint `gorm:"not null;uniqueIndex:idx_wishlist_user_product" json:"product_id"`
+
+	User    User    `gorm:"foreignKey:UserID" json:"-"`
+	Product Product `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+}
+
+type RestockSubscription struct {
+	gorm.Model
+	UserID     uint       `gorm:"not null;uniqueIndex:idx_restock_user_product" json:"user_id"`
+	ProductID  uint       `gorm:"not null;uniqueIndex:idx_restock_user_product;index" json:"product_id"`
+	NotifiedAt *time.Time `json:"notified_at,omitempty"`
+
+	User    User    `gorm:"foreignKey:UserID" json:"-"`
+	Product Product `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+}
+
+type StockReservation struct {
+	gorm.Model
+	UserID        uint                        `gorm:"not null;index" json:"user_id"`
+	ProductID     uint                        `gorm:"not null;index" json:"product_id"`
+	Quantity      uint                        `gorm:"not null" json:"quantity"`
+	Status        constants.ReservationStatus `gorm:"type:varchar(50);default:'active';index" json:"status"`
+	ExpiresAt     time.Time                   `gorm:"not null;index" json:"expires_at"`
+	TransactionID *uint                       `json:"transaction_id,omitempty"`
+
+	Product Product `gorm:"foreignKey:ProductID" json:"-"`
+}
+
+type InventoryMovement struct {
+	gorm.Model
+	ProductID     uint                     `gorm:"not null;index" json:"product_id"`
+	Change        int                      `gorm:"not null" json:"change"`
+	StockBefore   uint                     `gorm:"not null" json:"stock_before"`
+	StockAfter    uint                     `gorm:"not null" json:"stock_after"`
+	Cause         constants.InventoryCause `gorm:"type:varchar(50);not null;index" json:"cause"`
+	Note          string                   `gorm:"type:text" json:"note,omitempty"`
+	ActorID       *uint                    `json:"actor_id,omitempty"`
+	TransactionID *uint                    `json:"transaction_id,omitempty"`
+
+	Product Product `gorm:"foreignKey:ProductID" json:"-"`
+}
+
+type ProductVisibilityLog struct {
+	gorm.Model
+	ProductID      uint                        `gorm:"not null;index" json:"product_id"`
+	FromVisibility constants.ProductVisibility `gorm:"type:varchar(50)" json:"from_visibility"`
+	ToVisibility   constants.ProductVisibility `gorm:"type:varchar(50);not null" json:"to_visibility"`
+	Reason         constants.VisibilityReason  `gorm:"type:varchar(50);not null" json:"reason"`
+	ActorID        *uint                       `json:"actor_id,omitempty"`
+
+	Product Product `gorm:"foreignKey:ProductID" json:"-"`
+}
+
+// Upload mencatat pemilik setiap file yang diunggah melalui /api/upload. ReferencedBy
+// diisi saat file dipakai pesan (misalnya "chat_message:12"); unggahan chat dan dukungan
+// yang tidak pernah dipakai dihapus oleh RunUploadGC setelah masa tenggang.
+type Upload struct {
+	gorm.Model
+	UserID       uint                    `gorm:"not null;index" json:"user_id"`
+	Purpose      constants.UploadPurpose `gorm:"type:varchar(50);not null" json:"purpose"`
+	FileURL      string                  `gorm:"type:varchar(255);not null;uniqueIndex" json:"file_url"`
+	FileName     string                  `gorm:"type:varchar(255)" json:"file_name"`
+	FileHash     string                  `gorm:"type:varchar(64);index" json:"file_hash"`
+	FileSize     int64                   `gorm:"not null" json:"file_size"`
+	ReferencedBy string                  `gorm:"type:varchar(100);index" json:"referenced_by,omitempty"`
+	ReferencedAt *time.Time              `json:"referenced_at,omitempty"`
+
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// NotificationPreference menyimpan pilihan pengguna untuk satu tipe notifikasi pada satu
+// channel. Kombinasi yang tidak tersimpan memakai nilai bawaan dari paket notify.
+type NotificationPreference struct {
+	gorm.Model
+	UserID  uint                          `gorm:"not null;uniqueIndex:idx_notification_pref" json:"user_id"`
+	Type    constants.NotificationType    `gorm:"type:varchar(50);not null;uniqueIndex:idx_notification_pref" json:"type"`
+	Channel constants.NotificationChannel `gorm:"type:varchar(50);not null;uniqueIndex:idx_notification_pref" json:"channel"`
+	Enabled bool                          `gorm:"not null" json:"enabled"`
+}
+
+// NotificationSetting menyimpan jam tenang pengguna. Start dan End berformat "HH:MM"
+// pada zona waktu Timezone dan boleh melewati tengah malam (misalnya 22:00-07:00).
+type NotificationSetting struct {
+	gorm.Model
+	UserID            uint   `gorm:"not null;uniqueIndex" json:"user_id"`
+	QuietHoursEnabled bool   `gorm:"default:false" json:"quiet_hours_enabled"`
+	QuietHoursStart   string `gorm:"type:varchar(5)" json:"quiet_hours_start"`
+	QuietHoursEnd     string `gorm:"type:varchar(5)" json:"quiet_hours_end"`
+	Timezone          string `gorm:"type:varchar(64)" json:"timezone"`
+}
+
+// NotificationDigestPreference menyimpan frekuensi ringkasan per tipe notifikasi. Tipe tanpa
+// baris dikirim langsung (immediate).
+type NotificationDigestPreference struct {
+	gorm.Model
+	UserID    uint                       `gorm:"not null;uniqueIndex:idx_notification_digest_pref" json:"user_id"`
+	Type      constants.NotificationType `gorm:"type:varchar(50);not null;uniqueIndex:idx_notification_digest_pref" json:"type"`
+	Frequency constants.DigestFrequency  `gorm:"type:varchar(20);not null" json:"frequency"`
+}
+
+// NotificationDigestItem menampung notifikasi bertipe ringkasan sampai DeliverAt, lalu
+// digabung menjadi satu Notification dan dihapus
+type NotificationDigestItem struct {
+	gorm.Model
+	UserID        uint                       `gorm:"not null;index:idx_notification_digest_item" json:"user_id"`
+	Type          constants.NotificationType `gorm:"type:varchar(50);not null;index:idx_notification_digest_item" json:"type"`
+	Frequency     constants.DigestFrequency  `gorm:"type:varchar(20);not null" json:"frequency"`
+	Message       string                     `gorm:"type:text;not null" json:"message"`
+	MessageKey    string                     `gorm:"type:varchar(100)" json:"message_key,omitempty"`
+	MessageParams JSONB                      `gorm:"type:jsonb" json:"message_params,omitempty"`
+	RelatedID     *uint                      `json:"related_id,omitempty"`
+	DeliverAt     time.Time                  `gorm:"not null;index" json:"deliver_at"`
+}
+
+// NotificationArchive menyimpan notifikasi yang dipindahkan oleh kebijakan retensi dengan
+// ID aslinya
+type NotificationArchive struct {
+	ID            uint                       `gorm:"primaryKey" json:"id"`
+	UserID        uint                       `gorm:"not null;index" json:"user_id"`
+	Type          constants.NotificationType `gorm:"type:varchar(50);not null" json:"type"`
+	Message       string                     `gorm:"type:text;not null" json:"message"`
+	MessageKey    string                     `gorm:"type:varchar(100)" json:"message_key,omitempty"`
+	MessageParams JSONB                      `gorm:"type:jsonb" json:"message_params,omitempty"`
+	RelatedID     *uint                      `json:"related_id,omitempty"`
+	IsRead        bool                       `json:"is_read"`
+	CreatedAt     time.Time                  `json:"created_at"`
+	ArchivedAt    time.Time                  `gorm:"not null;index" json:"archived_at"`
+}
+
+// EmailOutbox adalah antrean email yang ditulis dalam transaksi yang sama dengan notifikasi
+// dan dikirim oleh RunEmailOutbox dengan percobaan ulang.
+type EmailOutbox struct {
+	gorm.Model
+	UserID        *uint                 `gorm:"index" json:"user_id,omitempty"`
+	ToAddress     string                `gorm:"type:varchar(255);not null" json:"to_address"`
+	Template      string                `gorm:"type:varchar(50);not null" json:"template"`
+	Subject       string                `gorm:"type:varchar(255);not null" json:"subject"`
+	HTMLBody      string                `gorm:"type:text" json:"-"`
+	TextBody      string                `gorm:"type:text" json:"-"`
+	Status        constants.EmailStatus `gorm:"type:varchar(50);default:'pending';index" json:"status"`
+	Attempts      int                   `gorm:"default:0" json:"attempts"`
+	NextAttemptAt time.Time             `gorm:"index" json:"next_attempt_at"`
+	LastError     string                `gorm:"type:text" json:"last_error,omitempty"`
+	SentAt        *time.Time            `json:"sent_at,omitempty"`
+}
+
+// OutboxEvent adalah event domain yang ditulis dalam transaksi yang sama dengan perubahan
+// state dan diteruskan ke subscriber oleh relay (at-least-once).
+type OutboxEvent struct {
+	gorm.Model
+	EventType     constants.EventType    `gorm:"type:varchar(100);not null;index" json:"event_type"`
+	AggregateType string                 `gorm:"type:varchar(50);not null;index:idx_outbox_aggregate" json:"aggregate_type"`
+	AggregateID   uint                   `gorm:"not null;index:idx_outbox_aggregate" json:"aggregate_id"`
+	Payload       JSONB                  `gorm:"type:jsonb" json:"payload"`
+	Status        constants.OutboxStatus `gorm:"type:varchar(50);default:'pending';index" json:"status"`
+	Attempts      int                    `gorm:"default:0" json:"attempts"`
+	NextAttemptAt time.Time              `gorm:"index" json:"next_attempt_at"`
+	LastError     string                 `gorm:"type:text" json:"last_error,omitempty"`
+	// CompletedSubscribers berisi nama subscriber (dipisah koma) yang sudah berhasil
+	// sehingga percobaan ulang hanya menjalankan subscriber yang gagal
+	CompletedSubscribers string     `gorm:"type:text" json:"completed_subscribers,omitempty"`
+	PublishedAt          *time.Time `json:"published_at,omitempty"`
+}
+
+// EventReceipt mencatat event yang sudah diproses satu subscriber agar event yang
+// diteruskan ulang tidak menjalankan efek sampingnya dua kali
+type EventReceipt struct {
+	EventID    uint      `gorm:"primaryKey;autoIncrement:false" json:"event_id"`
+	Subscriber string    `gorm:"type:varchar(100);primaryKey" json:"subscriber"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Webhook adalah endpoint milik pengguna yang menerima event terpilih dengan payload
+// bertanda tangan HMAC-SHA256 memakai Secret
+type Webhook struct {
+	gorm.Model
+	UserID      uint   `gorm:"not null;index" json:"user_id"`
+	URL         string `gorm:"type:varchar(500);not null" json:"url"`
+	Description string `gorm:"type:varchar(255)" json:"description"`
+	Secret      string `gorm:"type:varchar(100);not null" json:"-"`
+	// EventTypes berisi tipe event yang dilanggan, dipisah koma
+	EventTypes string `gorm:"type:text;not null" json:"event_types"`
+	IsActive   bool   `gorm:"default:true" json:"is_active"`
+
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// WebhookDelivery adalah satu pengiriman event ke webhook beserta hasil percobaan
+// terakhirnya. EventID kosong untuk uji coba.
+type WebhookDelivery struct {
+	gorm.Model
+	WebhookID     uint                            `gorm:"not null;uniqueIndex:idx_webhook_delivery_event" json:"webhook_id"`
+	EventID       *uint                           `gorm:"uniqueIndex:idx_webhook_delivery_event" json:"event_id,omitempty"`
+	EventType     constants.EventType             `gorm:"type:varchar(100);not null" json:"event_type"`
+	Payload       JSONB                           `gorm:"type:jsonb" json:"payload"`
+	Status        constants.WebhookDeliveryStatus `gorm:"type:varchar(50);default:'pending';index" json:"status"`
+	Attempts      int                             `gorm:"default:0" json:"attempts"`
+	NextAttemptAt time.Time                       `gorm:"index" json:"next_attempt_at"`
+	ResponseCode  int                             `json:"response_code"`
+	ResponseBody  string                          `gorm:"type:text" json:"response_body,omitempty"`
+	DurationMs    int64                           `json:"duration_ms"`
+	LastError     string                          `gorm:"type:text" json:"last_error,omitempty"`
+	DeliveredAt   *time.Time                      `json:"delivered_at,omitempty"`
+
+	Webhook Webhook `gorm:"foreignKey:WebhookID" json:"-"`
+}
+
+// PushSubscription adalah langganan Web Push satu browser/perangkat. Endpoint unik
+// sehingga browser yang berganti akun hanya menerima push untuk akun terakhir.
+type PushSubscription struct {
+	gorm.Model
+	UserID       uint       `gorm:"not null;index" json:"user_id"`
+	Endpoint     string     `gorm:"type:text;not null;uniqueIndex" json:"endpoint"`
+	P256dh       string     `gorm:"type:varchar(200);not null" json:"-"`
+	Auth         string     `gorm:"type:varchar(100);not null" json:"-"`
+	UserAgent    string     `gorm:"type:varchar(255)" json:"user_agent"`
+	ExpiresAt    *time.Time `gorm:"index" json:"expires_at,omitempty"`
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty"`
+	FailureCount int        `gorm:"default:0" json:"failure_count"`
+
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
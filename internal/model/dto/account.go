@@ -1,6 +1,10 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"portolio-backend/configs/constants"
+)
 
 type RequestPostRegister struct {
 	FullName string `json:"full_name" binding:"required,min=3,max=100"`
@@ -26,6 +30,27 @@ type RequestPatchAccount struct {
 	Email       string `json:"email,omitempty" binding:"omitempty,email"`
 	OldPassword string `json:"old_password,omitempty"`
 	NewPassword string `json:"new_password,omitempty" binding:"omitempty,min=8"`
+	// Language mengubah bahasa pesan; "auto" kembali mengikuti header Accept-Language
+	Language    string `json:"language,omitempty" binding:"omitempty,oneof=id en auto"`
+}
+
+type StorageUsageResponse struct {
+	UsedBytes      int64 `json:"used_bytes"`
+	QuotaBytes     int64 `json:"quota_bytes"`
+	RemainingBytes int64 `json:"remaining_bytes"`
+	IsCustomQuota  bool  `json:"is_custom_quota"`
+}
+
+type GetAccountResponse struct {
+	ID        uint                 `json:"id"`
+	FullName  string               `json:"full_name"`
+	Email     string               `json:"email"`
+	Role      constants.UserRole   `json:"role"`
+	Balance   uint                 `json:"balance"`
+	Status    constants.UserStatus `json:"status"`
+	Language  constants.Language   `json:"language"`
+	CreatedAt time.Time            `json:"created_at"`
+	Storage   StorageUsageResponse `json:"storage"`
 }
 
 type PostRegisterResponse struct {
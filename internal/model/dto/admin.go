@@ -40,6 +40,24 @@ type UserDetailResponse struct {
 	DeletedAt       *time.Time           `json:"deleted_at,omitempty"`
 }
 
+type RequestUpdateStorageQuota struct {
+	// QuotaMB kosong (null) mengembalikan pengguna ke kuota bawaan role
+	QuotaMB *int64 `json:"quota_mb" binding:"omitempty,gte=0,lte=1048576"`
+}
+
+type StorageConsumerResponse struct {
+	UserID   uint                 `json:"user_id"`
+	FullName string               `json:"full_name"`
+	Email    string               `json:"email"`
+	Role     constants.UserRole   `json:"role"`
+	Storage  StorageUsageResponse `json:"storage"`
+}
+
+type GetStorageUsageAdminResponse struct {
+	TotalUsedBytes int64                     `json:"total_used_bytes"`
+	Users          []StorageConsumerResponse `json:"users"`
+}
+
 type GetUsersResponse struct {
 	TotalRecords int64                `json:"total_records"`
 	Page         int                  `json:"page"`
@@ -60,6 +78,7 @@ type TransactionDetailResponse struct {
 	UserID        uint                        `json:"user_id"`
 	Quantity      uint                        `json:"quantity"`
 	TotalPrice    uint                        `json:"total_price"`
+	DiscountAmount uint                       `json:"discount_amount"`
 	GovtTax       uint                        `json:"govt_tax"`
 	EcommerceTax  uint                        `json:"ecommerce_tax"`
 	Status        constants.TransactionStatus `json:"status"`
@@ -117,4 +136,26 @@ type GetSupportTicketMessagesResponse struct {
 	Subject   string                   `json:"subject"`
 	Status    constants.SupportTicketStatus `json:"status"`
 	Messages  []SupportMessageResponse `json:"messages"`
-}
\ No newline at end of file
+}
+type OutboxEventResponse struct {
+	ID                   uint                   `json:"id"`
+	EventType            constants.EventType    `json:"event_type"`
+	AggregateType        string                 `json:"aggregate_type"`
+	AggregateID          uint                   `json:"aggregate_id"`
+	Payload              map[string]interface{} `json:"payload"`
+	Status               constants.OutboxStatus `json:"status"`
+	Attempts             int                    `json:"attempts"`
+	NextAttemptAt        time.Time              `json:"next_attempt_at"`
+	LastError            string                 `json:"last_error,omitempty"`
+	CompletedSubscribers []string               `json:"completed_subscribers"`
+	PublishedAt          *time.Time             `json:"published_at,omitempty"`
+	CreatedAt            time.Time              `json:"created_at"`
+}
+
+type GetOutboxEventsResponse struct {
+	TotalRecords int64                            `json:"total_records"`
+	Page         int                              `json:"page"`
+	Limit        int                              `json:"limit"`
+	StatusCounts map[constants.OutboxStatus]int64 `json:"status_counts"`
+	Events       []OutboxEventResponse            `json:"events"`
+}
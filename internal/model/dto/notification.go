@@ -0,0 +1,83 @@
+package dto
+
+import (
+	"time"
+
+	"portolio-backend/configs/constants"
+)
+
+type RequestMarkNotificationsRead struct {
+	IDs []uint `json:"ids" binding:"required,min=1,max=100"`
+}
+
+type GetNotificationsResponse struct {
+	TotalRecords  int64                  `json:"total_records"`
+	Page          int                    `json:"page"`
+	Limit         int                    `json:"limit"`
+	UnreadCount   int64                  `json:"unread_count"`
+	Notifications []NotificationResponse `json:"notifications"`
+}
+
+type UnreadNotificationCountResponse struct {
+	UnreadCount int64                                `json:"unread_count"`
+	ByType      map[constants.NotificationType]int64 `json:"by_type"`
+}
+
+type MarkNotificationsReadResponse struct {
+	Message     string `json:"message"`
+	Updated     int64  `json:"updated"`
+	UnreadCount int64  `json:"unread_count"`
+}
+
+type NotificationPreferenceItem struct {
+	Type    constants.NotificationType    `json:"type" binding:"required"`
+	Channel constants.NotificationChannel `json:"channel" binding:"required"`
+	Enabled *bool                         `json:"enabled" binding:"required"`
+}
+
+type QuietHoursSetting struct {
+	Enabled  bool   `json:"enabled"`
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Timezone string `json:"timezone"`
+}
+
+type NotificationDigestItem struct {
+	Type      constants.NotificationType `json:"type" binding:"required"`
+	Frequency constants.DigestFrequency  `json:"frequency" binding:"required"`
+}
+
+type RequestUpdateNotificationPreferences struct {
+	Preferences []NotificationPreferenceItem `json:"preferences" binding:"omitempty,max=100,dive"`
+	Digests     []NotificationDigestItem     `json:"digests" binding:"omitempty,max=20,dive"`
+	QuietHours  *QuietHoursSetting           `json:"quiet_hours"`
+}
+
+type NotificationPreferencesResponse struct {
+	Channels    []constants.NotificationChannel                                       `json:"channels"`
+	Preferences map[constants.NotificationType]map[constants.NotificationChannel]bool `json:"preferences"`
+	Digests     map[constants.NotificationType]constants.DigestFrequency              `json:"digests"`
+	QuietHours  QuietHoursSetting                                                     `json:"quiet_hours"`
+}
+
+type PushSubscriptionKeys struct {
+	P256dh string `json:"p256dh" binding:"required,max=200"`
+	Auth   string `json:"auth" binding:"required,max=100"`
+}
+
+// RequestCreatePushSubscription mengikuti bentuk PushSubscription.toJSON() di browser.
+// ExpirationTime dalam milidetik epoch atau null.
+type RequestCreatePushSubscription struct {
+	Endpoint       string               `json:"endpoint" binding:"required,url,max=1000"`
+	ExpirationTime *int64               `json:"expirationTime"`
+	Keys           PushSubscriptionKeys `json:"keys" binding:"required"`
+}
+
+type PushSubscriptionResponse struct {
+	ID         uint       `json:"id"`
+	Endpoint   string     `json:"endpoint"`
+	UserAgent  string     `json:"user_agent"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
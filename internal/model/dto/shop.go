@@ -13,6 +13,7 @@ type RequestPostProduct struct {
 	Visibility  constants.ProductVisibility `json:"visibility,omitempty" binding:"omitempty,oneof=all owner_admin"`
 	Categories  string                    `json:"categories,omitempty" binding:"omitempty,max=255"`
 	ImagesLinks []string                  `json:"images_links,omitempty" binding:"omitempty,dive,url"`
+	LowStockThreshold uint                `json:"low_stock_threshold,omitempty" binding:"omitempty,lte=10000"`
 }
 
 type RequestPutProduct struct {
@@ -23,17 +24,28 @@ type RequestPutProduct struct {
 	Categories  string                    `json:"categories,omitempty" binding:"omitempty,max=255"`
 	ImagesLinks []string                  `json:"images_links,omitempty" binding:"omitempty,dive,url"`
 	IsActive    *bool                     `json:"is_active,omitempty"`
+	LowStockThreshold *uint               `json:"low_stock_threshold,omitempty" binding:"omitempty,lte=10000"`
 }
 
 type RequestPurchaseItem struct {
-	ProductID uint `json:"product_id" binding:"required"`
-	Quantity  uint `json:"quantity" binding:"required,gt=0"`
+	ProductID  uint   `json:"product_id" binding:"required"`
+	Quantity   uint   `json:"quantity" binding:"required,gt=0"`
+	CouponCode string `json:"coupon_code,omitempty" binding:"omitempty,max=50"`
+	ReservationID *uint `json:"reservation_id,omitempty"`
 }
 
 type ProductImageResponse struct {
 	ID        uint   `json:"id"`
 	ProductID uint   `json:"product_id"`
 	ImageURL  string `json:"image_url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	MediumURL    string `json:"medium_url"`
+	Position  int    `json:"position"`
+	IsPrimary bool   `json:"is_primary"`
+	AltText   string `json:"alt_text"`
+	Status    constants.ProductImageStatus `json:"status"`
+	SourceURL string `json:"source_url,omitempty"`
+	FetchError string `json:"fetch_error,omitempty"`
 }
 
 type ProductDetailInCart struct {
@@ -54,6 +66,7 @@ type CartProduct struct {
 	UserID        uint      `json:"user_id"`
 	Quantity      uint      `json:"quantity"`
 	TotalPrice    uint      `json:"total_price"`
+	DiscountAmount uint     `json:"discount_amount"`
 	GovtTax       uint      `json:"govt_tax"`
 	EcommerceTax  uint      `json:"ecommerce_tax"`
 	Status        string    `json:"status"`
@@ -75,7 +88,9 @@ type GetProductsResponse struct {
 	Id         uint                   `json:"id"`
 	Title      string                 `json:"title"`
 	Price      uint                   `json:"price"`
+	PreviousPrice *uint               `json:"previous_price,omitempty"`
 	Stock      uint                   `json:"stock"`
+	AvailableStock uint               `json:"available_stock"`
 	Categories []string               `json:"categories"`
 	Images     []ProductImageResponse `json:"images"`
 	Rating     float64                `json:"rating"`
@@ -86,8 +101,11 @@ type GetProductsRequestAdmin struct {
 	Id         uint                   `json:"id"`
 	Title      string                 `json:"title"`
 	Price      uint                   `json:"price"`
+	PreviousPrice *uint               `json:"previous_price,omitempty"`
 	Stock      uint                   `json:"stock"`
+	AvailableStock uint               `json:"available_stock"`
 	Visibility string                 `json:"visibility"`
+	VisibilityReason constants.VisibilityReason `json:"visibility_reason,omitempty"`
 	Categories []string               `json:"categories"`
 	Images     []ProductImageResponse `json:"images"`
 	Rating     float64                `json:"rating"`
@@ -105,8 +123,13 @@ type ProductDetailResponse struct {
 	UserID     uint                      `json:"user_id"`
 	Title      string                    `json:"title"`
 	Price      uint                      `json:"price"`
+	PreviousPrice *uint                  `json:"previous_price,omitempty"`
 	Stock      uint                      `json:"stock"`
+	ReservedStock  uint                  `json:"reserved_stock"`
+	AvailableStock uint                  `json:"available_stock"`
+	LowStockThreshold uint               `json:"low_stock_threshold"`
 	Visibility constants.ProductVisibility `json:"visibility"`
+	VisibilityReason constants.VisibilityReason `json:"visibility_reason,omitempty"`
 	Categories []string                  `json:"categories"`
 	IsActive   bool                      `json:"is_active"`
 	Images     []ProductImageResponse    `json:"images"`
@@ -139,4 +162,235 @@ type GetOwnerProductOrdersResponse struct {
 	Page         int                         `json:"page"`
 	Limit        int                         `json:"limit"`
 	Orders       []db.TransactionHistory `json:"orders"` 
-}
\ No newline at end of file
+}
+type ProductImportErrorResponse struct {
+	RowNumber uint   `json:"row_number"`
+	Field     string `json:"field"`
+	Reason    string `json:"reason"`
+}
+
+type ProductImportJobResponse struct {
+	ID          uint                         `json:"id"`
+	FileName    string                       `json:"file_name"`
+	Format      constants.ImportFormat       `json:"format"`
+	Status      constants.ImportJobStatus    `json:"status"`
+	TotalRows   uint                         `json:"total_rows"`
+	SuccessRows uint                         `json:"success_rows"`
+	FailedRows  uint                         `json:"failed_rows"`
+	StartedAt   *time.Time                   `json:"started_at,omitempty"`
+	FinishedAt  *time.Time                   `json:"finished_at,omitempty"`
+	CreatedAt   time.Time                    `json:"created_at"`
+	Errors      []ProductImportErrorResponse `json:"errors,omitempty"`
+}
+
+type GetProductImportJobsResponse struct {
+	TotalRecords int64                      `json:"total_records"`
+	Page         int                        `json:"page"`
+	Limit        int                        `json:"limit"`
+	Jobs         []ProductImportJobResponse `json:"jobs"`
+}
+
+type ProductExportItem struct {
+	ID          uint                        `json:"id"`
+	Title       string                      `json:"title"`
+	Price       uint                        `json:"price"`
+	Stock       uint                        `json:"stock"`
+	Visibility  constants.ProductVisibility `json:"visibility"`
+	Categories  string                      `json:"categories"`
+	IsActive    bool                        `json:"is_active"`
+	ImagesLinks []string                    `json:"images_links"`
+	LowStockThreshold uint                  `json:"low_stock_threshold"`
+}
+
+type RequestCreatePriceSchedule struct {
+	Price   uint       `json:"price" binding:"required,gte=1000,lte=100000000"`
+	StartAt time.Time  `json:"start_at" binding:"required"`
+	EndAt   *time.Time `json:"end_at,omitempty"`
+}
+
+type PriceScheduleResponse struct {
+	ID            uint                          `json:"id"`
+	ProductID     uint                          `json:"product_id"`
+	Price         uint                          `json:"price"`
+	OriginalPrice uint                          `json:"original_price"`
+	StartAt       time.Time                     `json:"start_at"`
+	EndAt         *time.Time                    `json:"end_at,omitempty"`
+	Status        constants.PriceScheduleStatus `json:"status"`
+	CreatedAt     time.Time                     `json:"created_at"`
+}
+
+type PriceHistoryResponse struct {
+	ID         uint                        `json:"id"`
+	OldPrice   uint                        `json:"old_price"`
+	NewPrice   uint                        `json:"new_price"`
+	Reason     constants.PriceChangeReason `json:"reason"`
+	ChangedBy  *uint                       `json:"changed_by,omitempty"`
+	ScheduleID *uint                       `json:"schedule_id,omitempty"`
+	CreatedAt  time.Time                   `json:"created_at"`
+}
+
+type GetPriceHistoryResponse struct {
+	TotalRecords int64                  `json:"total_records"`
+	Page         int                    `json:"page"`
+	Limit        int                    `json:"limit"`
+	History      []PriceHistoryResponse `json:"history"`
+}
+
+type RequestCreateCoupon struct {
+	Code          string                       `json:"code" binding:"required,min=3,max=50,alphanum"`
+	Description   string                       `json:"description,omitempty" binding:"omitempty,max=255"`
+	DiscountType  constants.CouponDiscountType `json:"discount_type" binding:"required,oneof=percentage fixed"`
+	DiscountValue uint                         `json:"discount_value" binding:"required,gt=0"`
+	MaxDiscount   uint                         `json:"max_discount,omitempty"`
+	FundedBy      constants.CouponFundedBy     `json:"funded_by,omitempty" binding:"omitempty,oneof=platform seller"`
+	SellerID      *uint                        `json:"seller_id,omitempty"`
+	MinSpend      uint                         `json:"min_spend,omitempty"`
+	UsageLimit    uint                         `json:"usage_limit,omitempty"`
+	PerUserLimit  uint                         `json:"per_user_limit,omitempty"`
+	StartsAt      *time.Time                   `json:"starts_at,omitempty"`
+	ExpiresAt     *time.Time                   `json:"expires_at,omitempty"`
+	ProductIDs    []uint                       `json:"product_ids,omitempty" binding:"omitempty,dive,gt=0"`
+	Categories    string                       `json:"categories,omitempty" binding:"omitempty,max=255"`
+}
+
+type RequestPatchCoupon struct {
+	IsActive   *bool      `json:"is_active,omitempty"`
+	UsageLimit *uint      `json:"usage_limit,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
+
+type CouponResponse struct {
+	ID            uint                         `json:"id"`
+	Code          string                       `json:"code"`
+	Description   string                       `json:"description"`
+	DiscountType  constants.CouponDiscountType `json:"discount_type"`
+	DiscountValue uint                         `json:"discount_value"`
+	MaxDiscount   uint                         `json:"max_discount"`
+	FundedBy      constants.CouponFundedBy     `json:"funded_by"`
+	SellerID      *uint                        `json:"seller_id,omitempty"`
+	MinSpend      uint                         `json:"min_spend"`
+	UsageLimit    uint                         `json:"usage_limit"`
+	PerUserLimit  uint                         `json:"per_user_limit"`
+	UsedCount     uint                         `json:"used_count"`
+	StartsAt      *time.Time                   `json:"starts_at,omitempty"`
+	ExpiresAt     *time.Time                   `json:"expires_at,omitempty"`
+	ProductIDs    []uint                       `json:"product_ids"`
+	Categories    []string                     `json:"categories"`
+	IsActive      bool                         `json:"is_active"`
+	CreatedAt     time.Time                    `json:"created_at"`
+}
+
+type GetCouponsResponse struct {
+	TotalRecords int64            `json:"total_records"`
+	Page         int              `json:"page"`
+	Limit        int              `json:"limit"`
+	Coupons      []CouponResponse `json:"coupons"`
+}
+
+type CouponRedemptionResponse struct {
+	ID             uint                             `json:"id"`
+	UserID         uint                             `json:"user_id"`
+	TransactionID  uint                             `json:"transaction_id"`
+	DiscountAmount uint                             `json:"discount_amount"`
+	Status         constants.CouponRedemptionStatus `json:"status"`
+	CreatedAt      time.Time                        `json:"created_at"`
+}
+
+type CouponUsageResponse struct {
+	Coupon        CouponResponse             `json:"coupon"`
+	TotalDiscount uint                       `json:"total_discount"`
+	AppliedCount  int64                      `json:"applied_count"`
+	ReleasedCount int64                      `json:"released_count"`
+	TotalRecords  int64                      `json:"total_records"`
+	Page          int                        `json:"page"`
+	Limit         int                        `json:"limit"`
+	Redemptions   []CouponRedemptionResponse `json:"redemptions"`
+}
+
+type RequestAddWishlist struct {
+	ProductID uint `json:"product_id" binding:"required,gt=0"`
+}
+
+type WishlistItemResponse struct {
+	ID                uint      `json:"id"`
+	ProductID         uint      `json:"product_id"`
+	Title             string    `json:"title"`
+	Price             uint      `json:"price"`
+	PreviousPrice     *uint     `json:"previous_price,omitempty"`
+	Stock             uint      `json:"stock"`
+	IsAvailable       bool      `json:"is_available"`
+	ImageURL          string    `json:"image_url,omitempty"`
+	RestockSubscribed bool      `json:"restock_subscribed"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type GetWishlistResponse struct {
+	TotalRecords int64                  `json:"total_records"`
+	Page         int                    `json:"page"`
+	Limit        int                    `json:"limit"`
+	Items        []WishlistItemResponse `json:"items"`
+}
+
+type RestockSubscriptionResponse struct {
+	ID         uint       `json:"id"`
+	ProductID  uint       `json:"product_id"`
+	Title      string     `json:"title"`
+	Stock      uint       `json:"stock"`
+	NotifiedAt *time.Time `json:"notified_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type RequestReserveItem struct {
+	ProductID uint `json:"product_id" binding:"required"`
+	Quantity  uint `json:"quantity" binding:"required,gt=0"`
+}
+
+type StockReservationResponse struct {
+	ID            uint                        `json:"id"`
+	ProductID     uint                        `json:"product_id"`
+	ProductTitle  string                      `json:"product_title,omitempty"`
+	Quantity      uint                        `json:"quantity"`
+	Status        constants.ReservationStatus `json:"status"`
+	ExpiresAt     time.Time                   `json:"expires_at"`
+	TransactionID *uint                       `json:"transaction_id,omitempty"`
+	CreatedAt     time.Time                   `json:"created_at"`
+}
+
+type RequestAdjustStock struct {
+	Change int    `json:"change" binding:"required,ne=0,gte=-10000,lte=10000"`
+	Note   string `json:"note" binding:"required,min=3,max=255"`
+}
+
+type InventoryMovementResponse struct {
+	ID            uint                     `json:"id"`
+	ProductID     uint                     `json:"product_id"`
+	Change        int                      `json:"change"`
+	StockBefore   uint                     `json:"stock_before"`
+	StockAfter    uint                     `json:"stock_after"`
+	Cause         constants.InventoryCause `json:"cause"`
+	Note          string                   `json:"note,omitempty"`
+	ActorID       *uint                    `json:"actor_id,omitempty"`
+	TransactionID *uint                    `json:"transaction_id,omitempty"`
+	CreatedAt     time.Time                `json:"created_at"`
+}
+
+type GetInventoryMovementsResponse struct {
+	TotalRecords int64                       `json:"total_records"`
+	Page         int                         `json:"page"`
+	Limit        int                         `json:"limit"`
+	Movements    []InventoryMovementResponse `json:"movements"`
+}
+
+type RequestAddProductImages struct {
+	ImagesLinks []string `form:"images_links" json:"images_links,omitempty" binding:"omitempty,dive,url"`
+	AltText     string   `form:"alt_text" json:"alt_text,omitempty" binding:"omitempty,max=255"`
+}
+
+type RequestPatchProductImage struct {
+	AltText   *string `json:"alt_text,omitempty" binding:"omitempty,max=255"`
+	IsPrimary *bool   `json:"is_primary,omitempty"`
+}
+
+type RequestReorderProductImages struct {
+	ImageIDs []uint `json:"image_ids" binding:"required,min=1"`
+}
@@ -38,5 +38,5 @@ type SupportMessageResponse struct {
 type RequestReplyTicket struct {
 	Content     string                    `json:"content" binding:"required_without=FileURL,max=1000"`
 	MessageType constants.ChatMessageType `json:"message_type" binding:"required,oneof=text image file"`
-	FileURL string `json:"file_url,omitempty" binding:"omitempty,url,required_if=MessageType image required_if=MessageType file"`
+	FileURL string `json:"file_url,omitempty" binding:"omitempty,uri,required_if=MessageType image required_if=MessageType file"`
 }
\ No newline at end of file
@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"time"
+
+	"portolio-backend/configs/constants"
+)
+
+type RequestCreateWebhook struct {
+	URL         string                `json:"url" binding:"required,url,max=500"`
+	Description string                `json:"description" binding:"max=255"`
+	EventTypes  []constants.EventType `json:"event_types" binding:"required,min=1,max=10"`
+}
+
+type RequestUpdateWebhook struct {
+	URL         *string               `json:"url" binding:"omitempty,url,max=500"`
+	Description *string               `json:"description" binding:"omitempty,max=255"`
+	EventTypes  []constants.EventType `json:"event_types" binding:"omitempty,min=1,max=10"`
+	IsActive    *bool                 `json:"is_active"`
+}
+
+type WebhookResponse struct {
+	ID          uint                  `json:"id"`
+	URL         string                `json:"url"`
+	Description string                `json:"description"`
+	EventTypes  []constants.EventType `json:"event_types"`
+	IsActive    bool                  `json:"is_active"`
+	// Secret hanya diisi saat webhook dibuat atau secret diganti
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID            uint                            `json:"id"`
+	WebhookID     uint                            `json:"webhook_id"`
+	EventID       *uint                           `json:"event_id,omitempty"`
+	EventType     constants.EventType             `json:"event_type"`
+	Payload       map[string]interface{}          `json:"payload"`
+	Status        constants.WebhookDeliveryStatus `json:"status"`
+	Attempts      int                             `json:"attempts"`
+	NextAttemptAt time.Time                       `json:"next_attempt_at"`
+	ResponseCode  int                             `json:"response_code"`
+	ResponseBody  string                          `json:"response_body,omitempty"`
+	DurationMs    int64                           `json:"duration_ms"`
+	LastError     string                          `json:"last_error,omitempty"`
+	DeliveredAt   *time.Time                      `json:"delivered_at,omitempty"`
+	CreatedAt     time.Time                       `json:"created_at"`
+}
+
+type GetWebhookDeliveriesResponse struct {
+	TotalRecords int64                     `json:"total_records"`
+	Page         int                       `json:"page"`
+	Limit        int                       `json:"limit"`
+	Deliveries   []WebhookDeliveryResponse `json:"deliveries"`
+}
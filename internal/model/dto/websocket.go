@@ -21,14 +21,45 @@ type ChatMessageResponse struct {
 	MessageType   constants.ChatMessageType `json:"message_type"`
 	Content       string                    `json:"content"`
 	FileURL       string                    `json:"file_url,omitempty"`
+	IsRead        bool                      `json:"is_read"`
+	ReadAt        *time.Time                `json:"read_at,omitempty"`
 	CreatedAt     time.Time                 `json:"created_at"`
 }
 
+// ChatFrame membaca field type dari frame WebSocket chat. Frame tanpa type adalah pesan
+// baru (RequestSendMessage); type "mark_read" menandai pesan sampai UpToID sudah dibaca.
+type ChatFrame struct {
+	Type   string `json:"type"`
+	UpToID uint   `json:"up_to_id"`
+}
+
+type RequestMarkChatRead struct {
+	// UpToID adalah ID pesan terakhir yang sudah dibaca; 0 berarti semua pesan
+	UpToID uint `json:"up_to_id"`
+}
+
+type GetChatMessagesResponse struct {
+	TransactionID uint                  `json:"transaction_id"`
+	Messages      []ChatMessageResponse `json:"messages"`
+	HasMore       bool                  `json:"has_more"`
+	NextCursor    *uint                 `json:"next_cursor,omitempty"`
+	UnreadCount   int64                 `json:"unread_count"`
+}
+
+type ChatReadReceiptWebSocketResponse struct {
+	Type          string    `json:"type"` // "chat_read" untuk lawan bicara, "chat_read_ack" untuk pembaca
+	TransactionID uint      `json:"transaction_id"`
+	ReaderID      uint      `json:"reader_id"`
+	MessageIDs    []uint    `json:"message_ids"`
+	ReadAt        time.Time `json:"read_at"`
+}
+
 type NotificationResponse struct {
 	ID        uint                        `json:"id"`
 	UserID    uint                        `json:"user_id"`
 	Type      constants.NotificationType  `json:"type"`
 	Message   string                      `json:"message"`
+	Items     []string                    `json:"items,omitempty"`
 	RelatedID *uint                       `json:"related_id,omitempty"`
 	CreatedAt time.Time                   `json:"created_at"`
 	IsRead    bool                        `json:"is_read"`
@@ -0,0 +1,115 @@
+package notify
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/i18n"
+	"portolio-backend/internal/model/db"
+)
+
+// maxDigestItems membatasi jumlah pesan yang ikut ditampilkan di dalam satu ringkasan
+const maxDigestItems = 10
+
+// nextDigestAt menghitung waktu pengiriman ringkasan berikutnya: awal jam berikutnya untuk
+// hourly, atau constants.DefaultDigestDailyHour di zona waktu pengguna untuk daily
+func nextDigestAt(frequency constants.DigestFrequency, now time.Time, loc *time.Location) time.Time {
+	local := now.In(loc)
+	if frequency == constants.DigestHourly {
+		return time.Date(local.Year(), local.Month(), local.Day(), local.Hour()+1, 0, 0, 0, loc)
+	}
+	next := time.Date(local.Year(), local.Month(), local.Day(), constants.DefaultDigestDailyHour, 0, 0, 0, loc)
+	if !next.After(local) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// SendDigest menggabungkan item ringkasan milik satu pengguna dan satu tipe menjadi satu
+// notifikasi. Satu item dikirim sebagai notifikasi aslinya. Pemanggil bertanggung jawab
+// menghapus item setelahnya di transaksi yang sama.
+func SendDigest(tx *gorm.DB, userID uint, notifType constants.NotificationType, frequency constants.DigestFrequency, items []db.NotificationDigestItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	prefs, err := LoadPreferences(tx, userID)
+	if err != nil {
+		return err
+	}
+
+	if len(items) == 1 {
+		return dispatch(tx, &db.Notification{
+			UserID:        userID,
+			Type:          notifType,
+			Message:       items[0].Message,
+			MessageKey:    items[0].MessageKey,
+			MessageParams: items[0].MessageParams,
+			RelatedID:     items[0].RelatedID,
+		}, prefs, "")
+	}
+
+	summaries := make([]interface{}, 0, maxDigestItems)
+	for _, item := range items {
+		if len(summaries) == maxDigestItems {
+			break
+		}
+		summaries = append(summaries, map[string]interface{}{
+			"key":     item.MessageKey,
+			"params":  map[string]interface{}(item.MessageParams),
+			"message": item.Message,
+		})
+	}
+
+	key := "notification.digest_daily"
+	if frequency == constants.DigestHourly {
+		key = "notification.digest_hourly"
+	}
+	params := db.JSONB{
+		"count": len(items),
+		"type":  i18n.Ref("notification_type."+string(notifType), nil),
+		"items": summaries,
+	}
+	return dispatch(tx, &db.Notification{
+		UserID:        userID,
+		Type:          notifType,
+		Message:       i18n.T(constants.DefaultLanguage, key, i18n.Params(params)),
+		MessageKey:    key,
+		MessageParams: params,
+	}, prefs, "")
+}
+
+// renderDigestItems merender daftar pesan yang tersimpan di parameter "items" notifikasi
+// ringkasan. Notifikasi biasa mengembalikan nil.
+func renderDigestItems(notification db.Notification, lang constants.Language) []string {
+	var items []interface{}
+	switch v := notification.MessageParams["items"].(type) {
+	case []interface{}:
+		items = v
+	case []map[string]interface{}:
+		for _, item := range v {
+			items = append(items, item)
+		}
+	default:
+		return nil
+	}
+
+	rendered := make([]string, 0, len(items))
+	for _, raw := range items {
+		item, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		key, _ := item["key"].(string)
+		message, _ := item["message"].(string)
+		if key == "" {
+			rendered = append(rendered, i18n.Translate(lang, message))
+			continue
+		}
+		params, _ := item["params"].(map[string]interface{})
+		rendered = append(rendered, i18n.T(lang, key, params))
+	}
+	return rendered
+}
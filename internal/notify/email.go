@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/i18n"
+	"portolio-backend/internal/mailer"
+	"portolio-backend/internal/model/db"
+)
+
+// emailTemplates memetakan tipe notifikasi ke template email. Tipe lain memakai
+// mailer.DefaultTemplate.
+var emailTemplates = map[constants.NotificationType]string{
+	constants.NotifTypePurchase: "purchase",
+	constants.NotifTypeSale:     "sale",
+	constants.NotifTypeShipment: "shipment",
+	constants.NotifTypeTopUp:    "topup",
+	constants.NotifTypeWithdraw: "withdraw",
+	constants.NotifTypeAccount:  "account_status",
+	constants.NotifTypeSupport:  "support_reply",
+}
+
+// EmailDeliverer menulis email ke tabel EmailOutbox di dalam transaksi pemanggil. Email
+// baru dikirim oleh jobs.RunEmailOutbox setelah transaksi berhasil di-commit, sehingga
+// transaksi yang dibatalkan tidak pernah menghasilkan email. Penulisan dibungkus savepoint
+// agar kegagalannya tidak membatalkan transaksi pemanggil.
+type EmailDeliverer struct {
+	AppName string
+	AppURL  string
+}
+
+func (d EmailDeliverer) Deliver(tx *gorm.DB, notification db.Notification) error {
+	return tx.Transaction(func(tx *gorm.DB) error {
+		return d.enqueue(tx, notification)
+	})
+}
+
+func (d EmailDeliverer) enqueue(tx *gorm.DB, notification db.Notification) error {
+	var user db.User
+	if err := tx.Select("id", "full_name", "email", "language").First(&user, notification.UserID).Error; err != nil {
+		return err
+	}
+	lang := i18n.Resolve(user.Language, "")
+
+	templateName, ok := emailTemplates[notification.Type]
+	if !ok {
+		templateName = mailer.DefaultTemplate
+	}
+	data := mailer.TemplateData{
+		AppName:       d.AppName,
+		RecipientName: user.FullName,
+		Message:       Render(notification, lang),
+		RelatedID:     notification.RelatedID,
+	}
+	if d.AppURL != "" {
+		data.ActionURL = d.AppURL
+		data.ActionText = i18n.T(lang, "email.open_app", i18n.Params{"app": d.AppName})
+	}
+
+	subject, html, text, err := mailer.Render(lang, templateName, data)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&db.EmailOutbox{
+		UserID:        &user.ID,
+		ToAddress:     user.Email,
+		Template:      templateName,
+		Subject:       subject,
+		HTMLBody:      html,
+		TextBody:      text,
+		Status:        constants.EmailPending,
+		NextAttemptAt: time.Now(),
+	}).Error
+}
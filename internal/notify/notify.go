@@ -0,0 +1,184 @@
+package notify
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/i18n"
+	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/model/dto"
+	"portolio-backend/internal/util"
+)
+
+// Deliverer mengirim notifikasi melalui satu channel di luar kotak masuk in-app.
+// Notification.ID bernilai 0 jika pengguna menonaktifkan channel in-app untuk tipe tersebut.
+// Deliverer setelah commit menerima koneksi database biasa, bukan transaksi pemanggil.
+type Deliverer interface {
+	Deliver(tx *gorm.DB, notification db.Notification) error
+}
+
+// DelivererFunc mengubah fungsi biasa menjadi Deliverer
+type DelivererFunc func(tx *gorm.DB, notification db.Notification) error
+
+func (f DelivererFunc) Deliver(tx *gorm.DB, notification db.Notification) error {
+	return f(tx, notification)
+}
+
+type registration struct {
+	deliverer   Deliverer
+	afterCommit bool
+}
+
+var (
+	deliverersMu sync.RWMutex
+	deliverers   = map[constants.NotificationChannel]registration{
+		constants.NotificationChannelWebsocket: {deliverer: DelivererFunc(deliverWebsocket), afterCommit: true},
+	}
+)
+
+// quietChannels adalah channel yang ditahan selama jam tenang. Notifikasi tetap tersimpan
+// di kotak masuk sehingga dapat dibaca setelahnya.
+var quietChannels = map[constants.NotificationChannel]bool{
+	constants.NotificationChannelWebsocket: true,
+	constants.NotificationChannelWebPush:   true,
+}
+
+// Register memasang Deliverer yang dijalankan di dalam transaksi pemanggil, misalnya
+// penulisan antrean email, sehingga ikut batal bila transaksi di-rollback. Dipanggil dari
+// main saat layanan pengiriman tersedia. Channel tanpa Deliverer dilewati.
+func Register(channel constants.NotificationChannel, deliverer Deliverer) {
+	deliverersMu.Lock()
+	defer deliverersMu.Unlock()
+	deliverers[channel] = registration{deliverer: deliverer}
+}
+
+// RegisterAfterCommit memasang Deliverer yang mengirim ke luar database (WebSocket, web
+// push). Di dalam Transaction pengirimannya ditunda sampai transaksi berhasil di-commit.
+func RegisterAfterCommit(channel constants.NotificationChannel, deliverer Deliverer) {
+	deliverersMu.Lock()
+	defer deliverersMu.Unlock()
+	deliverers[channel] = registration{deliverer: deliverer, afterCommit: true}
+}
+
+// Send adalah satu-satunya jalur pembuatan notifikasi. Notifikasi sebaiknya diisi
+// MessageKey dan MessageParams dari katalog i18n; Message dirender dalam bahasa bawaan
+// bila kosong. Preferensi pengguna diperiksa lebih dulu: tipe yang diatur sebagai
+// ringkasan ditampung di NotificationDigestItem dan dikirim oleh SendDigest. Selain itu
+// notifikasi disimpan hanya jika channel in-app aktif, lalu dikirim ke setiap channel lain
+// yang aktif dan tidak sedang ditahan jam tenang. Bila tx berasal dari Transaction,
+// pengiriman ke luar database diantrekan dan baru dijalankan setelah commit dengan ID dan
+// waktu pembuatan yang sudah tersimpan. Kegagalan channel hanya dicatat di log agar tidak
+// membatalkan transaksi pemanggil, sedangkan kegagalan menyimpan notifikasi dikembalikan
+// sebagai error.
+func Send(tx *gorm.DB, notification *db.Notification) error {
+	return send(tx, notification, "")
+}
+
+// send menjalankan Send tanpa mengirim ke channel skip, yaitu channel yang ditangani
+// subscriber event tersendiri
+func send(tx *gorm.DB, notification *db.Notification, skip constants.NotificationChannel) error {
+	if notification.Message == "" && notification.MessageKey != "" {
+		notification.Message = i18n.T(constants.DefaultLanguage, notification.MessageKey, i18n.Params(notification.MessageParams))
+	}
+
+	prefs, err := LoadPreferences(tx, notification.UserID)
+	if err != nil {
+		return err
+	}
+
+	if frequency := prefs.Digest(notification.Type); frequency != constants.DigestImmediate {
+		return tx.Create(&db.NotificationDigestItem{
+			UserID:        notification.UserID,
+			Type:          notification.Type,
+			Frequency:     frequency,
+			Message:       notification.Message,
+			MessageKey:    notification.MessageKey,
+			MessageParams: notification.MessageParams,
+			RelatedID:     notification.RelatedID,
+			DeliverAt:     nextDigestAt(frequency, time.Now(), prefs.Location()),
+		}).Error
+	}
+	return dispatch(tx, notification, prefs, skip)
+}
+
+// dispatch menyimpan dan mengirim notifikasi ke channel yang aktif selain skip tanpa
+// memeriksa ringkasan
+func dispatch(tx *gorm.DB, notification *db.Notification, prefs Preferences, skip constants.NotificationChannel) error {
+	if prefs.Enabled(notification.Type, constants.NotificationChannelInApp) {
+		if err := tx.Create(notification).Error; err != nil {
+			return err
+		}
+	} else if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = time.Now()
+	}
+
+	quiet := prefs.InQuietHours(time.Now()) && notification.Type != constants.NotifTypeAccount
+	queue := queueFrom(tx)
+	if queue == nil && inTransaction(tx) {
+		log.Printf("⚠️ Notifikasi %s untuk user %d dikirim dari transaksi di luar notify.Transaction, push tidak menunggu commit", notification.Type, notification.UserID)
+	}
+
+	deliverersMu.RLock()
+	defer deliverersMu.RUnlock()
+	for _, channel := range constants.NotificationChannels {
+		reg, ok := deliverers[channel]
+		if !ok || channel == skip || !prefs.Enabled(notification.Type, channel) {
+			continue
+		}
+		if quiet && quietChannels[channel] {
+			continue
+		}
+		if reg.afterCommit && queue != nil {
+			queue.add(channel, reg.deliverer, *notification)
+			continue
+		}
+		deliver(tx, channel, reg.deliverer, *notification)
+	}
+	return nil
+}
+
+func deliver(tx *gorm.DB, channel constants.NotificationChannel, deliverer Deliverer, notification db.Notification) {
+	if err := deliverer.Deliver(tx, notification); err != nil {
+		log.Printf("❌ Gagal mengirim notifikasi %s ke user %d melalui %s: %v", notification.Type, notification.UserID, channel, err)
+	}
+}
+
+// Render menyusun pesan notifikasi dalam bahasa lang. Notifikasi lama tanpa MessageKey
+// diterjemahkan dari Message bila teksnya ada di katalog.
+func Render(notification db.Notification, lang constants.Language) string {
+	if notification.MessageKey == "" {
+		return i18n.Translate(lang, notification.Message)
+	}
+	return i18n.T(lang, notification.MessageKey, i18n.Params(notification.MessageParams))
+}
+
+// UserLanguage mengembalikan bahasa pilihan pengguna di profil, atau bahasa bawaan bila
+// belum diatur. Dipakai untuk pengiriman yang tidak memiliki header Accept-Language.
+func UserLanguage(tx *gorm.DB, userID uint) constants.Language {
+	var language constants.Language
+	tx.Model(&db.User{}).Select("language").Where("id = ?", userID).Scan(&language)
+	return i18n.Resolve(language, "")
+}
+
+// Response mengubah notifikasi menjadi bentuk respons API dan WebSocket dalam bahasa lang
+func Response(notification db.Notification, lang constants.Language) dto.NotificationResponse {
+	return dto.NotificationResponse{
+		ID:        notification.ID,
+		UserID:    notification.UserID,
+		Type:      notification.Type,
+		Message:   Render(notification, lang),
+		Items:     renderDigestItems(notification, lang),
+		RelatedID: notification.RelatedID,
+		CreatedAt: notification.CreatedAt,
+		IsRead:    notification.IsRead,
+	}
+}
+
+func deliverWebsocket(tx *gorm.DB, notification db.Notification) error {
+	util.SendNotificationToUser(notification.UserID, Response(notification, UserLanguage(tx, notification.UserID)))
+	return nil
+}
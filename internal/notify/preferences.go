@@ -0,0 +1,246 @@
+package notify
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+)
+
+// Preferences adalah preferensi notifikasi seorang pengguna yang sudah digabung dengan
+// nilai bawaan.
+type Preferences struct {
+	overrides map[constants.NotificationType]map[constants.NotificationChannel]bool
+	digests   map[constants.NotificationType]constants.DigestFrequency
+	Setting   db.NotificationSetting
+}
+
+// DefaultEnabled mengembalikan nilai bawaan sebuah channel untuk tipe notifikasi. In-app,
+// WebSocket, dan web push aktif untuk semua tipe; email hanya untuk transaksi, saldo, akun,
+// dan tiket dukungan.
+func DefaultEnabled(notifType constants.NotificationType, channel constants.NotificationChannel) bool {
+	if channel != constants.NotificationChannelEmail {
+		return true
+	}
+	switch notifType {
+	case constants.NotifTypePurchase, constants.NotifTypeSale, constants.NotifTypeShipment,
+		constants.NotifTypeTopUp, constants.NotifTypeWithdraw, constants.NotifTypeAccount, constants.NotifTypeSupport:
+		return true
+	}
+	return false
+}
+
+// IsMandatory menandai kombinasi yang tidak boleh dimatikan pengguna. Notifikasi status akun
+// selalu disimpan di kotak masuk dan tidak ditahan oleh jam tenang.
+func IsMandatory(notifType constants.NotificationType, channel constants.NotificationChannel) bool {
+	return notifType == constants.NotifTypeAccount && channel == constants.NotificationChannelInApp
+}
+
+// Enabled memeriksa apakah tipe notifikasi dikirim melalui channel tersebut
+func (p Preferences) Enabled(notifType constants.NotificationType, channel constants.NotificationChannel) bool {
+	if IsMandatory(notifType, channel) {
+		return true
+	}
+	if enabled, ok := p.overrides[notifType][channel]; ok {
+		return enabled
+	}
+	return DefaultEnabled(notifType, channel)
+}
+
+// InQuietHours memeriksa apakah now berada di dalam jam tenang pengguna
+func (p Preferences) InQuietHours(now time.Time) bool {
+	if !p.Setting.QuietHoursEnabled {
+		return false
+	}
+	start, errStart := parseClock(p.Setting.QuietHoursStart)
+	end, errEnd := parseClock(p.Setting.QuietHoursEnd)
+	if errStart != nil || errEnd != nil || start == end {
+		return false
+	}
+
+	local := now.In(p.Location())
+	minute := local.Hour()*60 + local.Minute()
+
+	if start < end {
+		return minute >= start && minute < end
+	}
+	// Jam tenang melewati tengah malam, misalnya 22:00-07:00
+	return minute >= start || minute < end
+}
+
+// Location mengembalikan zona waktu pengguna untuk jam tenang dan ringkasan harian
+func (p Preferences) Location() *time.Location {
+	loc, err := time.LoadLocation(p.Setting.Timezone)
+	if err != nil || p.Setting.Timezone == "" {
+		loc, _ = time.LoadLocation(constants.DefaultNotificationTimezone)
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	return loc
+}
+
+// Digest mengembalikan frekuensi ringkasan untuk tipe notifikasi. Notifikasi status akun
+// selalu dikirim langsung.
+func (p Preferences) Digest(notifType constants.NotificationType) constants.DigestFrequency {
+	if notifType == constants.NotifTypeAccount {
+		return constants.DigestImmediate
+	}
+	if frequency, ok := p.digests[notifType]; ok {
+		return frequency
+	}
+	return constants.DigestImmediate
+}
+
+// Digests mengembalikan frekuensi ringkasan efektif untuk semua tipe
+func (p Preferences) Digests() map[constants.NotificationType]constants.DigestFrequency {
+	digests := make(map[constants.NotificationType]constants.DigestFrequency, len(constants.NotificationTypes))
+	for _, notifType := range constants.NotificationTypes {
+		digests[notifType] = p.Digest(notifType)
+	}
+	return digests
+}
+
+// Matrix mengembalikan preferensi efektif untuk semua tipe dan channel
+func (p Preferences) Matrix() map[constants.NotificationType]map[constants.NotificationChannel]bool {
+	matrix := make(map[constants.NotificationType]map[constants.NotificationChannel]bool, len(constants.NotificationTypes))
+	for _, notifType := range constants.NotificationTypes {
+		matrix[notifType] = make(map[constants.NotificationChannel]bool, len(constants.NotificationChannels))
+		for _, channel := range constants.NotificationChannels {
+			matrix[notifType][channel] = p.Enabled(notifType, channel)
+		}
+	}
+	return matrix
+}
+
+// LoadPreferences memuat preferensi, frekuensi ringkasan, dan jam tenang pengguna
+func LoadPreferences(tx *gorm.DB, userID uint) (Preferences, error) {
+	prefs := Preferences{
+		overrides: make(map[constants.NotificationType]map[constants.NotificationChannel]bool),
+		digests:   make(map[constants.NotificationType]constants.DigestFrequency),
+		Setting:   db.NotificationSetting{UserID: userID, Timezone: constants.DefaultNotificationTimezone},
+	}
+
+	var rows []db.NotificationPreference
+	if err := tx.Where("user_id = ?", userID).Find(&rows).Error; err != nil {
+		return prefs, err
+	}
+	for _, row := range rows {
+		if prefs.overrides[row.Type] == nil {
+			prefs.overrides[row.Type] = make(map[constants.NotificationChannel]bool)
+		}
+		prefs.overrides[row.Type][row.Channel] = row.Enabled
+	}
+
+	var digestRows []db.NotificationDigestPreference
+	if err := tx.Where("user_id = ?", userID).Find(&digestRows).Error; err != nil {
+		return prefs, err
+	}
+	for _, row := range digestRows {
+		prefs.digests[row.Type] = row.Frequency
+	}
+
+	var setting db.NotificationSetting
+	result := tx.Where("user_id = ?", userID).Limit(1).Find(&setting)
+	if result.Error != nil {
+		return prefs, result.Error
+	}
+	if result.RowsAffected > 0 {
+		prefs.Setting = setting
+	}
+	return prefs, nil
+}
+
+// SetPreference menyimpan pilihan pengguna untuk satu tipe dan channel
+func SetPreference(tx *gorm.DB, userID uint, notifType constants.NotificationType, channel constants.NotificationChannel, enabled bool) error {
+	if !slices.Contains(constants.NotificationTypes, notifType) {
+		return fmt.Errorf(constants.ErrMsgInvalidNotificationType)
+	}
+	if !slices.Contains(constants.NotificationChannels, channel) {
+		return fmt.Errorf(constants.ErrMsgInvalidNotificationChannel)
+	}
+	if !enabled && IsMandatory(notifType, channel) {
+		return fmt.Errorf(constants.ErrMsgNotificationChannelRequired)
+	}
+
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}, {Name: "channel"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"enabled": enabled, "updated_at": time.Now(), "deleted_at": nil}),
+	}).Create(&db.NotificationPreference{
+		UserID:  userID,
+		Type:    notifType,
+		Channel: channel,
+		Enabled: enabled,
+	}).Error
+}
+
+// SetDigest menyimpan frekuensi ringkasan pengguna untuk satu tipe notifikasi
+func SetDigest(tx *gorm.DB, userID uint, notifType constants.NotificationType, frequency constants.DigestFrequency) error {
+	if !slices.Contains(constants.NotificationTypes, notifType) {
+		return fmt.Errorf(constants.ErrMsgInvalidNotificationType)
+	}
+	if !slices.Contains(constants.DigestFrequencies, frequency) {
+		return fmt.Errorf(constants.ErrMsgInvalidDigestFrequency)
+	}
+	if notifType == constants.NotifTypeAccount && frequency != constants.DigestImmediate {
+		return fmt.Errorf(constants.ErrMsgDigestNotAllowed)
+	}
+
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"frequency": frequency, "updated_at": time.Now(), "deleted_at": nil}),
+	}).Create(&db.NotificationDigestPreference{
+		UserID:    userID,
+		Type:      notifType,
+		Frequency: frequency,
+	}).Error
+}
+
+// SetQuietHours menyimpan jam tenang pengguna
+func SetQuietHours(tx *gorm.DB, userID uint, enabled bool, start, end, timezone string) error {
+	if timezone == "" {
+		timezone = constants.DefaultNotificationTimezone
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return fmt.Errorf(constants.ErrMsgInvalidQuietHours)
+	}
+	if enabled {
+		startMinute, errStart := parseClock(start)
+		endMinute, errEnd := parseClock(end)
+		if errStart != nil || errEnd != nil || startMinute == endMinute {
+			return fmt.Errorf(constants.ErrMsgInvalidQuietHours)
+		}
+	}
+
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"quiet_hours_enabled": enabled,
+			"quiet_hours_start":   start,
+			"quiet_hours_end":     end,
+			"timezone":            timezone,
+			"updated_at":          time.Now(),
+			"deleted_at":          nil,
+		}),
+	}).Create(&db.NotificationSetting{
+		UserID:            userID,
+		QuietHoursEnabled: enabled,
+		QuietHoursStart:   start,
+		QuietHoursEnd:     end,
+		Timezone:          timezone,
+	}).Error
+}
+
+// parseClock mengubah "HH:MM" menjadi menit sejak tengah malam
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,75 @@
+package notify
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+)
+
+type queueKey struct{}
+
+type queuedDelivery struct {
+	channel      constants.NotificationChannel
+	deliverer    Deliverer
+	notification db.Notification
+}
+
+// queue menampung pengiriman setelah commit untuk satu transaksi
+type queue struct {
+	items []queuedDelivery
+}
+
+func (q *queue) add(channel constants.NotificationChannel, deliverer Deliverer, notification db.Notification) {
+	q.items = append(q.items, queuedDelivery{channel: channel, deliverer: deliverer, notification: notification})
+}
+
+func (q *queue) flush(dbConn *gorm.DB) {
+	items := q.items
+	q.items = nil
+	for _, item := range items {
+		deliver(dbConn, item.channel, item.deliverer, item.notification)
+	}
+}
+
+// Transaction menjalankan fc di dalam transaksi database seperti dbConn.Transaction, lalu
+// mengirim push notifikasi yang diantrekan Send hanya jika transaksi berhasil di-commit.
+// Bila transaksi di-rollback tidak ada push yang terkirim. Pemanggilan bersarang memakai
+// savepoint dan antrean transaksi terluar; antrean savepoint yang gagal dibuang.
+func Transaction(dbConn *gorm.DB, fc func(tx *gorm.DB) error) error {
+	if outer := queueFrom(dbConn); outer != nil {
+		mark := len(outer.items)
+		if err := dbConn.Transaction(fc); err != nil {
+			outer.items = outer.items[:mark]
+			return err
+		}
+		return nil
+	}
+
+	ctx := dbConn.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	q := &queue{}
+	if err := dbConn.WithContext(context.WithValue(ctx, queueKey{}, q)).Transaction(fc); err != nil {
+		return err
+	}
+	q.flush(dbConn)
+	return nil
+}
+
+func queueFrom(tx *gorm.DB) *queue {
+	if tx == nil || tx.Statement == nil || tx.Statement.Context == nil {
+		return nil
+	}
+	q, _ := tx.Statement.Context.Value(queueKey{}).(*queue)
+	return q
+}
+
+// inTransaction memeriksa apakah tx sedang berada di dalam transaksi database
+func inTransaction(tx *gorm.DB) bool {
+	_, ok := tx.Statement.ConnPool.(gorm.TxCommitter)
+	return ok
+}
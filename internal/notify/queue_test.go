@@ -0,0 +1,164 @@
+package notify
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dbConn, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger:                                   logger.Default.LogMode(logger.Silent),
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	if err != nil {
+		t.Fatalf("membuka database: %v", err)
+	}
+	if err := dbConn.AutoMigrate(&db.User{}, &db.Notification{}, &db.NotificationPreference{},
+		&db.NotificationSetting{}, &db.NotificationDigestPreference{}, &db.NotificationDigestItem{}, &db.PushSubscription{},
+		&db.Product{}, &db.TransactionHistory{}, &db.SupportTicket{}, &db.OutboxEvent{}, &db.EventReceipt{}, &db.EmailOutbox{}); err != nil {
+		t.Fatalf("migrasi: %v", err)
+	}
+	return dbConn
+}
+
+// recordingDeliverer mencatat notifikasi yang dikirim beserta apakah notifikasi tersebut
+// sudah terlihat dari koneksi database di luar transaksi pemanggil
+type recordingDeliverer struct {
+	mu        sync.Mutex
+	delivered []db.Notification
+	committed []bool
+}
+
+func (d *recordingDeliverer) Deliver(tx *gorm.DB, notification db.Notification) error {
+	var count int64
+	tx.Model(&db.Notification{}).Where("id = ?", notification.ID).Count(&count)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.delivered = append(d.delivered, notification)
+	d.committed = append(d.committed, count == 1)
+	return nil
+}
+
+// useDeliverers mengganti seluruh Deliverer terdaftar dengan satu Deliverer setelah commit
+// di channel web push selama test berjalan
+func useDeliverers(t *testing.T, deliverer Deliverer) {
+	deliverersMu.Lock()
+	previous := deliverers
+	deliverers = map[constants.NotificationChannel]registration{}
+	deliverersMu.Unlock()
+	t.Cleanup(func() {
+		deliverersMu.Lock()
+		deliverers = previous
+		deliverersMu.Unlock()
+	})
+	RegisterAfterCommit(constants.NotificationChannelWebPush, deliverer)
+}
+
+func newSaleNotification(userID uint) db.Notification {
+	return db.Notification{UserID: userID, Type: constants.NotifTypeSale, Message: "Produk Anda terjual."}
+}
+
+func TestTransactionRollbackDeliversNothing(t *testing.T) {
+	dbConn := openTestDB(t)
+	deliverer := &recordingDeliverer{}
+	useDeliverers(t, deliverer)
+
+	rollback := errors.New("pembelian gagal")
+	err := Transaction(dbConn, func(tx *gorm.DB) error {
+		notification := newSaleNotification(7)
+		if err := Send(tx, &notification); err != nil {
+			return err
+		}
+		return rollback
+	})
+	if !errors.Is(err, rollback) {
+		t.Fatalf("err = %v, ingin %v", err, rollback)
+	}
+
+	if len(deliverer.delivered) != 0 {
+		t.Fatalf("terkirim %d push setelah rollback, ingin 0", len(deliverer.delivered))
+	}
+	var count int64
+	dbConn.Model(&db.Notification{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("tersimpan %d notifikasi setelah rollback, ingin 0", count)
+	}
+}
+
+func TestTransactionCommitDeliversPersistedNotification(t *testing.T) {
+	dbConn := openTestDB(t)
+	deliverer := &recordingDeliverer{}
+	useDeliverers(t, deliverer)
+
+	err := Transaction(dbConn, func(tx *gorm.DB) error {
+		notification := newSaleNotification(7)
+		if err := Send(tx, &notification); err != nil {
+			return err
+		}
+		if len(deliverer.delivered) != 0 {
+			t.Error("push terkirim sebelum transaksi di-commit")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(deliverer.delivered) != 1 {
+		t.Fatalf("terkirim %d push, ingin 1", len(deliverer.delivered))
+	}
+	var stored db.Notification
+	if err := dbConn.First(&stored).Error; err != nil {
+		t.Fatal(err)
+	}
+	delivered := deliverer.delivered[0]
+	if delivered.ID == 0 || delivered.ID != stored.ID {
+		t.Errorf("ID push = %d, ingin ID tersimpan %d", delivered.ID, stored.ID)
+	}
+	if delivered.CreatedAt.IsZero() || !delivered.CreatedAt.Equal(stored.CreatedAt) {
+		t.Errorf("CreatedAt push = %v, ingin %v", delivered.CreatedAt, stored.CreatedAt)
+	}
+	if !deliverer.committed[0] {
+		t.Error("notifikasi belum terlihat di luar transaksi saat push dikirim")
+	}
+}
+
+func TestNestedTransactionDropsFailedSavepointQueue(t *testing.T) {
+	dbConn := openTestDB(t)
+	deliverer := &recordingDeliverer{}
+	useDeliverers(t, deliverer)
+
+	err := Transaction(dbConn, func(tx *gorm.DB) error {
+		kept := newSaleNotification(7)
+		if err := Send(tx, &kept); err != nil {
+			return err
+		}
+		// Savepoint yang gagal tidak membatalkan transaksi terluar
+		_ = Transaction(tx, func(tx *gorm.DB) error {
+			dropped := newSaleNotification(8)
+			if err := Send(tx, &dropped); err != nil {
+				return err
+			}
+			return errors.New("savepoint gagal")
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(deliverer.delivered) != 1 || deliverer.delivered[0].UserID != 7 {
+		t.Fatalf("push terkirim = %+v, ingin hanya untuk user 7", deliverer.delivered)
+	}
+}
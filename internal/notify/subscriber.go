@@ -0,0 +1,263 @@
+package notify
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/events"
+	"portolio-backend/internal/model/db"
+)
+
+// Nama subscriber notifikasi dan email di outbox event
+const (
+	SubscriberName      = "notification"
+	EmailSubscriberName = "email"
+)
+
+// Subscribe mendaftarkan subscriber yang membuat notifikasi untuk pengguna yang terdampak
+// event domain dan mengirimkannya ke seluruh channel aktif selain email. Email dikirim oleh
+// subscriber dari SubscribeEmail sehingga kegagalan salah satunya tidak mengulang yang lain.
+func Subscribe(dbConn *gorm.DB) {
+	events.Subscribe(SubscriberName, func(ctx context.Context, event events.Event) error {
+		return Transaction(dbConn.WithContext(ctx), func(tx *gorm.DB) error {
+			notifications, err := EventNotifications(tx, event)
+			if err != nil || len(notifications) == 0 {
+				return err
+			}
+			if claimed, err := events.Claim(tx, SubscriberName, event.ID); err != nil || !claimed {
+				return err
+			}
+			for i := range notifications {
+				if err := send(tx, &notifications[i], constants.NotificationChannelEmail); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// SubscribeEmail mendaftarkan subscriber yang mengantrekan email untuk notifikasi event
+// domain memakai deliverer. Tipe yang diatur sebagai ringkasan dilewati karena emailnya
+// dikirim bersama ringkasan oleh SendDigest.
+func SubscribeEmail(dbConn *gorm.DB, deliverer EmailDeliverer) {
+	events.Subscribe(EmailSubscriberName, func(ctx context.Context, event events.Event) error {
+		return dbConn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			notifications, err := EventNotifications(tx, event)
+			if err != nil || len(notifications) == 0 {
+				return err
+			}
+			if claimed, err := events.Claim(tx, EmailSubscriberName, event.ID); err != nil || !claimed {
+				return err
+			}
+			for _, notification := range notifications {
+				prefs, err := LoadPreferences(tx, notification.UserID)
+				if err != nil {
+					return err
+				}
+				if prefs.Digest(notification.Type) != constants.DigestImmediate || !prefs.Enabled(notification.Type, constants.NotificationChannelEmail) {
+					continue
+				}
+				// Akun yang sudah dihapus tidak lagi menerima email
+				if err := deliverer.enqueue(tx, notification); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// EventNotifications menyusun notifikasi untuk pengguna yang terdampak event. Event yang
+// tidak menghasilkan notifikasi mengembalikan nil.
+func EventNotifications(tx *gorm.DB, event events.Event) ([]db.Notification, error) {
+	switch event.AggregateType {
+	case events.AggregateTransaction:
+		return transactionNotifications(tx, event)
+	case events.AggregateUser:
+		return userNotifications(event), nil
+	case events.AggregateSupportTicket:
+		return supportTicketNotifications(tx, event)
+	}
+	return nil, nil
+}
+
+func transactionNotifications(tx *gorm.DB, event events.Event) ([]db.Notification, error) {
+	var trx db.TransactionHistory
+	if err := tx.Preload("Product", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		First(&trx, event.AggregateID).Error; err != nil {
+		return nil, err
+	}
+	buyerID, sellerID := trx.UserID, trx.Product.UserID
+	byTransaction := db.JSONB{"product": trx.Product.Title, "transaction_id": trx.ID}
+	byProduct := db.JSONB{"product": trx.Product.Title, "product_id": trx.ProductID}
+	reason := events.PayloadString(event.Payload, "reason")
+
+	switch event.Type {
+	case constants.EventPurchaseCreated:
+		var buyerName string
+		if err := tx.Model(&db.User{}).Select("full_name").Where("id = ?", buyerID).Scan(&buyerName).Error; err != nil {
+			return nil, err
+		}
+		return []db.Notification{
+			eventNotification(sellerID, constants.NotifTypeSale, "notification.purchase_seller", db.JSONB{"product": trx.Product.Title, "buyer": buyerName, "quantity": trx.Quantity}, trx.ID),
+			eventNotification(buyerID, constants.NotifTypePurchase, "notification.purchase_buyer", db.JSONB{"product": trx.Product.Title}, trx.ID),
+		}, nil
+
+	case constants.EventTransactionShipped:
+		if reason != events.ReasonSeller {
+			return nil, nil
+		}
+		return []db.Notification{
+			eventNotification(buyerID, constants.NotifTypeShipment, "notification.shipped_buyer", byTransaction, trx.ID),
+			eventNotification(sellerID, constants.NotifTypeSale, "notification.shipped_seller", byTransaction, trx.ID),
+		}, nil
+
+	case constants.EventTransactionCompleted:
+		switch reason {
+		case events.ReasonBuyer:
+			return []db.Notification{
+				eventNotification(sellerID, constants.NotifTypeSale, "notification.completed_seller", byProduct, trx.ID),
+				eventNotification(buyerID, constants.NotifTypePurchase, "notification.completed_buyer", byProduct, trx.ID),
+			}, nil
+		case events.ReasonAdmin:
+			return []db.Notification{
+				eventNotification(sellerID, constants.NotifTypeSale, "notification.admin_completed_seller", byProduct, trx.ID),
+				eventNotification(buyerID, constants.NotifTypePurchase, "notification.admin_completed_buyer", byProduct, trx.ID),
+			}, nil
+		case events.ReasonProductDeleted:
+			return []db.Notification{
+				eventNotification(buyerID, constants.NotifTypePurchase, "notification.product_deleted_completed_buyer", byTransaction, trx.ID),
+				eventNotification(sellerID, constants.NotifTypeSale, "notification.product_deleted_completed_seller", byTransaction, trx.ID),
+			}, nil
+		case events.ReasonSellerRemoved:
+			return []db.Notification{
+				eventNotification(buyerID, constants.NotifTypePurchase, "notification.seller_removed_completed", byTransaction, trx.ID),
+			}, nil
+		}
+
+	case constants.EventTransactionCanceled:
+		switch reason {
+		case events.ReasonBuyer, events.ReasonSeller:
+			return []db.Notification{
+				eventNotification(buyerID, constants.NotifTypePurchase, "notification.canceled_buyer", byTransaction, trx.ID),
+				eventNotification(sellerID, constants.NotifTypeSale, "notification.canceled_seller", byTransaction, trx.ID),
+			}, nil
+		case events.ReasonAdmin:
+			notifications := []db.Notification{
+				eventNotification(buyerID, constants.NotifTypePurchase, "notification.admin_canceled_buyer", byProduct, trx.ID),
+			}
+			// Penjual hanya diberi tahu bila saldonya ikut didebit
+			switch constants.TransactionStatus(events.PayloadString(event.Payload, "previous_status")) {
+			case constants.TrxStatusWaitingUser, constants.TrxStatusSuccess:
+				notifications = append(notifications, eventNotification(sellerID, constants.NotifTypeSale, "notification.admin_canceled_seller",
+					db.JSONB{"product": trx.Product.Title, "product_id": trx.ProductID, "amount": trx.SellerPayoutAmount()}, trx.ID))
+			}
+			return notifications, nil
+		case events.ReasonProductDeleted:
+			return []db.Notification{
+				eventNotification(buyerID, constants.NotifTypePurchase, "notification.product_deleted_canceled", byTransaction, trx.ID),
+			}, nil
+		case events.ReasonSellerRemoved:
+			return []db.Notification{
+				eventNotification(buyerID, constants.NotifTypePurchase, "notification.seller_removed_canceled", byTransaction, trx.ID),
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+func userNotifications(event events.Event) []db.Notification {
+	userID := event.AggregateID
+	reason := events.PayloadString(event.Payload, "reason")
+
+	switch event.Type {
+	case constants.EventUserSuspended:
+		return []db.Notification{
+			eventNotification(userID, constants.NotifTypeAccount, "notification.account_suspended", db.JSONB{"reason": reason}, userID),
+		}
+	case constants.EventUserBanned:
+		return []db.Notification{
+			eventNotification(userID, constants.NotifTypeAccount, "notification.account_banned",
+				db.JSONB{"hours": events.PayloadUint(event.Payload, "duration_hours"), "reason": reason}, userID),
+		}
+	case constants.EventUserUnbanned:
+		return []db.Notification{
+			eventNotification(userID, constants.NotifTypeAccount, "notification.account_unbanned", nil, userID),
+		}
+	}
+	return nil
+}
+
+func supportTicketNotifications(tx *gorm.DB, event events.Event) ([]db.Notification, error) {
+	ticketID := event.AggregateID
+	userID := events.PayloadUint(event.Payload, "user_id")
+	assignedAdminID := events.PayloadUint(event.Payload, "assigned_admin_id")
+	actorID := events.PayloadUint(event.Payload, "actor_id")
+	subject := events.PayloadString(event.Payload, "subject")
+	params := db.JSONB{"ticket_id": ticketID, "subject": subject}
+
+	switch event.Type {
+	case constants.EventSupportTicketCreated:
+		return adminNotifications(tx, "notification.ticket_created_admin",
+			db.JSONB{"user_id": userID, "subject": subject, "queue": events.PayloadUint(event.Payload, "queue_pos")}, ticketID)
+
+	case constants.EventSupportTicketReplied:
+		if actorID != userID {
+			return []db.Notification{
+				eventNotification(userID, constants.NotifTypeSupport, "notification.ticket_admin_reply", params, ticketID),
+			}, nil
+		}
+		if assignedAdminID != 0 {
+			return []db.Notification{
+				eventNotification(assignedAdminID, constants.NotifTypeSupport, "notification.ticket_user_reply", params, ticketID),
+			}, nil
+		}
+		return adminNotifications(tx, "notification.ticket_user_reply", params, ticketID)
+
+	case constants.EventSupportTicketClaimed:
+		var adminName string
+		if err := tx.Model(&db.User{}).Select("full_name").Where("id = ?", actorID).Scan(&adminName).Error; err != nil {
+			return nil, err
+		}
+		return []db.Notification{
+			eventNotification(userID, constants.NotifTypeSupport, "notification.ticket_claimed",
+				db.JSONB{"ticket_id": ticketID, "subject": subject, "admin": adminName}, ticketID),
+		}, nil
+
+	case constants.EventSupportTicketCanceled:
+		if assignedAdminID == 0 {
+			return nil, nil
+		}
+		return []db.Notification{
+			eventNotification(assignedAdminID, constants.NotifTypeSupport, "notification.ticket_canceled", params, ticketID),
+		}, nil
+	}
+	return nil, nil
+}
+
+// adminNotifications membuat notifikasi dukungan yang sama untuk setiap admin
+func adminNotifications(tx *gorm.DB, messageKey string, params db.JSONB, ticketID uint) ([]db.Notification, error) {
+	var adminIDs []uint
+	if err := tx.Model(&db.User{}).Where("role = ?", constants.RoleAdmin).Pluck("id", &adminIDs).Error; err != nil {
+		return nil, err
+	}
+	notifications := make([]db.Notification, 0, len(adminIDs))
+	for _, adminID := range adminIDs {
+		notifications = append(notifications, eventNotification(adminID, constants.NotifTypeSupport, messageKey, params, ticketID))
+	}
+	return notifications, nil
+}
+
+func eventNotification(userID uint, notifType constants.NotificationType, messageKey string, params db.JSONB, relatedID uint) db.Notification {
+	return db.Notification{
+		UserID:        userID,
+		Type:          notifType,
+		MessageKey:    messageKey,
+		MessageParams: params,
+		RelatedID:     &relatedID,
+	}
+}
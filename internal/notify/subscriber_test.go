@@ -0,0 +1,113 @@
+package notify
+
+import (
+	"context"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/events"
+	"portolio-backend/internal/model/db"
+)
+
+// publishAndLoad menulis event transaksi ke outbox lalu membacanya kembali seperti relay,
+// sehingga payload sudah melalui JSONB
+func publishAndLoad(t *testing.T, dbConn *gorm.DB, eventType constants.EventType, trx db.TransactionHistory, previous constants.TransactionStatus, reason string) db.OutboxEvent {
+	t.Helper()
+	if err := events.PublishTransaction(dbConn, eventType, trx, 2, previous, reason); err != nil {
+		t.Fatal(err)
+	}
+	var outbox db.OutboxEvent
+	if err := dbConn.Last(&outbox).Error; err != nil {
+		t.Fatal(err)
+	}
+	return outbox
+}
+
+func TestEventSubscribersNotifyOncePerEvent(t *testing.T) {
+	dbConn := openTestDB(t)
+	pushed := &recordingDeliverer{}
+	emailed := &recordingDeliverer{}
+	useDeliverers(t, pushed)
+	Register(constants.NotificationChannelEmail, emailed)
+
+	Subscribe(dbConn)
+	SubscribeEmail(dbConn, EmailDeliverer{AppName: "Portolio"})
+
+	buyer := db.User{FullName: "Budi", Email: "budi@portolio.test"}
+	seller := db.User{FullName: "Sari", Email: "sari@portolio.test"}
+	for _, user := range []*db.User{&buyer, &seller} {
+		if err := dbConn.Create(user).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	product := db.Product{UserID: seller.ID, Title: "Kaos Polos"}
+	if err := dbConn.Create(&product).Error; err != nil {
+		t.Fatal(err)
+	}
+	trx := db.TransactionHistory{ProductID: product.ID, UserID: buyer.ID, Quantity: 2, TotalPrice: 100000, Status: constants.TrxStatusPending}
+	if err := dbConn.Create(&trx).Error; err != nil {
+		t.Fatal(err)
+	}
+	// Penjual memilih ringkasan harian untuk notifikasi penjualan
+	if err := SetDigest(dbConn, seller.ID, constants.NotifTypeSale, constants.DigestDaily); err != nil {
+		t.Fatal(err)
+	}
+
+	outbox := publishAndLoad(t, dbConn, constants.EventPurchaseCreated, trx, "", events.ReasonBuyer)
+	completed, err := events.Dispatch(context.Background(), outbox)
+	if err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	if len(completed) != 2 {
+		t.Fatalf("subscriber selesai = %v", completed)
+	}
+
+	// Relay yang gagal mencatat hasilnya meneruskan event yang sama sekali lagi
+	if _, err := events.Dispatch(context.Background(), outbox); err != nil {
+		t.Fatalf("Dispatch ulang: %v", err)
+	}
+
+	var notifications []db.Notification
+	dbConn.Find(&notifications)
+	if len(notifications) != 1 || notifications[0].UserID != buyer.ID || notifications[0].MessageKey != "notification.purchase_buyer" {
+		t.Fatalf("notifikasi = %+v, ingin satu notifikasi pembelian untuk pembeli", notifications)
+	}
+	if notifications[0].RelatedID == nil || *notifications[0].RelatedID != trx.ID {
+		t.Errorf("related_id = %v, ingin %d", notifications[0].RelatedID, trx.ID)
+	}
+
+	var digestItems []db.NotificationDigestItem
+	dbConn.Find(&digestItems)
+	if len(digestItems) != 1 || digestItems[0].UserID != seller.ID || digestItems[0].MessageParams["buyer"] != "Budi" {
+		t.Fatalf("item ringkasan = %+v, ingin satu item penjualan untuk penjual", digestItems)
+	}
+
+	if len(pushed.delivered) != 1 {
+		t.Errorf("terkirim %d push, ingin 1", len(pushed.delivered))
+	}
+	if len(emailed.delivered) != 0 {
+		t.Errorf("channel email notify dipakai %d kali, ingin 0 karena email dikirim subscriber email", len(emailed.delivered))
+	}
+
+	var emails []db.EmailOutbox
+	dbConn.Find(&emails)
+	if len(emails) != 1 || emails[0].ToAddress != buyer.Email || emails[0].Template != "purchase" {
+		t.Fatalf("email = %+v, ingin satu email pembelian untuk pembeli", emails)
+	}
+
+	// Pembatalan admin hanya memberi tahu penjual bila saldonya sudah dibayarkan
+	for previous, want := range map[constants.TransactionStatus]int{
+		constants.TrxStatusPending:     1,
+		constants.TrxStatusWaitingUser: 2,
+	} {
+		outbox := publishAndLoad(t, dbConn, constants.EventTransactionCanceled, trx, previous, events.ReasonAdmin)
+		notifications, err := EventNotifications(dbConn, events.Event{
+			ID: outbox.ID, Type: outbox.EventType, AggregateType: outbox.AggregateType, AggregateID: outbox.AggregateID, Payload: outbox.Payload,
+		})
+		if err != nil || len(notifications) != want {
+			t.Errorf("pembatalan admin dari %s: %d notifikasi, %v; ingin %d", previous, len(notifications), err, want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/i18n"
+	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/util"
+	"portolio-backend/internal/webpush"
+)
+
+// maxPushBodyRunes membatasi panjang isi notifikasi agar payload terenkripsi muat dalam
+// satu record Web Push
+const maxPushBodyRunes = 1000
+
+// WebPushDeliverer mengirim notifikasi ke browser melalui Web Push hanya bila pengguna
+// tidak memiliki koneksi WebSocket aktif. Dipasang dengan RegisterAfterCommit sehingga
+// push baru dikirim setelah transaksi berhasil; pengiriman HTTP ke layanan push berjalan
+// di goroutine agar tidak menahan respons.
+type WebPushDeliverer struct {
+	Sender *webpush.Sender
+}
+
+// PushPayload adalah isi pesan yang dibaca service worker di browser
+type PushPayload struct {
+	ID        uint                       `json:"id"`
+	Type      constants.NotificationType `json:"type"`
+	Title     string                     `json:"title"`
+	Body      string                     `json:"body"`
+	Items     []string                   `json:"items,omitempty"`
+	RelatedID *uint                      `json:"related_id,omitempty"`
+	CreatedAt time.Time                  `json:"created_at"`
+}
+
+func (d WebPushDeliverer) Deliver(tx *gorm.DB, notification db.Notification) error {
+	if d.Sender == nil || util.IsUserOnline(notification.UserID) {
+		return nil
+	}
+
+	var subscriptions []db.PushSubscription
+	if err := tx.Where("user_id = ? AND (expires_at IS NULL OR expires_at > ?)", notification.UserID, time.Now()).
+		Find(&subscriptions).Error; err != nil {
+		return err
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	lang := UserLanguage(tx, notification.UserID)
+	body := []rune(Render(notification, lang))
+	if len(body) > maxPushBodyRunes {
+		body = append(body[:maxPushBodyRunes-1], '…')
+	}
+	pushPayload := PushPayload{
+		ID:        notification.ID,
+		Type:      notification.Type,
+		Title:     i18n.T(lang, "push.title", i18n.Params{"type": i18n.Ref("notification_type."+string(notification.Type), nil)}),
+		Body:      string(body),
+		Items:     renderDigestItems(notification, lang),
+		RelatedID: notification.RelatedID,
+		CreatedAt: notification.CreatedAt,
+	}
+	payload, err := json.Marshal(pushPayload)
+	if err == nil && len(payload) > webpush.MaxPayloadSize {
+		// Ringkasan dengan banyak item dikirim tanpa daftar item
+		pushPayload.Items = nil
+		payload, err = json.Marshal(pushPayload)
+	}
+	if err != nil {
+		return err
+	}
+
+	go d.push(tx.WithContext(context.Background()), subscriptions, payload)
+	return nil
+}
+
+// push mengirim payload ke setiap perangkat. Langganan yang dibalas 404/410 atau gagal
+// berturut-turut sebanyak constants.DefaultWebPushMaxFailures dihapus.
+func (d WebPushDeliverer) push(dbConn *gorm.DB, subscriptions []db.PushSubscription, payload []byte) {
+	for _, subscription := range subscriptions {
+		ctx, cancel := context.WithTimeout(context.Background(), constants.DefaultWebPushTimeout)
+		_, err := d.Sender.Send(ctx, webpush.Subscription{
+			Endpoint: subscription.Endpoint,
+			P256dh:   subscription.P256dh,
+			Auth:     subscription.Auth,
+		}, payload)
+		cancel()
+
+		switch {
+		case err == nil:
+			now := time.Now()
+			dbConn.Model(&db.PushSubscription{}).Where("id = ?", subscription.ID).
+				Updates(map[string]interface{}{"last_used_at": &now, "failure_count": 0})
+		case errors.Is(err, webpush.ErrSubscriptionGone) || subscription.FailureCount+1 >= constants.DefaultWebPushMaxFailures:
+			if err := dbConn.Unscoped().Delete(&db.PushSubscription{}, subscription.ID).Error; err != nil {
+				log.Printf("❌ Gagal menghapus langganan web push #%d: %v", subscription.ID, err)
+			}
+		default:
+			log.Printf("❌ Gagal mengirim web push ke user %d (langganan #%d): %v", subscription.UserID, subscription.ID, err)
+			dbConn.Model(&db.PushSubscription{}).Where("id = ?", subscription.ID).
+				Update("failure_count", gorm.Expr("failure_count + 1"))
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package notify
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/webpush"
+)
+
+func TestWebPushRemovesGoneSubscriptions(t *testing.T) {
+	dbConn := openTestDB(t)
+
+	// Layanan push membalas sesuai kode status di akhir path endpoint
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status, err := strconv.Atoi(r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:])
+		if err != nil {
+			status = http.StatusBadRequest
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	publicKey, privateKey, err := webpush.GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender, err := webpush.NewSender(webpush.Config{
+		VAPIDPublicKey:  publicKey,
+		VAPIDPrivateKey: privateKey,
+		Subject:         "mailto:admin@portolio.test",
+		AllowPrivate:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subscriptions := map[int]*db.PushSubscription{}
+	var all []db.PushSubscription
+	for _, status := range []int{http.StatusCreated, http.StatusNotFound, http.StatusGone, http.StatusInternalServerError} {
+		key, err := ecdh.P256().GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		auth := make([]byte, 16)
+		rand.Read(auth)
+		subscription := db.PushSubscription{
+			UserID:       7,
+			Endpoint:     server.URL + "/push/" + strconv.Itoa(status),
+			P256dh:       base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+			Auth:         base64.RawURLEncoding.EncodeToString(auth),
+			FailureCount: 1,
+		}
+		if err := dbConn.Create(&subscription).Error; err != nil {
+			t.Fatal(err)
+		}
+		subscriptions[status] = &subscription
+		all = append(all, subscription)
+	}
+
+	WebPushDeliverer{Sender: sender}.push(dbConn, all, []byte(`{"id":1}`))
+
+	for _, status := range []int{http.StatusNotFound, http.StatusGone} {
+		var count int64
+		dbConn.Unscoped().Model(&db.PushSubscription{}).Where("id = ?", subscriptions[status].ID).Count(&count)
+		if count != 0 {
+			t.Errorf("langganan yang dibalas HTTP %d masih tersimpan", status)
+		}
+	}
+
+	var delivered db.PushSubscription
+	if err := dbConn.First(&delivered, subscriptions[http.StatusCreated].ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if delivered.LastUsedAt == nil || delivered.FailureCount != 0 {
+		t.Errorf("langganan terkirim: last_used_at = %v, failure_count = %d", delivered.LastUsedAt, delivered.FailureCount)
+	}
+
+	var failing db.PushSubscription
+	if err := dbConn.First(&failing, subscriptions[http.StatusInternalServerError].ID).Error; err != nil {
+		t.Fatalf("langganan yang gagal sementara ikut dihapus: %v", err)
+	}
+	if failing.FailureCount != 2 {
+		t.Errorf("failure_count = %d, ingin 2", failing.FailureCount)
+	}
+
+	// Kegagalan ke-DefaultWebPushMaxFailures menghapus langganan
+	failing.FailureCount = constants.DefaultWebPushMaxFailures - 1
+	WebPushDeliverer{Sender: sender}.push(dbConn, []db.PushSubscription{failing}, []byte(`{"id":1}`))
+	var count int64
+	dbConn.Unscoped().Model(&db.PushSubscription{}).Where("id = ?", failing.ID).Count(&count)
+	if count != 0 {
+		t.Errorf("langganan tetap tersimpan setelah %d kegagalan", constants.DefaultWebPushMaxFailures)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"portolio-backend/configs/constants"
 	"portolio-backend/internal/handler"
 	"portolio-backend/internal/middlewares"
 	"portolio-backend/internal/util"
@@ -15,6 +16,8 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, hub *util.Hub) {
 	shopHandler := handler.NewShopHandler(db)
 	supportHandler := handler.NewSupportHandler(db)
 	uploadHandler := handler.NewUploadHandler(db)
+	notificationHandler := handler.NewNotificationHandler(db)
+	webhookHandler := handler.NewWebhookHandler(db)
 	websocketHandler := handler.NewWebsocketHandler(db, hub)
 	mediaHandler := handler.NewMediaHandler(db) // Inisialisasi handler media baru
 
@@ -29,6 +32,7 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, hub *util.Hub) {
 			account.Use(middlewares.Authorize(db))
 			account.Use(middlewares.CheckUserStatus(db))
 
+			account.GET("/", accountHandler.GetAccount)
 			account.GET("/balance", accountHandler.GetBalanceRequest)
 			account.POST("/topup", accountHandler.PostTopUpBalance)
 			account.POST("/withdraw", accountHandler.PostWithDrawBalance)
@@ -46,8 +50,13 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, hub *util.Hub) {
 
 			shop.GET("/orders", shopHandler.GetCartHandler)
 			shop.POST("/purchase", shopHandler.PostPurchaseProduct)
+			shop.POST("/reservations", shopHandler.CreateReservations)
+			shop.GET("/reservations", shopHandler.GetReservations)
+			shop.DELETE("/reservations/:id", shopHandler.CancelReservation)
 			shop.POST("/transactions/cancel", shopHandler.CancelTransaction)
 			shop.POST("/transactions/confirm-receipt", shopHandler.ConfirmTransactionByUser)
+			shop.GET("/transactions/:id/messages", shopHandler.GetChatMessages)
+			shop.POST("/transactions/:id/messages/read", shopHandler.MarkChatMessagesRead)
 
 			shop.POST("/products", shopHandler.PostProductsRequest)
 			shop.PUT("/products/:id", shopHandler.PutProductsRequest)
@@ -55,6 +64,31 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, hub *util.Hub) {
 			shop.PATCH("/products/:id/visibility", shopHandler.PatchProductVisibility)
 			shop.GET("/products/orders", shopHandler.GetOwnerProductOrders)
 			shop.POST("/products/orders/confirm-shipment", shopHandler.ConfirmTransactionByOwner)
+			shop.POST("/products/import", shopHandler.ImportProducts)
+			shop.GET("/products/imports", shopHandler.GetProductImportJobs)
+			shop.GET("/products/imports/:id", shopHandler.GetProductImportJob)
+			shop.GET("/products/export", shopHandler.ExportProducts)
+			shop.POST("/products/:id/price-schedules", shopHandler.CreatePriceSchedule)
+			shop.GET("/products/:id/price-schedules", shopHandler.GetPriceSchedules)
+			shop.DELETE("/products/:id/price-schedules/:schedule_id", shopHandler.CancelPriceSchedule)
+			shop.GET("/products/:id/price-history", shopHandler.GetPriceHistory)
+			shop.GET("/products/:id/inventory-movements", shopHandler.GetInventoryMovements)
+			shop.POST("/products/:id/stock-adjustments", shopHandler.AdjustProductStock)
+			shop.POST("/products/:id/images", shopHandler.AddProductImages)
+			shop.PUT("/products/:id/images/order", shopHandler.ReorderProductImages)
+			shop.PATCH("/products/:id/images/:image_id", shopHandler.PatchProductImage)
+			shop.DELETE("/products/:id/images/:image_id", shopHandler.DeleteProductImage)
+			shop.POST("/coupons", shopHandler.CreateSellerCoupon)
+			shop.GET("/coupons", shopHandler.GetSellerCoupons)
+			shop.PATCH("/coupons/:id", shopHandler.PatchSellerCoupon)
+			shop.GET("/coupons/:id/usage", shopHandler.GetSellerCouponUsage)
+
+			shop.GET("/wishlist", shopHandler.GetWishlist)
+			shop.POST("/wishlist", shopHandler.AddWishlistItem)
+			shop.DELETE("/wishlist/:product_id", shopHandler.RemoveWishlistItem)
+			shop.GET("/restock-subscriptions", shopHandler.GetRestockSubscriptions)
+			shop.POST("/products/:id/restock-subscription", shopHandler.SubscribeRestock)
+			shop.DELETE("/products/:id/restock-subscription", shopHandler.UnsubscribeRestock)
 
 			shop.POST("/support/tickets", supportHandler.CreateSupportTicket)
 			shop.GET("/support/tickets", supportHandler.GetUserSupportTickets)
@@ -72,7 +106,7 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, hub *util.Hub) {
 			adminAPI.Use(middlewares.AuthorizeAdmin(db))
 
 			adminAPI.GET("/dashboard", func(c *gin.Context) {
-				util.RespondJSON(c, 200, gin.H{"message": "Selamat datang di Dashboard Admin!"})
+				util.RespondJSON(c, 200, gin.H{"message": constants.MsgSuccessAdminDashboard})
 			})
 
 			adminAPI.GET("/users", adminHandler.GetUsers)
@@ -80,6 +114,8 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, hub *util.Hub) {
 			adminAPI.PATCH("/users/:id/ban", adminHandler.BanUser)
 			adminAPI.PATCH("/users/:id/unban", adminHandler.UnbanUser)
 			adminAPI.DELETE("/users/:id", adminHandler.DeleteUser)
+			adminAPI.PATCH("/users/:id/storage-quota", adminHandler.UpdateUserStorageQuota)
+			adminAPI.GET("/storage/usage", adminHandler.GetStorageUsageAdmin)
 
 			adminAPI.GET("/products", adminHandler.GetProductsAdmin)
 			adminAPI.PATCH("/products/:id", adminHandler.PatchProductAdmin)
@@ -87,6 +123,11 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, hub *util.Hub) {
 			adminAPI.GET("/transactions", adminHandler.GetTransactions)
 			adminAPI.PATCH("/transactions/:id/status", adminHandler.PatchTransactionStatus)
 
+			adminAPI.POST("/coupons", adminHandler.CreateCouponAdmin)
+			adminAPI.GET("/coupons", adminHandler.GetCouponsAdmin)
+			adminAPI.PATCH("/coupons/:id", adminHandler.PatchCouponAdmin)
+			adminAPI.GET("/coupons/:id/usage", adminHandler.GetCouponUsageAdmin)
+
 			adminAPI.GET("/balances/history", adminHandler.GetBalanceHistories)
 			adminAPI.GET("/balances/topup-withdraw-logs", adminHandler.GetTopUpWithdrawLogs)
 
@@ -94,6 +135,10 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, hub *util.Hub) {
 			adminAPI.GET("/support/tickets/:id/messages", adminHandler.GetSupportTicketMessages)
 			adminAPI.POST("/support/tickets/:id/claim", adminHandler.ClaimSupportTicket)
 			adminAPI.POST("/support/tickets/:id/reply", adminHandler.ReplySupportTicket)
+
+			adminAPI.GET("/events", adminHandler.GetOutboxEventsAdmin)
+			adminAPI.GET("/events/:id", adminHandler.GetOutboxEventAdmin)
+			adminAPI.POST("/events/:id/replay", adminHandler.ReplayOutboxEvent)
 		}
 
 		uploadAPI := r.Group("/api/upload")
@@ -105,6 +150,42 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, hub *util.Hub) {
 			uploadAPI.POST("/image", uploadHandler.UploadImage)
 			uploadAPI.POST("/file", uploadHandler.UploadFile)
 		}
+
+		notificationAPI := r.Group("/api/notifications")
+		{
+			notificationAPI.Use(middlewares.JWTMiddleware())
+			notificationAPI.Use(middlewares.Authorize(db))
+			notificationAPI.Use(middlewares.CheckUserStatus(db))
+
+			notificationAPI.GET("", notificationHandler.GetNotifications)
+			notificationAPI.GET("/unread-count", notificationHandler.GetUnreadCount)
+			notificationAPI.GET("/preferences", notificationHandler.GetNotificationPreferences)
+			notificationAPI.PUT("/preferences", notificationHandler.UpdateNotificationPreferences)
+			notificationAPI.GET("/push/public-key", notificationHandler.GetVAPIDPublicKey)
+			notificationAPI.GET("/push/subscriptions", notificationHandler.GetPushSubscriptions)
+			notificationAPI.POST("/push/subscriptions", notificationHandler.CreatePushSubscription)
+			notificationAPI.DELETE("/push/subscriptions/:id", notificationHandler.DeletePushSubscription)
+			notificationAPI.PATCH("/read", notificationHandler.MarkNotificationsRead)
+			notificationAPI.PATCH("/read-all", notificationHandler.MarkAllNotificationsRead)
+			notificationAPI.PATCH("/:id/read", notificationHandler.MarkNotificationRead)
+			notificationAPI.DELETE("/:id", notificationHandler.DeleteNotification)
+		}
+
+		webhookAPI := r.Group("/api/webhooks")
+		{
+			webhookAPI.Use(middlewares.JWTMiddleware())
+			webhookAPI.Use(middlewares.Authorize(db))
+			webhookAPI.Use(middlewares.CheckUserStatus(db))
+
+			webhookAPI.GET("", webhookHandler.GetWebhooks)
+			webhookAPI.POST("", webhookHandler.CreateWebhook)
+			webhookAPI.PATCH("/:id", webhookHandler.UpdateWebhook)
+			webhookAPI.DELETE("/:id", webhookHandler.DeleteWebhook)
+			webhookAPI.POST("/:id/rotate-secret", webhookHandler.RotateWebhookSecret)
+			webhookAPI.POST("/:id/test", webhookHandler.TestWebhook)
+			webhookAPI.GET("/:id/deliveries", webhookHandler.GetWebhookDeliveries)
+			webhookAPI.POST("/:id/deliveries/:delivery_id/redeliver", webhookHandler.RedeliverWebhook)
+		}
 	}
 
 	wsAPI := r.Group("/ws")
@@ -117,12 +198,17 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, hub *util.Hub) {
 		wsAPI.GET("/notifications", websocketHandler.NotificationHandler)
 	}
 
+	// Gambar produk dapat diakses publik
+	api.GET("/media/products/:filename", mediaHandler.ServeProductMedia)
+
 	// Rute media terproteksi (chat dan support)
 	protectedMedia := r.Group("/media")
 	{
-		protectedMedia.Use(middlewares.JWTMiddleware())
-		protectedMedia.Use(middlewares.Authorize(db))
-		protectedMedia.Use(middlewares.CheckUserStatus(db))
+		// URL bertanda tangan dari API chat/support tidak memerlukan token maupun query database
+		protectedMedia.Use(middlewares.SignedMedia())
+		protectedMedia.Use(middlewares.UnlessSignedMedia(middlewares.JWTMiddleware()))
+		protectedMedia.Use(middlewares.UnlessSignedMedia(middlewares.Authorize(db)))
+		protectedMedia.Use(middlewares.UnlessSignedMedia(middlewares.CheckUserStatus(db)))
 		// Rute ini akan menangani /media/chat/:filename dan /media/support/:filename
 		protectedMedia.GET("/:mediaType/:filename", mediaHandler.ServeProtectedMedia)
 	}
@@ -0,0 +1,102 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+const clamdChunkSize = 64 * 1024
+
+// ClamdScanner memindai file melalui perintah INSTREAM milik clamd. Semua server
+// yang kompatibel dengan protokol socket clamd dapat dipakai.
+type ClamdScanner struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+func NewClamdScanner(address string, timeout time.Duration) *ClamdScanner {
+	network, addr := "tcp", address
+	if strings.HasPrefix(address, "unix://") {
+		network, addr = "unix", strings.TrimPrefix(address, "unix://")
+	} else {
+		addr = strings.TrimPrefix(address, "tcp://")
+	}
+	return &ClamdScanner{network: network, address: addr, timeout: timeout}
+}
+
+func (s *ClamdScanner) Name() string {
+	return "clamd"
+}
+
+func (s *ClamdScanner) Scan(ctx context.Context, r io.Reader) (Result, error) {
+	dialer := net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return Result{}, fmt.Errorf("gagal terhubung ke clamd: %v", err)
+	}
+	defer conn.Close()
+
+	if s.timeout > 0 {
+		conn.SetDeadline(time.Now().Add(s.timeout))
+	}
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return Result{}, err
+	}
+
+	buf := make([]byte, clamdChunkSize)
+	size := make([]byte, 4)
+	for {
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := conn.Write(size); err != nil {
+				return Result{}, err
+			}
+			if _, err := conn.Write(buf[:n]); err != nil {
+				return Result{}, err
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return Result{}, readErr
+		}
+	}
+
+	binary.BigEndian.PutUint32(size, 0)
+	if _, err := conn.Write(size); err != nil {
+		return Result{}, err
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString('\x00')
+	if err != nil && err != io.EOF {
+		return Result{}, err
+	}
+	return parseClamdReply(reply)
+}
+
+// parseClamdReply membaca balasan seperti "stream: OK" atau
+// "stream: Eicar-Test-Signature FOUND".
+func parseClamdReply(reply string) (Result, error) {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	reply = strings.TrimPrefix(reply, "stream:")
+	reply = strings.TrimSpace(reply)
+
+	switch {
+	case reply == "OK":
+		return Result{Clean: true}, nil
+	case strings.HasSuffix(reply, "FOUND"):
+		return Result{Clean: false, Signature: strings.TrimSpace(strings.TrimSuffix(reply, "FOUND"))}, nil
+	default:
+		return Result{}, fmt.Errorf("balasan clamd tidak dikenal: %s", reply)
+	}
+}
@@ -0,0 +1,178 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const eicarMarker = "EICAR-STANDARD-ANTIVIRUS-TEST-FILE"
+
+// clamdSession adalah isi satu percakapan INSTREAM yang diterima fakeClamd
+type clamdSession struct {
+	command string
+	chunks  []int
+	data    []byte
+}
+
+// fakeClamd menerima satu koneksi per pemindaian, menyusun ulang chunk INSTREAM, lalu
+// membalas reply untuk isi tersebut seperti clamd
+func fakeClamd(t *testing.T, listener net.Listener, reply func(data []byte) string) <-chan clamdSession {
+	t.Helper()
+	t.Cleanup(func() { listener.Close() })
+
+	sessions := make(chan clamdSession, 4)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.SetDeadline(time.Now().Add(5 * time.Second))
+			r := bufio.NewReader(conn)
+
+			var session clamdSession
+			session.command, err = r.ReadString('\x00')
+			if err != nil {
+				conn.Close()
+				continue
+			}
+			size := make([]byte, 4)
+			for {
+				if _, err := io.ReadFull(r, size); err != nil {
+					break
+				}
+				n := binary.BigEndian.Uint32(size)
+				if n == 0 {
+					break
+				}
+				chunk := make([]byte, n)
+				if _, err := io.ReadFull(r, chunk); err != nil {
+					break
+				}
+				session.chunks = append(session.chunks, int(n))
+				session.data = append(session.data, chunk...)
+			}
+			io.WriteString(conn, reply(session.data)+"\x00")
+			conn.Close()
+			sessions <- session
+		}
+	}()
+	return sessions
+}
+
+func eicarReply(data []byte) string {
+	if bytes.Contains(data, []byte(eicarMarker)) {
+		return "stream: Eicar-Test-Signature FOUND"
+	}
+	return "stream: OK"
+}
+
+func TestClamdScannerStreamsFileInChunks(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessions := fakeClamd(t, listener, eicarReply)
+	s := New("clamav", "tcp://"+listener.Addr().String(), 5*time.Second)
+
+	// Lebih besar dari satu chunk agar pemecahan INSTREAM ikut diuji
+	content := bytes.Repeat([]byte("isi dokumen bersih "), 10000)
+	result, err := s.Scan(context.Background(), bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if !result.Clean || result.Signature != "" {
+		t.Fatalf("Result = %+v, ingin bersih", result)
+	}
+
+	session := <-sessions
+	if session.command != "zINSTREAM\x00" {
+		t.Errorf("perintah = %q, ingin zINSTREAM", session.command)
+	}
+	if !bytes.Equal(session.data, content) {
+		t.Errorf("clamd menerima %d byte, ingin %d byte yang sama", len(session.data), len(content))
+	}
+	if len(session.chunks) != 3 || session.chunks[0] != clamdChunkSize {
+		t.Errorf("chunk = %v, ingin 3 chunk dengan chunk pertama %d byte", session.chunks, clamdChunkSize)
+	}
+
+	result, err = s.Scan(context.Background(), strings.NewReader("X5O!P%@AP[4\\PZX54(P^)7CC)7}$"+eicarMarker+"!$H+H*"))
+	if err != nil {
+		t.Fatalf("Scan EICAR: %v", err)
+	}
+	if result.Clean || result.Signature != "Eicar-Test-Signature" {
+		t.Fatalf("Result = %+v, ingin terdeteksi Eicar-Test-Signature", result)
+	}
+}
+
+func TestClamdScannerUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "clamd.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix socket tidak tersedia: %v", err)
+	}
+	fakeClamd(t, listener, eicarReply)
+
+	result, err := NewClamdScanner("unix://"+socket, 5*time.Second).Scan(context.Background(), strings.NewReader("halo"))
+	if err != nil || !result.Clean {
+		t.Fatalf("Scan = %+v, %v", result, err)
+	}
+}
+
+func TestClamdScannerErrors(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fakeClamd(t, listener, func([]byte) string { return "INSTREAM size limit exceeded. ERROR" })
+	if _, err := NewClamdScanner(listener.Addr().String(), 5*time.Second).Scan(context.Background(), strings.NewReader("halo")); err == nil {
+		t.Error("balasan ERROR dari clamd dianggap berhasil")
+	}
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := closed.Addr().String()
+	closed.Close()
+	if _, err := NewClamdScanner(address, time.Second).Scan(context.Background(), strings.NewReader("halo")); err == nil {
+		t.Error("clamd yang tidak berjalan dianggap berhasil")
+	}
+}
+
+func TestParseClamdReply(t *testing.T) {
+	tests := []struct {
+		reply   string
+		want    Result
+		wantErr bool
+	}{
+		{reply: "stream: OK\x00", want: Result{Clean: true}},
+		{reply: "stream: Win.Test.EICAR_HDB-1 FOUND\x00", want: Result{Signature: "Win.Test.EICAR_HDB-1"}},
+		{reply: "OK", want: Result{Clean: true}},
+		{reply: "stream: lstat() failed. ERROR", wantErr: true},
+		{reply: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseClamdReply(tt.reply)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseClamdReply(%q) = %+v, %v; ingin %+v, error = %v", tt.reply, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestNewSelectsDriver(t *testing.T) {
+	if name := New("ClamD", "localhost:3310", time.Second).Name(); name != "clamd" {
+		t.Errorf("driver clamd = %s", name)
+	}
+	if name := New("", "", time.Second).Name(); name != "noop" {
+		t.Errorf("driver kosong = %s, ingin noop", name)
+	}
+}
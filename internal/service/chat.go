@@ -0,0 +1,87 @@
+package service
+
+import (
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+)
+
+// ChatPage adalah satu halaman riwayat chat transaksi yang diurutkan dari pesan terlama
+type ChatPage struct {
+	Messages   []db.ChatMessage
+	HasMore    bool
+	NextCursor *uint
+}
+
+// ListChatMessages memuat satu halaman pesan chat transaksi. Tanpa cursor (0), halaman berisi
+// pesan terbaru; before memuat pesan yang lebih lama dan after memuat pesan yang lebih baru.
+// NextCursor dipakai sebagai before/after berikutnya selama HasMore bernilai true.
+func ListChatMessages(tx *gorm.DB, transactionID, before, after uint, limit int) (ChatPage, error) {
+	query := tx.Preload("Sender").Where("transaction_id = ?", transactionID)
+	ascending := after > 0
+	switch {
+	case ascending:
+		query = query.Where("id > ?", after).Order("id ASC")
+	case before > 0:
+		query = query.Where("id < ?", before).Order("id DESC")
+	default:
+		query = query.Order("id DESC")
+	}
+
+	var page ChatPage
+	if err := query.Limit(limit + 1).Find(&page.Messages).Error; err != nil {
+		return page, err
+	}
+
+	page.HasMore = len(page.Messages) > limit
+	if page.HasMore {
+		page.Messages = page.Messages[:limit]
+	}
+	if !ascending {
+		slices.Reverse(page.Messages)
+	}
+	if page.HasMore {
+		cursor := page.Messages[0].ID
+		if ascending {
+			cursor = page.Messages[len(page.Messages)-1].ID
+		}
+		page.NextCursor = &cursor
+	}
+	return page, nil
+}
+
+// MarkChatRead menandai pesan di transaksi yang diterima readerID sebagai dibaca, sampai
+// upToID (0 berarti semua), beserta notifikasi chat untuk pesan tersebut. Mengembalikan
+// ID pesan yang baru ditandai; pesan yang sudah dibaca sebelumnya tidak ikut.
+func MarkChatRead(tx *gorm.DB, transactionID, readerID, upToID uint, readAt time.Time) ([]uint, error) {
+	query := tx.Model(&db.ChatMessage{}).
+		Where("transaction_id = ? AND receiver_id = ? AND is_read = ?", transactionID, readerID, false)
+	if upToID > 0 {
+		query = query.Where("id <= ?", upToID)
+	}
+
+	var messageIDs []uint
+	if err := query.Order("id ASC").Pluck("id", &messageIDs).Error; err != nil {
+		return nil, err
+	}
+	if len(messageIDs) == 0 {
+		return messageIDs, nil
+	}
+
+	if err := tx.Model(&db.ChatMessage{}).
+		Where("id IN ? AND is_read = ?", messageIDs, false).
+		Updates(map[string]interface{}{"is_read": true, "read_at": readAt}).Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Model(&db.Notification{}).
+		Where("user_id = ? AND type = ? AND related_id IN ? AND is_read = ?", readerID, constants.NotifTypeChat, messageIDs, false).
+		Update("is_read", true).Error; err != nil {
+		return nil, err
+	}
+	return messageIDs, nil
+}
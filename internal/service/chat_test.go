@@ -0,0 +1,140 @@
+package service
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+)
+
+// createChat membuat count pesan bergantian antara pembeli (1) dan penjual (2)
+func createChat(t *testing.T, dbConn *gorm.DB, transactionID uint, count int) []uint {
+	t.Helper()
+	ids := make([]uint, count)
+	for i := range ids {
+		message := db.ChatMessage{TransactionID: transactionID, SenderID: 1, ReceiverID: 2, MessageType: constants.ChatTypeText, Content: "halo"}
+		if i%2 == 1 {
+			message.SenderID, message.ReceiverID = 2, 1
+		}
+		if err := dbConn.Create(&message).Error; err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = message.ID
+	}
+	return ids
+}
+
+func pageIDs(page ChatPage) []uint {
+	ids := make([]uint, len(page.Messages))
+	for i, message := range page.Messages {
+		ids[i] = message.ID
+	}
+	return ids
+}
+
+func TestListChatMessagesPaging(t *testing.T) {
+	dbConn := openTestDB(t, &db.User{}, &db.ChatMessage{})
+	ids := createChat(t, dbConn, 10, 5)
+	createChat(t, dbConn, 11, 2)
+
+	tests := []struct {
+		name          string
+		before, after uint
+		limit         int
+		want          []uint
+		wantMore      bool
+		wantCursor    uint
+	}{
+		{name: "halaman terbaru", limit: 2, want: ids[3:], wantMore: true, wantCursor: ids[3]},
+		{name: "sebelum cursor", before: ids[3], limit: 2, want: ids[1:3], wantMore: true, wantCursor: ids[1]},
+		{name: "halaman terlama", before: ids[1], limit: 2, want: ids[:1]},
+		{name: "setelah cursor", after: ids[0], limit: 2, want: ids[1:3], wantMore: true, wantCursor: ids[2]},
+		{name: "setelah cursor sampai habis", after: ids[2], limit: 2, want: ids[3:]},
+		{name: "semua muat satu halaman", limit: 10, want: ids},
+		{name: "tidak ada pesan baru", after: ids[4], limit: 2, want: []uint{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := ListChatMessages(dbConn, 10, tt.before, tt.after, tt.limit)
+			if err != nil {
+				t.Fatalf("ListChatMessages: %v", err)
+			}
+			if got := pageIDs(page); !slices.Equal(got, tt.want) {
+				t.Errorf("pesan = %v, ingin %v", got, tt.want)
+			}
+			if page.HasMore != tt.wantMore {
+				t.Errorf("has_more = %v, ingin %v", page.HasMore, tt.wantMore)
+			}
+			switch {
+			case !tt.wantMore && page.NextCursor != nil:
+				t.Errorf("next_cursor = %d, ingin kosong", *page.NextCursor)
+			case tt.wantMore && (page.NextCursor == nil || *page.NextCursor != tt.wantCursor):
+				t.Errorf("next_cursor = %v, ingin %d", page.NextCursor, tt.wantCursor)
+			}
+		})
+	}
+}
+
+func TestMarkChatRead(t *testing.T) {
+	dbConn := openTestDB(t, &db.ChatMessage{}, &db.Notification{})
+	ids := createChat(t, dbConn, 10, 5)
+	// Pesan transaksi lain untuk pembaca yang sama tidak boleh ikut ditandai
+	other := createChat(t, dbConn, 11, 1)
+	for _, id := range append(ids, other...) {
+		relatedID := id
+		var message db.ChatMessage
+		dbConn.First(&message, id)
+		notification := db.Notification{UserID: message.ReceiverID, Type: constants.NotifTypeChat, MessageKey: "notification.chat", RelatedID: &relatedID}
+		if err := dbConn.Create(&notification).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	readAt := time.Now().Truncate(time.Second)
+
+	// Penjual (2) menerima pesan ids[0], ids[2], dan ids[4]
+	marked, err := MarkChatRead(dbConn, 10, 2, ids[2], readAt)
+	if err != nil {
+		t.Fatalf("MarkChatRead: %v", err)
+	}
+	if want := []uint{ids[0], ids[2]}; !slices.Equal(marked, want) {
+		t.Fatalf("ditandai = %v, ingin %v", marked, want)
+	}
+
+	marked, err = MarkChatRead(dbConn, 10, 2, 0, readAt)
+	if err != nil {
+		t.Fatalf("MarkChatRead semua: %v", err)
+	}
+	if want := []uint{ids[4]}; !slices.Equal(marked, want) {
+		t.Fatalf("ditandai ulang = %v, ingin hanya pesan yang belum dibaca %v", marked, want)
+	}
+
+	marked, err = MarkChatRead(dbConn, 10, 2, 0, readAt)
+	if err != nil || len(marked) != 0 {
+		t.Fatalf("MarkChatRead tanpa pesan baru = %v, %v", marked, err)
+	}
+
+	var messages []db.ChatMessage
+	dbConn.Order("id ASC").Find(&messages)
+	for _, message := range messages {
+		wantRead := message.TransactionID == 10 && message.ReceiverID == 2
+		if message.IsRead != wantRead || (message.ReadAt != nil) != wantRead {
+			t.Errorf("pesan %d: is_read = %v, read_at = %v; ingin dibaca = %v", message.ID, message.IsRead, message.ReadAt, wantRead)
+		}
+		if wantRead && !message.ReadAt.Equal(readAt) {
+			t.Errorf("pesan %d: read_at = %v, ingin %v", message.ID, message.ReadAt, readAt)
+		}
+	}
+
+	var notifications []db.Notification
+	dbConn.Order("id ASC").Find(&notifications)
+	for _, notification := range notifications {
+		wantRead := notification.UserID == 2 && *notification.RelatedID != other[0]
+		if notification.IsRead != wantRead {
+			t.Errorf("notifikasi pesan %d untuk %d: is_read = %v, ingin %v", *notification.RelatedID, notification.UserID, notification.IsRead, wantRead)
+		}
+	}
+}
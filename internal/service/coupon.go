@@ -0,0 +1,224 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+)
+
+// NormalizeCouponCode menyeragamkan kode kupon agar pencarian tidak peka huruf besar/kecil
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// CouponLine adalah satu item pesanan yang memakai kupon beserta harga sebelum potongan
+type CouponLine struct {
+	Product   db.Product
+	BasePrice uint
+}
+
+// ResolveCoupon mengunci kupon lalu memvalidasi masa berlaku, kuota, cakupan produk, dan
+// minimum belanja untuk satu pesanan. Minimum belanja dibandingkan dengan subtotal item
+// yang memakai kupon, sehingga barang lain di keranjang tidak ikut dihitung. Potongan
+// dihitung sekali lalu dibagi ke setiap item sebanding harganya; urutan hasil sama
+// dengan lines.
+func ResolveCoupon(tx *gorm.DB, code string, userID uint, lines []CouponLine, now time.Time) (*db.Coupon, []uint, error) {
+	var coupon db.Coupon
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("code = ? AND is_active = ?", NormalizeCouponCode(code), true).
+		First(&coupon).Error; err != nil {
+		return nil, nil, fmt.Errorf(constants.ErrMsgCouponNotFound)
+	}
+
+	if (coupon.StartsAt != nil && now.Before(*coupon.StartsAt)) || (coupon.ExpiresAt != nil && !now.Before(*coupon.ExpiresAt)) {
+		return nil, nil, fmt.Errorf(constants.ErrMsgCouponExpired)
+	}
+	if coupon.UsageLimit > 0 && coupon.UsedCount >= coupon.UsageLimit {
+		return nil, nil, fmt.Errorf(constants.ErrMsgCouponUsageExceeded)
+	}
+	if coupon.PerUserLimit > 0 {
+		var used int64
+		if err := tx.Model(&db.CouponRedemption{}).
+			Where("coupon_id = ? AND user_id = ? AND status = ?", coupon.ID, userID, constants.CouponRedemptionApplied).
+			Count(&used).Error; err != nil {
+			return nil, nil, err
+		}
+		if uint(used) >= coupon.PerUserLimit {
+			return nil, nil, fmt.Errorf(constants.ErrMsgCouponUserLimitExceeded)
+		}
+	}
+
+	var subtotal uint
+	for _, line := range lines {
+		if !CouponAppliesToProduct(coupon, line.Product) {
+			return nil, nil, fmt.Errorf(constants.ErrMsgCouponNotApplicable+": %s", line.Product.Title)
+		}
+		subtotal += line.BasePrice
+	}
+	if subtotal < coupon.MinSpend {
+		return nil, nil, fmt.Errorf(constants.ErrMsgCouponMinSpend)
+	}
+
+	return &coupon, splitDiscount(CouponDiscount(coupon, subtotal), lines, subtotal), nil
+}
+
+// splitDiscount membagi potongan pesanan ke setiap item sebanding harganya. Sisa
+// pembulatan diberikan ke item terakhir agar jumlahnya tetap sama dengan discount.
+func splitDiscount(discount uint, lines []CouponLine, subtotal uint) []uint {
+	discounts := make([]uint, len(lines))
+	if subtotal == 0 {
+		return discounts
+	}
+	var allocated uint
+	for i, line := range lines {
+		if i == len(lines)-1 {
+			discounts[i] = discount - allocated
+			break
+		}
+		discounts[i] = uint(uint64(discount) * uint64(line.BasePrice) / uint64(subtotal))
+		allocated += discounts[i]
+	}
+	return discounts
+}
+
+// CouponAppliesToProduct memeriksa cakupan penjual, produk, dan kategori kupon.
+// Cakupan yang kosong berarti kupon berlaku untuk semua.
+func CouponAppliesToProduct(coupon db.Coupon, product db.Product) bool {
+	if coupon.SellerID != nil && *coupon.SellerID != product.UserID {
+		return false
+	}
+
+	if ids := SplitCSV(coupon.ProductIDs); len(ids) > 0 {
+		found := false
+		for _, id := range ids {
+			if id == strconv.FormatUint(uint64(product.ID), 10) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if cats := SplitCSV(coupon.Categories); len(cats) > 0 {
+		productCats := SplitCSV(product.Categories)
+		for _, want := range cats {
+			for _, have := range productCats {
+				if strings.EqualFold(want, have) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// CouponDiscount menghitung potongan harga, tidak pernah melebihi basePrice
+func CouponDiscount(coupon db.Coupon, basePrice uint) uint {
+	var discount uint
+	switch coupon.DiscountType {
+	case constants.CouponDiscountPercentage:
+		discount = uint(uint64(basePrice) * uint64(coupon.DiscountValue) / 100)
+		if coupon.MaxDiscount > 0 && discount > coupon.MaxDiscount {
+			discount = coupon.MaxDiscount
+		}
+	case constants.CouponDiscountFixed:
+		discount = coupon.DiscountValue
+	}
+	if discount > basePrice {
+		discount = basePrice
+	}
+	return discount
+}
+
+// RedeemCoupon mencatat satu pemakaian kupon untuk seluruh item pesanan yang memakainya
+// dan menambah UsedCount sekali. Redemption dikaitkan ke transaksi pertama, dan setiap
+// transaksi menyimpan CouponRedemptionID agar pembatalan dapat mengembalikan kuota.
+func RedeemCoupon(tx *gorm.DB, coupon *db.Coupon, trxs []db.TransactionHistory) error {
+	if len(trxs) == 0 {
+		return nil
+	}
+	redemption := db.CouponRedemption{
+		CouponID:      coupon.ID,
+		UserID:        trxs[0].UserID,
+		TransactionID: trxs[0].ID,
+		Status:        constants.CouponRedemptionApplied,
+	}
+	trxIDs := make([]uint, 0, len(trxs))
+	for _, trx := range trxs {
+		redemption.DiscountAmount += trx.DiscountAmount
+		trxIDs = append(trxIDs, trx.ID)
+	}
+	if err := tx.Create(&redemption).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&db.TransactionHistory{}).Where("id IN ?", trxIDs).
+		Update("coupon_redemption_id", redemption.ID).Error; err != nil {
+		return err
+	}
+	coupon.UsedCount++
+	return tx.Model(coupon).Update("used_count", gorm.Expr("used_count + 1")).Error
+}
+
+// ReleaseCouponRedemption mengembalikan kuota kupon saat transaksi dibatalkan. Redemption
+// yang dipakai beberapa item pesanan baru dilepas saat item terakhirnya dibatalkan.
+func ReleaseCouponRedemption(tx *gorm.DB, trx db.TransactionHistory) error {
+	if trx.CouponID == nil {
+		return nil
+	}
+
+	query := tx.Model(&db.CouponRedemption{}).Where("status = ?", constants.CouponRedemptionApplied)
+	if trx.CouponRedemptionID != nil {
+		// Redemption dikunci agar dua item yang dibatalkan bersamaan tidak sama-sama
+		// melihat item lainnya masih aktif
+		var redemption db.CouponRedemption
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&redemption, *trx.CouponRedemptionID).Error; err != nil {
+			return err
+		}
+		var remaining int64
+		if err := tx.Model(&db.TransactionHistory{}).
+			Where("coupon_redemption_id = ? AND id <> ? AND status <> ?", redemption.ID, trx.ID, constants.TrxStatusCancel).
+			Count(&remaining).Error; err != nil {
+			return err
+		}
+		if remaining > 0 {
+			return nil
+		}
+		query = query.Where("id = ?", redemption.ID)
+	} else {
+		query = query.Where("transaction_id = ?", trx.ID)
+	}
+
+	result := query.Update("status", constants.CouponRedemptionReleased)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+	return tx.Model(&db.Coupon{}).
+		Where("id = ? AND used_count > 0", *trx.CouponID).
+		Update("used_count", gorm.Expr("used_count - 1")).Error
+}
+
+// SplitCSV memecah string dipisah koma dan membuang elemen kosong
+func SplitCSV(value string) []string {
+	var result []string
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+)
+
+func openTestDB(t *testing.T, models ...interface{}) *gorm.DB {
+	t.Helper()
+	dbConn, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger:                                   logger.Default.LogMode(logger.Silent),
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	if err != nil {
+		t.Fatalf("membuka database: %v", err)
+	}
+	if err := dbConn.AutoMigrate(models...); err != nil {
+		t.Fatalf("migrasi: %v", err)
+	}
+	return dbConn
+}
+
+func createCoupon(t *testing.T, dbConn *gorm.DB, coupon db.Coupon) db.Coupon {
+	t.Helper()
+	coupon.FundedBy = constants.CouponFundedBySeller
+	coupon.CreatedBy = 1
+	coupon.IsActive = true
+	if err := dbConn.Create(&coupon).Error; err != nil {
+		t.Fatal(err)
+	}
+	return coupon
+}
+
+func TestResolveCouponPerOrder(t *testing.T) {
+	dbConn := openTestDB(t, &db.Coupon{}, &db.CouponRedemption{})
+
+	sellerID := uint(1)
+	cable := db.Product{Model: gorm.Model{ID: 1}, UserID: sellerID, Title: "Kabel", Price: 40000}
+	charger := db.Product{Model: gorm.Model{ID: 2}, UserID: sellerID, Title: "Charger", Price: 80000}
+	otherSeller := db.Product{Model: gorm.Model{ID: 3}, UserID: 9, Title: "Laptop", Price: 5000000}
+
+	createCoupon(t, dbConn, db.Coupon{Code: "HEMAT10", DiscountType: constants.CouponDiscountPercentage, DiscountValue: 10, MaxDiscount: 10000, MinSpend: 100000, SellerID: &sellerID})
+	createCoupon(t, dbConn, db.Coupon{Code: "POTONG25", DiscountType: constants.CouponDiscountFixed, DiscountValue: 25000})
+
+	tests := []struct {
+		name          string
+		code          string
+		lines         []CouponLine
+		wantErr       string
+		wantDiscounts []uint
+	}{
+		{
+			name:          "item dalam cakupan memenuhi minimum bersama-sama",
+			code:          "hemat10",
+			lines:         []CouponLine{{cable, 40000}, {charger, 80000}},
+			wantDiscounts: []uint{3333, 6667},
+		},
+		{
+			name:    "item murah tidak lolos minimum walaupun pesanan lain mahal",
+			code:    "HEMAT10",
+			lines:   []CouponLine{{cable, 40000}},
+			wantErr: constants.ErrMsgCouponMinSpend,
+		},
+		{
+			name:    "item penjual lain ditolak",
+			code:    "HEMAT10",
+			lines:   []CouponLine{{cable, 40000}, {otherSeller, 5000000}},
+			wantErr: constants.ErrMsgCouponNotApplicable + ": Laptop",
+		},
+		{
+			name:          "potongan tetap dibagi, bukan dikalikan jumlah item",
+			code:          "POTONG25",
+			lines:         []CouponLine{{cable, 40000}, {charger, 80000}, {cable, 30000}},
+			wantDiscounts: []uint{6666, 13333, 5001},
+		},
+		{
+			name:          "potongan tetap tidak melebihi subtotal",
+			code:          "POTONG25",
+			lines:         []CouponLine{{cable, 10000}, {cable, 5000}},
+			wantDiscounts: []uint{10000, 5000},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, discounts, err := ResolveCoupon(dbConn, tt.code, 2, tt.lines, time.Now())
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("err = %v, ingin %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if len(discounts) != len(tt.wantDiscounts) {
+				t.Fatalf("potongan = %v, ingin %v", discounts, tt.wantDiscounts)
+			}
+			for i := range discounts {
+				if discounts[i] != tt.wantDiscounts[i] {
+					t.Fatalf("potongan = %v, ingin %v", discounts, tt.wantDiscounts)
+				}
+			}
+		})
+	}
+}
+
+func TestRedeemCouponOncePerOrder(t *testing.T) {
+	dbConn := openTestDB(t, &db.Coupon{}, &db.CouponRedemption{}, &db.TransactionHistory{})
+
+	coupon := createCoupon(t, dbConn, db.Coupon{Code: "SEKALI", DiscountType: constants.CouponDiscountFixed, DiscountValue: 25000, UsageLimit: 1, PerUserLimit: 1})
+	trxs := []db.TransactionHistory{
+		{ProductID: 1, UserID: 2, Quantity: 1, TotalPrice: 20000, DiscountAmount: 15000, Status: constants.TrxStatusPending, CouponID: &coupon.ID},
+		{ProductID: 2, UserID: 2, Quantity: 1, TotalPrice: 30000, DiscountAmount: 10000, Status: constants.TrxStatusPending, CouponID: &coupon.ID},
+	}
+	if err := dbConn.Create(&trxs).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := RedeemCoupon(dbConn, &coupon, trxs); err != nil {
+		t.Fatal(err)
+	}
+
+	var redemptions []db.CouponRedemption
+	dbConn.Find(&redemptions)
+	if len(redemptions) != 1 || redemptions[0].DiscountAmount != 25000 {
+		t.Fatalf("redemption = %+v, ingin satu redemption senilai 25000", redemptions)
+	}
+	var stored db.Coupon
+	dbConn.First(&stored, coupon.ID)
+	if stored.UsedCount != 1 {
+		t.Fatalf("used_count = %d, ingin 1", stored.UsedCount)
+	}
+
+	// Kuota baru kembali setelah item terakhir pesanan dibatalkan
+	for i := range trxs {
+		dbConn.First(&trxs[i], trxs[i].ID)
+		if err := ReleaseCouponRedemption(dbConn, trxs[i]); err != nil {
+			t.Fatal(err)
+		}
+		dbConn.Model(&trxs[i]).Update("status", constants.TrxStatusCancel)
+
+		dbConn.First(&stored, coupon.ID)
+		want := uint(1)
+		if i == len(trxs)-1 {
+			want = 0
+		}
+		if stored.UsedCount != want {
+			t.Fatalf("used_count setelah membatalkan item %d = %d, ingin %d", i+1, stored.UsedCount, want)
+		}
+	}
+	var released db.CouponRedemption
+	dbConn.First(&released, redemptions[0].ID)
+	if released.Status != constants.CouponRedemptionReleased {
+		t.Fatalf("status redemption = %s, ingin %s", released.Status, constants.CouponRedemptionReleased)
+	}
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/notify"
+)
+
+// StockChange menjelaskan satu perubahan stok beserta penyebabnya
+type StockChange struct {
+	Delta         int
+	Cause         constants.InventoryCause
+	Note          string
+	ActorID       *uint
+	TransactionID *uint
+}
+
+// AdjustStock mengubah stok produk secara atomik dan mencatatnya di InventoryMovement.
+// Fungsi ini juga mengatur visibilitas saat stok habis/tersedia kembali, mengirim
+// peringatan stok menipis ke penjual, dan notifikasi restock ke pelanggan.
+func AdjustStock(tx *gorm.DB, product *db.Product, change StockChange) error {
+	if change.Delta == 0 {
+		return nil
+	}
+
+	// Unscoped agar pembatalan transaksi produk yang sudah dihapus tetap mengembalikan stok.
+	query := tx.Model(&db.Product{}).Unscoped().Where("id = ?", product.ID)
+	if change.Delta < 0 {
+		query = query.Where("stock >= ?", -change.Delta)
+	}
+	result := query.Update("stock", gorm.Expr("stock + ?", change.Delta))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf(constants.ErrMsgInsufficientStock+" untuk produk %s", product.Title)
+	}
+
+	var current db.Product
+	if err := tx.Unscoped().First(&current, product.ID).Error; err != nil {
+		return err
+	}
+	stockAfter := current.Stock
+	stockBefore := uint(int(stockAfter) - change.Delta)
+
+	movement := db.InventoryMovement{
+		ProductID:     product.ID,
+		Change:        change.Delta,
+		StockBefore:   stockBefore,
+		StockAfter:    stockAfter,
+		Cause:         change.Cause,
+		Note:          change.Note,
+		ActorID:       change.ActorID,
+		TransactionID: change.TransactionID,
+	}
+	if err := tx.Create(&movement).Error; err != nil {
+		return err
+	}
+
+	if stockAfter == 0 && current.Visibility == constants.ProductVisibilityAll {
+		if err := SetProductVisibility(tx, &current, constants.ProductVisibilityOwnerAdmin, constants.VisibilityReasonSoldOut, change.ActorID); err != nil {
+			return err
+		}
+	} else if stockAfter > 0 && hiddenForSoldOut(current, stockBefore) {
+		if err := SetProductVisibility(tx, &current, constants.ProductVisibilityAll, constants.VisibilityReasonRestocked, change.ActorID); err != nil {
+			return err
+		}
+	}
+
+	product.Stock = current.Stock
+	product.Visibility = current.Visibility
+	product.VisibilityReason = current.VisibilityReason
+
+	if err := notifyLowStock(tx, current, stockBefore); err != nil {
+		return err
+	}
+	if stockBefore == 0 && stockAfter > 0 && current.IsActive && !current.DeletedAt.Valid && current.Visibility == constants.ProductVisibilityAll {
+		if err := NotifyRestockSubscribers(tx, current); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecordInitialStock mencatat stok awal produk yang baru dibuat
+func RecordInitialStock(tx *gorm.DB, product db.Product, cause constants.InventoryCause, actorID *uint) error {
+	movement := db.InventoryMovement{
+		ProductID:   product.ID,
+		Change:      int(product.Stock),
+		StockBefore: 0,
+		StockAfter:  product.Stock,
+		Cause:       cause,
+		ActorID:     actorID,
+	}
+	return tx.Create(&movement).Error
+}
+
+// notifyLowStock memberi tahu penjual hanya saat stok melewati ambang batas dari atas
+// atau saat stok habis, sehingga penjualan berikutnya tidak mengirim notifikasi berulang.
+func notifyLowStock(tx *gorm.DB, product db.Product, stockBefore uint) error {
+	soldOut := stockBefore > 0 && product.Stock == 0
+	crossedThreshold := product.LowStockThreshold > 0 &&
+		stockBefore > product.LowStockThreshold && product.Stock <= product.LowStockThreshold
+	if !soldOut && !crossedThreshold {
+		return nil
+	}
+
+	messageKey := "notification.low_stock"
+	if product.Stock == 0 {
+		messageKey = "notification.sold_out"
+	}
+
+	notification := db.Notification{
+		UserID:        product.UserID,
+		Type:          constants.NotifTypeLowStock,
+		MessageKey:    messageKey,
+		MessageParams: db.JSONB{"product": product.Title, "stock": product.Stock, "threshold": product.LowStockThreshold},
+		RelatedID:     &product.ID,
+	}
+	return notify.Send(tx, &notification)
+}
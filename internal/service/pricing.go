@@ -0,0 +1,135 @@
+package service
+
+import (
+	"gorm.io/gorm"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+)
+
+// PriceChange menjelaskan satu perubahan harga produk beserta alasannya.
+// PreviousPrice diisi hanya saat harga baru merupakan potongan harga sementara
+// sehingga listing dapat menampilkan harga "sebelum/sesudah".
+type PriceChange struct {
+	NewPrice      uint
+	PreviousPrice *uint
+	Reason        constants.PriceChangeReason
+	ChangedBy     *uint
+	ScheduleID    *uint
+}
+
+// ChangeProductPrice memperbarui harga produk dan mencatatnya di ProductPriceHistory.
+// Harus dipanggil di dalam transaksi yang sama dengan perubahan lain pada produk.
+func ChangeProductPrice(tx *gorm.DB, product *db.Product, change PriceChange) error {
+	oldPrice := product.Price
+	if oldPrice == change.NewPrice && samePrice(product.PreviousPrice, change.PreviousPrice) {
+		return nil
+	}
+
+	if err := tx.Model(product).Updates(map[string]interface{}{
+		"price":          change.NewPrice,
+		"previous_price": change.PreviousPrice,
+	}).Error; err != nil {
+		return err
+	}
+	product.Price = change.NewPrice
+	product.PreviousPrice = change.PreviousPrice
+
+	if oldPrice == change.NewPrice {
+		return nil
+	}
+
+	history := db.ProductPriceHistory{
+		ProductID:  product.ID,
+		OldPrice:   oldPrice,
+		NewPrice:   change.NewPrice,
+		Reason:     change.Reason,
+		ChangedBy:  change.ChangedBy,
+		ScheduleID: change.ScheduleID,
+	}
+	return tx.Create(&history).Error
+}
+
+// CancelActivePriceSchedules membatalkan jadwal harga yang sedang berjalan pada produk.
+// Dipakai ketika harga diubah secara manual agar job tidak mengembalikan harga lama.
+func CancelActivePriceSchedules(tx *gorm.DB, productID uint) error {
+	return tx.Model(&db.ProductPriceSchedule{}).
+		Where("product_id = ? AND status = ?", productID, constants.PriceScheduleActive).
+		Update("status", constants.PriceScheduleCanceled).Error
+}
+
+// StartPriceSchedule menerapkan harga dari jadwal yang sudah jatuh tempo.
+// Jadwal tanpa EndAt dianggap perubahan harga permanen dan langsung selesai.
+func StartPriceSchedule(tx *gorm.DB, schedule *db.ProductPriceSchedule) error {
+	var product db.Product
+	if err := tx.First(&product, schedule.ProductID).Error; err != nil {
+		return err
+	}
+
+	// Jadwal lain yang masih aktif dianggap selesai karena harga akan ditimpa.
+	if err := tx.Model(&db.ProductPriceSchedule{}).
+		Where("product_id = ? AND status = ? AND id <> ?", product.ID, constants.PriceScheduleActive, schedule.ID).
+		Update("status", constants.PriceScheduleCompleted).Error; err != nil {
+		return err
+	}
+
+	change := PriceChange{
+		NewPrice:   schedule.Price,
+		Reason:     constants.PriceChangeScheduleStart,
+		ChangedBy:  &schedule.UserID,
+		ScheduleID: &schedule.ID,
+	}
+	status := constants.PriceScheduleCompleted
+	if schedule.EndAt != nil {
+		status = constants.PriceScheduleActive
+		basePrice := product.Price
+		if product.PreviousPrice != nil {
+			basePrice = *product.PreviousPrice
+		}
+		schedule.OriginalPrice = basePrice
+		if schedule.Price < basePrice {
+			change.PreviousPrice = &basePrice
+		}
+	}
+
+	if err := ChangeProductPrice(tx, &product, change); err != nil {
+		return err
+	}
+
+	schedule.Status = status
+	return tx.Model(schedule).Updates(map[string]interface{}{
+		"status":         schedule.Status,
+		"original_price": schedule.OriginalPrice,
+	}).Error
+}
+
+// EndPriceSchedule mengakhiri jadwal harga aktif dan mengembalikan harga semula.
+// Harga hanya dikembalikan bila belum diubah pihak lain sejak jadwal dimulai.
+func EndPriceSchedule(tx *gorm.DB, schedule *db.ProductPriceSchedule, status constants.PriceScheduleStatus, reason constants.PriceChangeReason, changedBy *uint) error {
+	var product db.Product
+	if err := tx.First(&product, schedule.ProductID).Error; err != nil {
+		return err
+	}
+
+	if product.Price == schedule.Price {
+		change := PriceChange{
+			NewPrice:   schedule.OriginalPrice,
+			Reason:     reason,
+			ChangedBy:  changedBy,
+			ScheduleID: &schedule.ID,
+		}
+		if err := ChangeProductPrice(tx, &product, change); err != nil {
+			return err
+		}
+	}
+
+	schedule.Status = status
+	return tx.Model(schedule).Update("status", status).Error
+}
+
+func samePrice(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"fmt"
+	"mime/multipart"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"portolio-backend/configs"
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/util"
+)
+
+const productMediaDir = "media/products"
+
+// MaxProductImages adalah batas jumlah gambar per produk, dapat diatur melalui MAX_PRODUCT_IMAGES
+func MaxProductImages() int {
+	return configs.GetEnvInt("MAX_PRODUCT_IMAGES", constants.DefaultMaxProductImages)
+}
+
+// OrderedImages dipakai pada Preload("Images", ...) agar gambar selalu urut sesuai posisi
+func OrderedImages(tx *gorm.DB) *gorm.DB {
+	return tx.Order("position ASC, id ASC")
+}
+
+// PrimaryImageURL mengembalikan gambar utama produk, atau gambar siap pakai dengan posisi
+// terkecil untuk data lama yang belum memiliki gambar utama. Gambar yang masih diunduh
+// atau gagal diunduh dilewati.
+func PrimaryImageURL(images []db.ProductImage) string {
+	var first *db.ProductImage
+	for i, img := range images {
+		if img.Status != constants.ProductImageReady && img.Status != "" {
+			continue
+		}
+		if img.IsPrimary {
+			return img.ImageURL
+		}
+		if first == nil || img.Position < first.Position || (img.Position == first.Position && img.ID < first.ID) {
+			first = &images[i]
+		}
+	}
+	if first == nil {
+		return ""
+	}
+	return first.ImageURL
+}
+
+// AddProductImages menambahkan gambar di urutan terakhir. Gambar pertama produk
+// otomatis menjadi gambar utama. Gagal jika melebihi batas gambar per produk.
+func AddProductImages(tx *gorm.DB, productID uint, images []db.ProductImage) error {
+	if len(images) == 0 {
+		return nil
+	}
+
+	var existing []db.ProductImage
+	if err := tx.Where("product_id = ?", productID).Find(&existing).Error; err != nil {
+		return err
+	}
+	if len(existing)+len(images) > MaxProductImages() {
+		return fmt.Errorf(constants.ErrMsgProductImageLimit+" (maksimal %d)", MaxProductImages())
+	}
+
+	nextPosition := 0
+	hasPrimary := false
+	for _, img := range existing {
+		if img.Position >= nextPosition {
+			nextPosition = img.Position + 1
+		}
+		if img.IsPrimary {
+			hasPrimary = true
+		}
+	}
+
+	for i := range images {
+		images[i].ProductID = productID
+		images[i].Position = nextPosition + i
+		images[i].IsPrimary = !hasPrimary && i == 0
+	}
+	return tx.Create(&images).Error
+}
+
+// SetPrimaryImage menjadikan satu gambar sebagai gambar utama produk
+func SetPrimaryImage(tx *gorm.DB, image *db.ProductImage) error {
+	if err := tx.Model(&db.ProductImage{}).
+		Where("product_id = ? AND id <> ?", image.ProductID, image.ID).
+		Update("is_primary", false).Error; err != nil {
+		return err
+	}
+	image.IsPrimary = true
+	return tx.Model(image).Update("is_primary", true).Error
+}
+
+// ReorderProductImages menyusun ulang posisi gambar. imageIDs harus berisi
+// seluruh gambar produk tepat satu kali.
+func ReorderProductImages(tx *gorm.DB, productID uint, imageIDs []uint) error {
+	var existingIDs []uint
+	if err := tx.Model(&db.ProductImage{}).Where("product_id = ?", productID).Pluck("id", &existingIDs).Error; err != nil {
+		return err
+	}
+	if len(existingIDs) != len(imageIDs) {
+		return fmt.Errorf(constants.ErrMsgProductImageOrderInvalid)
+	}
+
+	owned := make(map[uint]bool, len(existingIDs))
+	for _, id := range existingIDs {
+		owned[id] = true
+	}
+	for _, id := range imageIDs {
+		if !owned[id] {
+			return fmt.Errorf(constants.ErrMsgProductImageOrderInvalid)
+		}
+		delete(owned, id)
+	}
+
+	for position, id := range imageIDs {
+		if err := tx.Model(&db.ProductImage{}).Where("id = ?", id).Update("position", position).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteProductImage menghapus gambar, merapikan posisi gambar lainnya, dan memilih
+// gambar pertama sebagai gambar utama baru bila yang dihapus adalah gambar utama.
+// File dihapus oleh pemanggil melalui RemoveProductImageFiles setelah transaksi berhasil.
+func DeleteProductImage(tx *gorm.DB, image db.ProductImage) error {
+	if err := tx.Unscoped().Delete(&image).Error; err != nil {
+		return err
+	}
+
+	var remaining []db.ProductImage
+	if err := tx.Where("product_id = ?", image.ProductID).Order("position ASC, id ASC").Find(&remaining).Error; err != nil {
+		return err
+	}
+	for position, img := range remaining {
+		updates := map[string]interface{}{"position": position}
+		if image.IsPrimary && position == 0 {
+			updates["is_primary"] = true
+		}
+		if err := tx.Model(&db.ProductImage{}).Where("id = ?", img.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveProductImageUpload memproses gambar yang diunggah menjadi varian thumbnail,
+// medium, dan full di media/products. File asli hanya disimpan sementara.
+func SaveProductImageUpload(file *multipart.FileHeader) (db.ProductImage, error) {
+	tempPath, err := util.StageUploadedFile(file, int64(util.GetMaxImageSizeMB())*1024*1024, util.AllowedImageExtensions)
+	if err != nil {
+		return db.ProductImage{}, err
+	}
+	return processProductImage(tempPath)
+}
+
+// PendingProductImage membuat gambar berstatus pending untuk link yang akan diunduh
+// di latar belakang oleh jobs.RunImageFetcher. URL yang jelas diblokir langsung ditolak.
+func PendingProductImage(link string) (db.ProductImage, error) {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return db.ProductImage{}, fmt.Errorf(constants.ErrMsgRemoteURLBlocked)
+	}
+	if err := util.ValidateRemoteURL(u); err != nil {
+		return db.ProductImage{}, err
+	}
+	return db.ProductImage{
+		SourceURL: u.String(),
+		Status:    constants.ProductImagePending,
+	}, nil
+}
+
+// DownloadProductImage mengunduh gambar dari URL lalu memprosesnya seperti SaveProductImageUpload
+func DownloadProductImage(link string) (db.ProductImage, error) {
+	tempPath, err := util.StageRemoteImage(link, int64(util.GetMaxImageSizeMB())*1024*1024)
+	if err != nil {
+		return db.ProductImage{}, err
+	}
+	return processProductImage(tempPath)
+}
+
+func processProductImage(tempPath string) (db.ProductImage, error) {
+	defer os.Remove(tempPath)
+
+	paths, err := util.ProcessImage(tempPath, constants.StagingDir, util.ProductImageVariants)
+	if err != nil {
+		return db.ProductImage{}, err
+	}
+	defer func() {
+		for _, localPath := range paths {
+			os.Remove(localPath)
+		}
+	}()
+
+	// Varian diproses di disk lokal lalu diunggah ke MediaStorage dengan key media/products/...
+	keys := make(map[string]string, len(paths))
+	for name, localPath := range paths {
+		key := path.Join(productMediaDir, filepath.Base(localPath))
+		if err := util.PutLocalFile(key, localPath); err != nil {
+			for _, uploaded := range keys {
+				util.DeleteMedia(uploaded)
+			}
+			return db.ProductImage{}, err
+		}
+		keys[name] = key
+	}
+
+	return db.ProductImage{
+		Status:       constants.ProductImageReady,
+		ImageURL:     keys["full"],
+		MediumURL:    keys["medium"],
+		ThumbnailURL: keys["thumb"],
+	}, nil
+}
+
+// RemoveProductImageFiles menghapus file gambar beserta variannya dari media/products
+// jika tidak lagi dipakai gambar lain (data seed memakai file yang sama untuk beberapa
+// produk). URL di luar direktori tersebut diabaikan.
+func RemoveProductImageFiles(dbConn *gorm.DB, image db.ProductImage) error {
+	if image.ImageURL == "" {
+		return nil
+	}
+
+	var references int64
+	if err := dbConn.Model(&db.ProductImage{}).
+		Where("image_url IN ?", []string{image.ImageURL, "/" + strings.TrimPrefix(image.ImageURL, "/")}).
+		Count(&references).Error; err != nil {
+		return err
+	}
+	if references > 0 {
+		return nil
+	}
+
+	for _, imageURL := range []string{image.ImageURL, image.MediumURL, image.ThumbnailURL} {
+		if imageURL == "" {
+			continue
+		}
+		key := path.Clean(strings.TrimPrefix(imageURL, "/"))
+		if path.Dir(key) != productMediaDir {
+			continue
+		}
+		if err := util.DeleteMedia(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
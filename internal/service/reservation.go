@@ -0,0 +1,147 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+)
+
+// AvailableStock adalah stok yang masih bisa dibeli atau direservasi,
+// yaitu stok fisik dikurangi stok yang sedang ditahan reservasi.
+func AvailableStock(product db.Product) uint {
+	if product.ReservedStock >= product.Stock {
+		return 0
+	}
+	return product.Stock - product.ReservedStock
+}
+
+// ReserveStock menahan stok produk untuk pengguna selama ttl.
+// Baris produk dikunci agar dua checkout bersamaan tidak menahan stok yang sama.
+func ReserveStock(tx *gorm.DB, userID, productID, quantity uint, ttl time.Duration) (*db.StockReservation, error) {
+	var product db.Product
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND visibility = ? AND is_active = ?", productID, constants.ProductVisibilityAll, true).
+		First(&product).Error; err != nil {
+		return nil, fmt.Errorf(constants.ErrMsgProductNotFound+" (ID: %d)", productID)
+	}
+	if product.UserID == userID {
+		return nil, fmt.Errorf(constants.ErrMsgProductSelfPurchase+": %s", product.Title)
+	}
+	if AvailableStock(product) < quantity {
+		return nil, fmt.Errorf(constants.ErrMsgInsufficientStock+" untuk produk %s", product.Title)
+	}
+
+	if err := tx.Model(&product).Update("reserved_stock", gorm.Expr("reserved_stock + ?", quantity)).Error; err != nil {
+		return nil, err
+	}
+
+	reservation := db.StockReservation{
+		UserID:    userID,
+		ProductID: product.ID,
+		Quantity:  quantity,
+		Status:    constants.ReservationActive,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := tx.Create(&reservation).Error; err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// LockReservation mengunci produk lalu reservasi aktifnya, urutan yang sama dengan
+// PostPurchaseProduct yang mengunci produk sebelum LockActiveReservation, agar pelepasan
+// dan pembelian yang bersamaan tidak saling menunggu. userID 0 berarti reservasi milik
+// siapa pun. Mengembalikan nil bila reservasi tidak lagi aktif atau, dengan skipLocked,
+// produk maupun reservasinya sedang dikunci proses lain.
+func LockReservation(tx *gorm.DB, reservationID, userID uint, skipLocked bool) (*db.StockReservation, error) {
+	locking := clause.Locking{Strength: "UPDATE"}
+	if skipLocked {
+		locking.Options = "SKIP LOCKED"
+	}
+	findActive := func(query *gorm.DB, reservation *db.StockReservation) (bool, error) {
+		query = query.Where("id = ? AND status = ?", reservationID, constants.ReservationActive)
+		if userID != 0 {
+			query = query.Where("user_id = ?", userID)
+		}
+		result := query.Limit(1).Find(reservation)
+		return result.RowsAffected == 1, result.Error
+	}
+
+	var reservation db.StockReservation
+	if found, err := findActive(tx, &reservation); err != nil || !found {
+		return nil, err
+	}
+
+	var product db.Product
+	result := tx.Unscoped().Clauses(locking).Where("id = ?", reservation.ProductID).Limit(1).Find(&product)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+
+	// Reservasi dibaca ulang setelah produk dikunci karena statusnya bisa berubah selama menunggu
+	reservation = db.StockReservation{}
+	if found, err := findActive(tx.Clauses(locking), &reservation); err != nil || !found {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// ReleaseReservation melepas stok yang ditahan reservasi aktif dengan status akhir
+// released (dibatalkan pengguna) atau expired (dilepas sweeper). Status hanya diubah bila
+// reservasi masih aktif di database, dan stok yang ditahan hanya dikurangi bila perubahan
+// itu berhasil, sehingga pembatalan yang bersamaan dengan sweeper atau pembelian tidak
+// mengurangi stok dua kali maupun menimpa status converted. Pemanggil mengunci baris
+// dengan LockReservation terlebih dahulu.
+func ReleaseReservation(tx *gorm.DB, reservation *db.StockReservation, status constants.ReservationStatus) error {
+	if reservation.Status != constants.ReservationActive {
+		return nil
+	}
+
+	result := tx.Model(&db.StockReservation{}).
+		Where("id = ? AND status = ?", reservation.ID, constants.ReservationActive).
+		Update("status", status)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected != 1 {
+		return nil
+	}
+	reservation.Status = status
+
+	return tx.Model(&db.Product{}).Unscoped().
+		Where("id = ?", reservation.ProductID).
+		Update("reserved_stock", gorm.Expr("CASE WHEN reserved_stock >= ? THEN reserved_stock - ? ELSE 0 END", reservation.Quantity, reservation.Quantity)).Error
+}
+
+// LockActiveReservation mengambil reservasi aktif milik pengguna untuk dikonversi menjadi pembelian
+func LockActiveReservation(tx *gorm.DB, reservationID, userID, productID, quantity uint) (*db.StockReservation, error) {
+	var reservation db.StockReservation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND user_id = ? AND status = ?", reservationID, userID, constants.ReservationActive).
+		First(&reservation).Error; err != nil {
+		return nil, fmt.Errorf(constants.ErrMsgReservationNotFound)
+	}
+	if !reservation.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf(constants.ErrMsgReservationExpired)
+	}
+	if reservation.ProductID != productID || reservation.Quantity != quantity {
+		return nil, fmt.Errorf(constants.ErrMsgReservationMismatch)
+	}
+	return &reservation, nil
+}
+
+// ConvertReservation menandai reservasi sebagai terbayar. Stok fisik dan stok
+// yang ditahan sudah dikurangi oleh pemanggil saat pembelian dibuat.
+func ConvertReservation(tx *gorm.DB, reservation *db.StockReservation, transactionID uint) error {
+	reservation.Status = constants.ReservationConverted
+	reservation.TransactionID = &transactionID
+	return tx.Model(reservation).Updates(map[string]interface{}{
+		"status":         reservation.Status,
+		"transaction_id": transactionID,
+	}).Error
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+)
+
+func TestReleaseReservationOnlyOnce(t *testing.T) {
+	dbConn := openTestDB(t, &db.Product{}, &db.StockReservation{})
+
+	product := db.Product{UserID: 1, Title: "Kabel", Price: 40000, Stock: 10, ReservedStock: 5, IsActive: true}
+	if err := dbConn.Create(&product).Error; err != nil {
+		t.Fatal(err)
+	}
+	reservation := db.StockReservation{
+		UserID:    2,
+		ProductID: product.ID,
+		Quantity:  3,
+		Status:    constants.ReservationActive,
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+	if err := dbConn.Create(&reservation).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// Salinan kedua mewakili pembatalan yang membaca reservasi sebelum sweeper selesai
+	stale := reservation
+	if err := ReleaseReservation(dbConn, &reservation, constants.ReservationExpired); err != nil {
+		t.Fatal(err)
+	}
+	if err := ReleaseReservation(dbConn, &stale, constants.ReservationReleased); err != nil {
+		t.Fatal(err)
+	}
+
+	var got db.Product
+	dbConn.First(&got, product.ID)
+	if got.ReservedStock != 2 {
+		t.Fatalf("reserved_stock = %d, ingin 2", got.ReservedStock)
+	}
+	var stored db.StockReservation
+	dbConn.First(&stored, reservation.ID)
+	if stored.Status != constants.ReservationExpired {
+		t.Fatalf("status = %s, ingin %s", stored.Status, constants.ReservationExpired)
+	}
+
+	// Reservasi yang sudah dikonversi tidak boleh ditimpa menjadi released
+	converted := db.StockReservation{UserID: 2, ProductID: product.ID, Quantity: 2, Status: constants.ReservationActive, ExpiresAt: time.Now().Add(time.Minute)}
+	if err := dbConn.Create(&converted).Error; err != nil {
+		t.Fatal(err)
+	}
+	stale = converted
+	if err := ConvertReservation(dbConn, &converted, 7); err != nil {
+		t.Fatal(err)
+	}
+	if err := ReleaseReservation(dbConn, &stale, constants.ReservationReleased); err != nil {
+		t.Fatal(err)
+	}
+	var storedConverted db.StockReservation
+	dbConn.First(&storedConverted, converted.ID)
+	if storedConverted.Status != constants.ReservationConverted {
+		t.Fatalf("status = %s, ingin %s", storedConverted.Status, constants.ReservationConverted)
+	}
+	dbConn.First(&got, product.ID)
+	if got.ReservedStock != 2 {
+		t.Fatalf("reserved_stock = %d, ingin 2 setelah konversi", got.ReservedStock)
+	}
+}
+
+func TestLockReservationOnlyReturnsActiveOwnReservation(t *testing.T) {
+	dbConn := openTestDB(t, &db.Product{}, &db.StockReservation{})
+
+	product := db.Product{UserID: 1, Title: "Kabel", Price: 40000, Stock: 10, ReservedStock: 3, IsActive: true}
+	if err := dbConn.Create(&product).Error; err != nil {
+		t.Fatal(err)
+	}
+	reservation := db.StockReservation{UserID: 2, ProductID: product.ID, Quantity: 3, Status: constants.ReservationActive, ExpiresAt: time.Now().Add(time.Minute)}
+	if err := dbConn.Create(&reservation).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// Produk yang sudah dihapus tetap dikunci agar reservasinya bisa dilepas
+	if err := dbConn.Delete(&product).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		userID    uint
+		wantFound bool
+	}{
+		{name: "pemilik reservasi", userID: 2, wantFound: true},
+		{name: "sweeper tanpa pengguna", userID: 0, wantFound: true},
+		{name: "pengguna lain", userID: 3, wantFound: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locked, err := LockReservation(dbConn, reservation.ID, tt.userID, tt.userID == 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (locked != nil) != tt.wantFound {
+				t.Fatalf("reservasi = %+v, ingin ditemukan = %v", locked, tt.wantFound)
+			}
+			if locked != nil && (locked.ID != reservation.ID || locked.Quantity != 3) {
+				t.Fatalf("reservasi = %+v", locked)
+			}
+		})
+	}
+
+	if err := ReleaseReservation(dbConn, &reservation, constants.ReservationReleased); err != nil {
+		t.Fatal(err)
+	}
+	locked, err := LockReservation(dbConn, reservation.ID, 2, false)
+	if err != nil || locked != nil {
+		t.Fatalf("reservasi yang sudah dilepas = %+v, %v; ingin nil", locked, err)
+	}
+}
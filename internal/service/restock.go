@@ -0,0 +1,43 @@
+package service
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/notify"
+)
+
+// NotifyRestockSubscribers memberi tahu pelanggan restock bahwa produk kembali tersedia.
+// Langganan bersifat sekali pakai: setelah dikirim NotifiedAt diisi dan pengguna
+// perlu berlangganan ulang untuk mendapat notifikasi berikutnya.
+func NotifyRestockSubscribers(tx *gorm.DB, product db.Product) error {
+	var subscriptions []db.RestockSubscription
+	if err := tx.Where("product_id = ? AND notified_at IS NULL", product.ID).Find(&subscriptions).Error; err != nil {
+		return err
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	ids := make([]uint, len(subscriptions))
+	for i, sub := range subscriptions {
+		ids[i] = sub.ID
+
+		notification := db.Notification{
+			UserID:        sub.UserID,
+			Type:          constants.NotifTypeRestock,
+			MessageKey:    "notification.restock",
+			MessageParams: db.JSONB{"product": product.Title, "stock": product.Stock},
+			RelatedID:     &product.ID,
+		}
+		if err := notify.Send(tx, &notification); err != nil {
+			return err
+		}
+	}
+
+	return tx.Model(&db.RestockSubscription{}).Where("id IN ?", ids).Update("notified_at", now).Error
+}
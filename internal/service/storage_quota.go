@@ -0,0 +1,103 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"portolio-backend/configs"
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/model/dto"
+)
+
+const bytesPerMB = 1024 * 1024
+
+// RoleStorageQuota mengembalikan kuota penyimpanan bawaan (byte) untuk role, diatur melalui
+// STORAGE_QUOTA_MB_USER dan STORAGE_QUOTA_MB_ADMIN.
+func RoleStorageQuota(role constants.UserRole) int64 {
+	if role == constants.RoleAdmin {
+		return int64(configs.GetEnvInt("STORAGE_QUOTA_MB_ADMIN", constants.DefaultStorageQuotaMBAdmin)) * bytesPerMB
+	}
+	return int64(configs.GetEnvInt("STORAGE_QUOTA_MB_USER", constants.DefaultStorageQuotaMBUser)) * bytesPerMB
+}
+
+// StorageQuota mengembalikan kuota efektif pengguna: kuota khusus dari admin bila ada,
+// selain itu kuota bawaan role.
+func StorageQuota(user db.User) int64 {
+	if user.StorageQuotaBytes != nil {
+		return *user.StorageQuotaBytes
+	}
+	return RoleStorageQuota(user.Role)
+}
+
+// CheckStorageQuota memeriksa apakah file berukuran size masih muat dalam kuota pengguna
+// tanpa mengubah pemakaian. Dipakai sebelum file disimpan agar unggahan yang pasti
+// ditolak tidak sempat ditulis ke storage.
+func CheckStorageQuota(user db.User, size int64) error {
+	quota := StorageQuota(user)
+	if user.StorageUsedBytes+size > quota {
+		return storageQuotaError(user.StorageUsedBytes, quota)
+	}
+	return nil
+}
+
+// ReserveStorage menambah pemakaian penyimpanan pengguna sebesar size. Baris pengguna
+// dikunci sehingga unggahan bersamaan tidak dapat melewati kuota.
+func ReserveStorage(tx *gorm.DB, userID uint, size int64) error {
+	var user db.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+		return err
+	}
+	if err := CheckStorageQuota(user, size); err != nil {
+		return err
+	}
+	return tx.Model(&db.User{}).Where("id = ?", userID).
+		Update("storage_used_bytes", gorm.Expr("storage_used_bytes + ?", size)).Error
+}
+
+// ReleaseStorage mengurangi pemakaian penyimpanan pengguna setelah file dihapus
+func ReleaseStorage(tx *gorm.DB, userID uint, size int64) error {
+	return tx.Unscoped().Model(&db.User{}).Where("id = ?", userID).
+		Update("storage_used_bytes", gorm.Expr("GREATEST(storage_used_bytes - ?, 0)", size)).Error
+}
+
+// CheckUploadRateLimit membatasi jumlah unggahan per pengguna dalam satu jendela waktu
+// (UPLOAD_RATE_LIMIT per UPLOAD_RATE_WINDOW). Unggahan dihitung dari tabel Upload,
+// termasuk yang sudah dihapus, sehingga batas berlaku untuk semua instance API.
+func CheckUploadRateLimit(tx *gorm.DB, userID uint, now time.Time) error {
+	limit := configs.GetEnvInt("UPLOAD_RATE_LIMIT", constants.DefaultUploadRateLimit)
+	if limit <= 0 {
+		return nil
+	}
+	window := configs.GetEnvDuration("UPLOAD_RATE_WINDOW", constants.DefaultUploadRateWindow)
+
+	var count int64
+	if err := tx.Unscoped().Model(&db.Upload{}).
+		Where("user_id = ? AND created_at > ?", userID, now.Add(-window)).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count >= int64(limit) {
+		return fmt.Errorf(constants.ErrMsgUploadRateLimited)
+	}
+	return nil
+}
+
+// StorageUsage merangkum pemakaian dan kuota penyimpanan pengguna untuk respons API
+func StorageUsage(user db.User) dto.StorageUsageResponse {
+	quota := StorageQuota(user)
+	return dto.StorageUsageResponse{
+		UsedBytes:      user.StorageUsedBytes,
+		QuotaBytes:     quota,
+		RemainingBytes: max(quota-user.StorageUsedBytes, 0),
+		IsCustomQuota:  user.StorageQuotaBytes != nil,
+	}
+}
+
+func storageQuotaError(used, quota int64) error {
+	return fmt.Errorf("%s Terpakai %.1f MB dari %.1f MB.", constants.ErrMsgStorageQuotaExceeded,
+		float64(used)/bytesPerMB, float64(quota)/bytesPerMB)
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+)
+
+// ClaimUpload memastikan fileURL adalah unggahan milik userID dengan tujuan purpose,
+// lalu menandainya dipakai oleh referencedBy (misalnya "chat_message:12") agar tidak
+// dihapus oleh garbage collector. Pemilik boleh memakai file yang sama lebih dari sekali;
+// ReferencedBy tetap berisi referensi pertama.
+func ClaimUpload(tx *gorm.DB, userID uint, purpose constants.UploadPurpose, fileURL, referencedBy string) error {
+	var upload db.Upload
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("file_url = ? AND user_id = ? AND purpose = ?", fileURL, userID, purpose).
+		Limit(1).Find(&upload)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf(constants.ErrMsgUploadNotOwned)
+	}
+	if upload.ReferencedBy != "" {
+		return nil
+	}
+
+	now := time.Now()
+	return tx.Model(&upload).Updates(map[string]interface{}{
+		"referenced_by": referencedBy,
+		"referenced_at": &now,
+	}).Error
+}
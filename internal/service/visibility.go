@@ -0,0 +1,50 @@
+package service
+
+import (
+	"gorm.io/gorm"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+)
+
+// SetProductVisibility mengubah visibilitas produk beserta alasannya dan mencatat
+// perubahan di ProductVisibilityLog. Alasan disimpan di produk agar stok yang
+// kembali tersedia hanya menampilkan ulang produk yang disembunyikan karena habis.
+func SetProductVisibility(tx *gorm.DB, product *db.Product, visibility constants.ProductVisibility, reason constants.VisibilityReason, actorID *uint) error {
+	if product.Visibility == visibility && product.VisibilityReason == reason {
+		return nil
+	}
+
+	from := product.Visibility
+	if err := tx.Model(&db.Product{}).Unscoped().Where("id = ?", product.ID).Updates(map[string]interface{}{
+		"visibility":        visibility,
+		"visibility_reason": reason,
+	}).Error; err != nil {
+		return err
+	}
+	product.Visibility = visibility
+	product.VisibilityReason = reason
+
+	if from == visibility {
+		return nil
+	}
+
+	log := db.ProductVisibilityLog{
+		ProductID:      product.ID,
+		FromVisibility: from,
+		ToVisibility:   visibility,
+		Reason:         reason,
+		ActorID:        actorID,
+	}
+	return tx.Create(&log).Error
+}
+
+// hiddenForSoldOut bernilai true bila produk disembunyikan otomatis karena stok habis.
+// Produk lama tanpa alasan tersimpan dianggap habis bila stoknya 0.
+func hiddenForSoldOut(product db.Product, stockBefore uint) bool {
+	if product.Visibility != constants.ProductVisibilityOwnerAdmin || product.DeletedAt.Valid {
+		return false
+	}
+	return product.VisibilityReason == constants.VisibilityReasonSoldOut ||
+		(product.VisibilityReason == "" && stockBefore == 0)
+}
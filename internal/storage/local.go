@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// Local menyimpan file di disk, cocok untuk satu instance atau volume bersama
+type Local struct {
+	root string
+}
+
+func NewLocal(root string) *Local {
+	if root == "" {
+		root = "."
+	}
+	return &Local{root: root}
+}
+
+func (s *Local) Name() string {
+	return "local"
+}
+
+func (s *Local) path(key string) (string, error) {
+	cleaned, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(cleaned)), nil
+}
+
+// Put menulis ke file sementara lalu rename agar pembaca tidak melihat file setengah jadi
+func (s *Local) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+func (s *Local) Get(ctx context.Context, key string) (io.ReadCloser, Object, error) {
+	object, err := s.Stat(ctx, key)
+	if err != nil {
+		return nil, Object{}, err
+	}
+	target, _ := s.path(key)
+	f, err := os.Open(target)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, Object{}, ErrNotFound
+		}
+		return nil, Object{}, err
+	}
+	return f, object, nil
+}
+
+func (s *Local) Delete(ctx context.Context, key string) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *Local) Stat(ctx context.Context, key string) (Object, error) {
+	target, err := s.path(key)
+	if err != nil {
+		return Object{}, err
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Object{}, ErrNotFound
+		}
+		return Object{}, err
+	}
+	if info.IsDir() {
+		return Object{}, ErrNotFound
+	}
+
+	cleaned, _ := CleanKey(key)
+	return Object{
+		Key:         cleaned,
+		Size:        info.Size(),
+		ContentType: mime.TypeByExtension(path.Ext(cleaned)),
+		ModTime:     info.ModTime(),
+	}, nil
+}
+
+// SignedURL tidak didukung disk lokal; file disajikan melalui handler media
+func (s *Local) SignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	return "", ErrSignedURLUnsupported
+}
@@ -0,0 +1,336 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3Algorithm       = "AWS4-HMAC-SHA256"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3MaxPresign      = 7 * 24 * time.Hour
+)
+
+// S3Config berisi pengaturan bucket S3 atau server yang kompatibel seperti MinIO.
+// PublicEndpoint adalah alamat yang dapat dijangkau klien untuk presigned URL, misalnya
+// saat Endpoint berupa host internal; kosong berarti sama dengan Endpoint.
+type S3Config struct {
+	Endpoint       string
+	PublicEndpoint string
+	Region         string
+	Bucket         string
+	AccessKey      string
+	SecretKey      string
+	UsePathStyle   bool
+}
+
+// S3 menyimpan file di bucket S3-compatible dengan request yang ditandatangani SigV4,
+// sehingga beberapa instance API dapat berbagi media yang sama.
+type S3 struct {
+	endpoint       *url.URL
+	publicEndpoint *url.URL
+	cfg            S3Config
+	client         *http.Client
+	now            func() time.Time
+}
+
+func NewS3(cfg S3Config) (*S3, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, errors.New("storage: S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY_ID, dan S3_SECRET_ACCESS_KEY wajib diisi")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	endpoint, err := parseS3Endpoint(cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("storage: S3_ENDPOINT tidak valid: %s", cfg.Endpoint)
+	}
+	publicEndpoint := endpoint
+	if cfg.PublicEndpoint != "" {
+		if publicEndpoint, err = parseS3Endpoint(cfg.PublicEndpoint); err != nil {
+			return nil, fmt.Errorf("storage: S3_PUBLIC_ENDPOINT tidak valid: %s", cfg.PublicEndpoint)
+		}
+	}
+
+	return &S3{
+		endpoint:       endpoint,
+		publicEndpoint: publicEndpoint,
+		cfg:            cfg,
+		client:         &http.Client{Timeout: 60 * time.Second},
+		now:            time.Now,
+	}, nil
+}
+
+func parseS3Endpoint(raw string) (*url.URL, error) {
+	endpoint, err := url.Parse(raw)
+	if err != nil || endpoint.Host == "" {
+		return nil, errors.New("endpoint tidak valid")
+	}
+	if endpoint.Scheme == "" {
+		endpoint.Scheme = "https"
+	}
+	return endpoint, nil
+}
+
+func (s *S3) Name() string {
+	return "s3"
+}
+
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	req, err := s.newRequest(ctx, http.MethodPut, key, r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, Object, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, Object{}, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, Object{}, err
+	}
+	return resp.Body, objectFromResponse(key, resp), nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3) Stat(ctx context.Context, key string) (Object, error) {
+	req, err := s.newRequest(ctx, http.MethodHead, key, nil)
+	if err != nil {
+		return Object{}, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return Object{}, err
+	}
+	resp.Body.Close()
+	return objectFromResponse(key, resp), nil
+}
+
+// SignedURL membuat presigned GET URL (SigV4 query string) yang berlaku selama expires.
+// URL memakai endpoint publik karena dibuka langsung oleh klien.
+func (s *S3) SignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	objectURL, err := s.objectURL(s.publicEndpoint, key)
+	if err != nil {
+		return "", err
+	}
+	if expires <= 0 || expires > s3MaxPresign {
+		expires = s3MaxPresign
+	}
+
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := s.scope(now)
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", s3Algorithm)
+	query.Set("X-Amz-Credential", s.cfg.AccessKey+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expires.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		escapePath(objectURL.Path),
+		canonicalQuery(query),
+		"host:" + objectURL.Host + "\n",
+		"host",
+		s3UnsignedPayload,
+	}, "\n")
+
+	query.Set("X-Amz-Signature", s.signature(now, amzDate, scope, canonicalRequest))
+	objectURL.RawQuery = canonicalQuery(query)
+	return objectURL.String(), nil
+}
+
+func (s *S3) objectURL(endpoint *url.URL, key string) (*url.URL, error) {
+	cleaned, err := CleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	u := *endpoint
+	basePath := strings.TrimSuffix(u.Path, "/")
+	if s.cfg.UsePathStyle {
+		u.Path = basePath + "/" + s.cfg.Bucket + "/" + cleaned
+	} else {
+		u.Host = s.cfg.Bucket + "." + u.Host
+		u.Path = basePath + "/" + cleaned
+	}
+	u.RawPath = ""
+	u.RawQuery = ""
+	return &u, nil
+}
+
+func (s *S3) newRequest(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	objectURL, err := s.objectURL(s.endpoint, key)
+	if err != nil {
+		return nil, err
+	}
+	objectURL.RawPath = escapePath(objectURL.Path)
+	return http.NewRequestWithContext(ctx, method, objectURL.String(), body)
+}
+
+// do menandatangani request dengan header Authorization SigV4. Isi body tidak di-hash
+// (UNSIGNED-PAYLOAD) agar file besar bisa di-stream tanpa dibaca dua kali.
+func (s *S3) do(req *http.Request) (*http.Response, error) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := s.scope(now)
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", s3UnsignedPayload)
+
+	signedHeaders := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	if req.Header.Get("Content-Type") != "" {
+		signedHeaders = []string{"content-type", "host", "x-amz-content-sha256", "x-amz-date"}
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, name := range signedHeaders {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = req.URL.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		s3UnsignedPayload,
+	}, "\n")
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.cfg.AccessKey, scope, strings.Join(signedHeaders, ";"),
+		s.signature(now, amzDate, scope, canonicalRequest)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("storage: %s %s gagal: %s %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return resp, nil
+}
+
+func (s *S3) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + s.cfg.Region + "/s3/aws4_request"
+}
+
+func (s *S3) signature(now time.Time, amzDate, scope, canonicalRequest string) string {
+	hashed := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{s3Algorithm, amzDate, scope, hex.EncodeToString(hashed[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func objectFromResponse(key string, resp *http.Response) Object {
+	cleaned, _ := CleanKey(key)
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return Object{
+		Key:         cleaned,
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+		ModTime:     modTime,
+	}
+}
+
+// canonicalQuery mengurutkan parameter dan meng-encode sesuai aturan SigV4
+func canonicalQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		vs := append([]string(nil), values[k]...)
+		sort.Strings(vs)
+		for _, v := range vs {
+			parts = append(parts, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func escapePath(p string) string {
+	return uriEncode(p, false)
+}
+
+// uriEncode meng-encode semua karakter kecuali unreserved (RFC 3986); "/" dipertahankan
+// untuk path dan di-encode untuk nilai query.
+func uriEncode(value string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9'),
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
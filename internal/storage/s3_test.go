@@ -0,0 +1,291 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testAccessKey = "minioadmin"
+	testSecretKey = "minio-secret"
+	testRegion    = "us-east-1"
+	testBucket    = "portolio-media"
+)
+
+type fakeObject struct {
+	body        []byte
+	contentType string
+	modTime     time.Time
+}
+
+// fakeS3 meniru MinIO dengan path-style URL dan menolak request yang tanda tangan SigV4-nya
+// tidak cocok. Verifikasi ditulis ulang di sini agar tidak memakai kode yang diuji.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]fakeObject
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := verifySigV4(r); err != nil {
+		http.Error(w, "SignatureDoesNotMatch: "+err.Error(), http.StatusForbidden)
+		return
+	}
+
+	prefix := "/" + testBucket + "/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		http.Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, prefix)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		if r.ContentLength < 0 || int64(len(body)) != r.ContentLength {
+			http.Error(w, "IncompleteBody", http.StatusBadRequest)
+			return
+		}
+		f.objects[key] = fakeObject{body: body, contentType: r.Header.Get("Content-Type"), modTime: time.Now().UTC().Truncate(time.Second)}
+	case http.MethodGet, http.MethodHead:
+		obj, ok := f.objects[key]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", obj.contentType)
+		w.Header().Set("Last-Modified", obj.modTime.Format(http.TimeFormat))
+		w.Header().Set("Content-Length", strconv.Itoa(len(obj.body)))
+		if r.Method == http.MethodGet {
+			w.Write(obj.body)
+		}
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// verifySigV4 memeriksa header Authorization atau presigned query string
+func verifySigV4(r *http.Request) error {
+	query := r.URL.Query()
+	var credential, signedHeaders, signature, amzDate, payloadHash string
+
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 ") {
+			return errors.New("algoritma Authorization salah")
+		}
+		for _, part := range strings.Split(strings.TrimPrefix(auth, "AWS4-HMAC-SHA256 "), ", ") {
+			name, value, _ := strings.Cut(part, "=")
+			switch name {
+			case "Credential":
+				credential = value
+			case "SignedHeaders":
+				signedHeaders = value
+			case "Signature":
+				signature = value
+			}
+		}
+		amzDate = r.Header.Get("X-Amz-Date")
+		payloadHash = r.Header.Get("X-Amz-Content-Sha256")
+		if payloadHash == "" {
+			return errors.New("x-amz-content-sha256 kosong")
+		}
+	} else {
+		if query.Get("X-Amz-Algorithm") != "AWS4-HMAC-SHA256" {
+			return errors.New("request tidak ditandatangani")
+		}
+		credential = query.Get("X-Amz-Credential")
+		signedHeaders = query.Get("X-Amz-SignedHeaders")
+		signature = query.Get("X-Amz-Signature")
+		amzDate = query.Get("X-Amz-Date")
+		payloadHash = "UNSIGNED-PAYLOAD"
+		query.Del("X-Amz-Signature")
+
+		issued, err := time.Parse("20060102T150405Z", amzDate)
+		if err != nil {
+			return err
+		}
+		expires, err := time.ParseDuration(query.Get("X-Amz-Expires") + "s")
+		if err != nil || time.Now().After(issued.Add(expires)) {
+			return errors.New("presigned URL kedaluwarsa")
+		}
+	}
+
+	parts := strings.Split(credential, "/")
+	if len(parts) != 5 || parts[0] != testAccessKey || parts[2] != testRegion || parts[3] != "s3" || parts[4] != "aws4_request" {
+		return errors.New("credential tidak valid: " + credential)
+	}
+	date := parts[1]
+	if !strings.HasPrefix(amzDate, date) {
+		return errors.New("tanggal credential tidak sesuai X-Amz-Date")
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, name := range strings.Split(signedHeaders, ";") {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var queryParts []string
+	for _, k := range keys {
+		queryParts = append(queryParts, url.QueryEscape(k)+"="+strings.ReplaceAll(url.QueryEscape(query.Get(k)), "+", "%20"))
+	}
+
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		r.URL.EscapedPath(),
+		strings.Join(queryParts, "&"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	hashed := sha256.Sum256([]byte(canonicalRequest))
+	scope := strings.Join(parts[1:], "/")
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hashed[:])
+
+	mac := func(key []byte, data string) []byte {
+		h := hmac.New(sha256.New, key)
+		h.Write([]byte(data))
+		return h.Sum(nil)
+	}
+	signingKey := mac(mac(mac(mac([]byte("AWS4"+testSecretKey), date), testRegion), "s3"), "aws4_request")
+	if expected := hex.EncodeToString(mac(signingKey, stringToSign)); !hmac.Equal([]byte(expected), []byte(signature)) {
+		return errors.New("tanda tangan tidak cocok")
+	}
+	return nil
+}
+
+func newTestS3(t *testing.T, publicEndpoint string) (*S3, *httptest.Server) {
+	t.Helper()
+	server := httptest.NewServer(&fakeS3{objects: map[string]fakeObject{}})
+	t.Cleanup(server.Close)
+
+	s3, err := NewS3(S3Config{
+		Endpoint:       server.URL,
+		PublicEndpoint: publicEndpoint,
+		Region:         testRegion,
+		Bucket:         testBucket,
+		AccessKey:      testAccessKey,
+		SecretKey:      testSecretKey,
+		UsePathStyle:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s3, server
+}
+
+func TestS3RoundTrip(t *testing.T) {
+	s3, _ := newTestS3(t, "")
+	ctx := context.Background()
+	key := "media/chat/foto liburan (1).jpg"
+	content := "isi file media"
+
+	if err := s3.Put(ctx, key, strings.NewReader(content), int64(len(content)), "image/jpeg"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	obj, err := s3.Stat(ctx, key)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if obj.Size != int64(len(content)) || obj.ContentType != "image/jpeg" || obj.ModTime.IsZero() {
+		t.Fatalf("Stat = %+v", obj)
+	}
+
+	rc, obj, err := s3.Get(ctx, "/"+key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	body, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(body) != content || obj.Key != key {
+		t.Fatalf("Get = %q (%s), ingin %q (%s)", body, obj.Key, content, key)
+	}
+
+	if err := s3.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s3.Stat(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Stat setelah Delete: err = %v, ingin ErrNotFound", err)
+	}
+	if err := s3.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete objek yang tidak ada: %v", err)
+	}
+}
+
+func TestS3RejectsWrongSecret(t *testing.T) {
+	s3, _ := newTestS3(t, "")
+	s3.cfg.SecretKey = "salah"
+
+	err := s3.Put(context.Background(), "media/a.txt", strings.NewReader("x"), 1, "text/plain")
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("err = %v, ingin 403", err)
+	}
+}
+
+func TestS3SignedURLUsesPublicEndpoint(t *testing.T) {
+	// localhost dan 127.0.0.1 menuju server yang sama tetapi dengan host berbeda, seperti
+	// endpoint internal dan publik pada deployment sebenarnya
+	_, server := newTestS3(t, "")
+	publicEndpoint := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	s3, err := NewS3(S3Config{
+		Endpoint:       server.URL,
+		PublicEndpoint: publicEndpoint,
+		Region:         testRegion,
+		Bucket:         testBucket,
+		AccessKey:      testAccessKey,
+		SecretKey:      testSecretKey,
+		UsePathStyle:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if err := s3.Put(ctx, "media/products/a.jpg", strings.NewReader("gambar"), 6, "image/jpeg"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	signed, err := s3.SignedURL(ctx, "media/products/a.jpg", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(signed, publicEndpoint+"/"+testBucket+"/media/products/a.jpg?") {
+		t.Fatalf("SignedURL = %s, ingin memakai endpoint publik %s", signed, publicEndpoint)
+	}
+
+	resp, err := http.Get(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "gambar" {
+		t.Fatalf("GET signed URL = %d %q", resp.StatusCode, body)
+	}
+}
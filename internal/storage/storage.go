@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound dikembalikan saat objek tidak ada di storage
+	ErrNotFound = errors.New("storage: objek tidak ditemukan")
+	// ErrSignedURLUnsupported dikembalikan backend yang tidak bisa membuat URL langsung,
+	// sehingga file harus disajikan melalui API
+	ErrSignedURLUnsupported = errors.New("storage: signed URL tidak didukung")
+	// ErrInvalidKey dikembalikan untuk key kosong, absolut, atau berisi ".."
+	ErrInvalidKey = errors.New("storage: key tidak valid")
+)
+
+// Object adalah metadata satu file di storage
+type Object struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// Storage menyimpan file media. Key memakai pemisah "/" dan sama dengan path relatif
+// yang disimpan di database, misalnya "media/products/abc_full.jpg".
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, Object, error)
+	Delete(ctx context.Context, key string) error
+	Stat(ctx context.Context, key string) (Object, error)
+	SignedURL(ctx context.Context, key string, expires time.Duration) (string, error)
+	Name() string
+}
+
+// Config berisi pengaturan backend storage, dibaca dari env di main
+type Config struct {
+	Driver    string
+	LocalRoot string
+
+	S3Endpoint       string
+	S3PublicEndpoint string
+	S3Region         string
+	S3Bucket         string
+	S3AccessKey      string
+	S3SecretKey      string
+	S3UsePathStyle   bool
+}
+
+// New membuat storage sesuai cfg.Driver: "s3" untuk S3/MinIO, selain itu disk lokal
+func New(cfg Config) (Storage, error) {
+	switch strings.ToLower(cfg.Driver) {
+	case "s3", "minio":
+		return NewS3(S3Config{
+			Endpoint:       cfg.S3Endpoint,
+			PublicEndpoint: cfg.S3PublicEndpoint,
+			Region:         cfg.S3Region,
+			Bucket:         cfg.S3Bucket,
+			AccessKey:      cfg.S3AccessKey,
+			SecretKey:      cfg.S3SecretKey,
+			UsePathStyle:   cfg.S3UsePathStyle,
+		})
+	default:
+		return NewLocal(cfg.LocalRoot), nil
+	}
+}
+
+// CleanKey menormalkan key dan menolak key yang keluar dari root storage
+func CleanKey(key string) (string, error) {
+	key = strings.TrimPrefix(strings.ReplaceAll(key, "\\", "/"), "/")
+	if key == "" {
+		return "", ErrInvalidKey
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == ".." {
+			return "", ErrInvalidKey
+		}
+	}
+	cleaned := path.Clean(key)
+	if cleaned == "." || strings.HasPrefix(cleaned, "../") {
+		return "", ErrInvalidKey
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,192 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"portolio-backend/configs"
+	"portolio-backend/configs/constants"
+)
+
+// blockedPrefixes adalah rentang alamat yang tidak boleh dihubungi oleh fetcher
+// selain loopback, private, link-local, dan multicast yang dicek melalui netip.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("2001:db8::/32"),
+}
+
+var errBlockedAddress = errors.New(constants.ErrMsgRemoteURLBlocked)
+
+// SafeDialContext membuat fungsi dial untuk http.Transport yang menolak koneksi ke jaringan
+// internal. Alamat IP diperiksa setelah resolusi DNS pada saat koneksi dibuat, sehingga
+// nama host yang mengarah ke jaringan internal (termasuk DNS rebinding) tetap ditolak
+// dengan blockedErr. allowPrivate mematikan pemeriksaan, hanya untuk pengembangan dan
+// pengujian dengan layanan lokal.
+func SafeDialContext(blockedErr error, allowPrivate bool) func(ctx context.Context, network, address string) (net.Conn, error) {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			if allowPrivate {
+				return nil
+			}
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip, err := netip.ParseAddr(host)
+			if err != nil || IsBlockedIP(ip) {
+				return blockedErr
+			}
+			return nil
+		},
+	}
+	return dialer.DialContext
+}
+
+// NewSafeHTTPClient membuat http.Client untuk mengambil URL dari pengguna dengan
+// SafeDialContext.
+func NewSafeHTTPClient() *http.Client {
+	timeout := configs.GetEnvDuration("IMAGE_FETCH_TIMEOUT", constants.DefaultImageFetchTimeout)
+	maxRedirects := configs.GetEnvInt("IMAGE_FETCH_MAX_REDIRECTS", constants.DefaultImageFetchMaxRedirects)
+
+	transport := &http.Transport{
+		Proxy:                 nil,
+		DialContext:           SafeDialContext(errBlockedAddress, false),
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > maxRedirects {
+				return fmt.Errorf(constants.ErrMsgRemoteURLTooManyRedirects)
+			}
+			return ValidateRemoteURL(req.URL)
+		},
+	}
+}
+
+// ValidateRemoteURL memeriksa skema, port, serta allowlist/denylist host sebelum URL diambil.
+// Host diatur melalui IMAGE_FETCH_ALLOWED_HOSTS dan IMAGE_FETCH_DENIED_HOSTS (dipisah koma,
+// cocok juga untuk subdomain). Jika allowlist diisi, hanya host di dalamnya yang diizinkan.
+func ValidateRemoteURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf(constants.ErrMsgRemoteURLBlocked)
+	}
+	if u.User != nil {
+		return fmt.Errorf(constants.ErrMsgRemoteURLBlocked)
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "" || host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf(constants.ErrMsgRemoteURLBlocked)
+	}
+	if ip, err := netip.ParseAddr(host); err == nil && IsBlockedIP(ip) {
+		return fmt.Errorf(constants.ErrMsgRemoteURLBlocked)
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	allowedPorts := configs.GetEnvList("IMAGE_FETCH_ALLOWED_PORTS", []string{"80", "443"})
+	if len(allowedPorts) > 0 && !slices.Contains(allowedPorts, port) {
+		return fmt.Errorf(constants.ErrMsgRemoteURLBlocked)
+	}
+
+	if matchHost(host, configs.GetEnvList("IMAGE_FETCH_DENIED_HOSTS", nil)) {
+		return fmt.Errorf(constants.ErrMsgRemoteURLBlocked)
+	}
+	allowed := configs.GetEnvList("IMAGE_FETCH_ALLOWED_HOSTS", nil)
+	if len(allowed) > 0 && !matchHost(host, allowed) {
+		return fmt.Errorf(constants.ErrMsgRemoteURLBlocked)
+	}
+	return nil
+}
+
+// IsBlockedIP memeriksa apakah alamat termasuk jaringan internal yang tidak boleh dihubungi
+// dengan URL dari pengguna
+func IsBlockedIP(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return true
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchHost(host string, patterns []string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimPrefix(pattern, "*."))
+		pattern = strings.TrimPrefix(pattern, ".")
+		if host == pattern || strings.HasSuffix(host, "."+pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchRemote mengambil URL dari pengguna dengan client aman
+func fetchRemote(rawURL string) (*http.Response, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf(constants.ErrMsgRemoteURLBlocked)
+	}
+	if err := ValidateRemoteURL(u); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "portolio-backend-image-fetcher/1.0")
+	req.Header.Set("Accept", "image/*")
+
+	safeHTTPClientOnce.Do(func() {
+		safeHTTPClient = NewSafeHTTPClient()
+	})
+	resp, err := safeHTTPClient.Do(req)
+	if err != nil {
+		if errors.Is(err, errBlockedAddress) {
+			return nil, fmt.Errorf(constants.ErrMsgRemoteURLBlocked)
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+var (
+	safeHTTPClient     *http.Client
+	safeHTTPClientOnce sync.Once
+)
@@ -0,0 +1,67 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"testing"
+)
+
+func TestIsBlockedIP(t *testing.T) {
+	tests := []struct {
+		addr    string
+		blocked bool
+	}{
+		{"127.0.0.1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true},
+		{"100.64.0.1", true},
+		{"0.0.0.0", true},
+		{"::1", true},
+		{"::ffff:127.0.0.1", true},
+		{"fd00::1", true},
+		{"fe80::1", true},
+		{"8.8.8.8", false},
+		{"2606:4700:4700::1111", false},
+	}
+	for _, tt := range tests {
+		if got := IsBlockedIP(netip.MustParseAddr(tt.addr)); got != tt.blocked {
+			t.Errorf("IsBlockedIP(%s) = %v, ingin %v", tt.addr, got, tt.blocked)
+		}
+	}
+}
+
+func TestSafeDialContextChecksResolvedAddress(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	blocked := errors.New("alamat diblokir")
+
+	// localhost baru diketahui sebagai loopback setelah resolusi DNS
+	for _, address := range []string{listener.Addr().String(), net.JoinHostPort("localhost", port)} {
+		if _, err := SafeDialContext(blocked, false)(context.Background(), "tcp", address); !errors.Is(err, blocked) {
+			t.Errorf("dial %s: err = %v, ingin %v", address, err, blocked)
+		}
+	}
+
+	conn, err := SafeDialContext(blocked, true)(context.Background(), "tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("dial dengan allowPrivate: %v", err)
+	}
+	conn.Close()
+}
@@ -2,6 +2,7 @@ package util
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"io"
@@ -11,6 +12,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"portolio-backend/configs"
 	"portolio-backend/configs/constants"
 )
 
@@ -22,7 +24,46 @@ func GenerateRandomFilename() string {
 	return hex.EncodeToString(b)
 }
 
+// HashUploadedFile menghitung SHA-256 isi file yang diunggah (hex)
+func HashUploadedFile(file *multipart.FileHeader) (string, error) {
+	src, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, src); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// SaveUploadedFile memvalidasi file yang diunggah lalu menyimpannya ke MediaStorage
+// di bawah destDir. Yang dikembalikan adalah key storage, misalnya "media/chat/abc.png".
 func SaveUploadedFile(file *multipart.FileHeader, destDir string, maxSizeBytes int64, allowedExtensions []string) (string, error) {
+	stagedPath, err := StageUploadedFile(file, maxSizeBytes, allowedExtensions)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(stagedPath)
+	return storeStagedFile(stagedPath, destDir)
+}
+
+// SaveUploadedImage memvalidasi gambar yang diunggah lalu menyimpannya ke MediaStorage di
+// bawah destDir setelah di-encode ulang, sehingga metadata EXIF dan GPS tidak ikut tersimpan
+func SaveUploadedImage(file *multipart.FileHeader, destDir string, maxSizeBytes int64) (string, error) {
+	stagedPath, err := StageUploadedFile(file, maxSizeBytes, AllowedImageExtensions)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(stagedPath)
+	return storeProcessedImage(stagedPath, destDir)
+}
+
+// StageUploadedFile memvalidasi file yang diunggah dan menyimpannya sementara di disk
+// lokal (media/temp) untuk diproses lebih lanjut. Pemanggil wajib menghapus file tersebut.
+func StageUploadedFile(file *multipart.FileHeader, maxSizeBytes int64, allowedExtensions []string) (string, error) {
 	if file.Size > maxSizeBytes {
 		return "", fmt.Errorf(constants.ErrMsgFileTooLarge)
 	}
@@ -36,7 +77,7 @@ func SaveUploadedFile(file *multipart.FileHeader, destDir string, maxSizeBytes i
 		}
 	}
 	if !isValidExt {
-		return "", fmt.Errorf(constants.ErrMsgInvalidFileType + ": " + ext + " tidak diizinkan.")
+		return "", fmt.Errorf("%s: %s tidak diizinkan.", constants.ErrMsgInvalidFileType, ext)
 	}
 
 	src, err := file.Open()
@@ -45,27 +86,49 @@ func SaveUploadedFile(file *multipart.FileHeader, destDir string, maxSizeBytes i
 	}
 	defer src.Close()
 
-	if err := os.MkdirAll(destDir, os.ModePerm); err != nil {
+	if err := os.MkdirAll(constants.StagingDir, os.ModePerm); err != nil {
 		return "", err
 	}
 
 	filename := GenerateRandomFilename() + ext
-	filePath := filepath.Join(destDir, filename)
+	filePath := filepath.Join(constants.StagingDir, filename)
 	dst, err := os.Create(filePath)
 	if err != nil {
 		return "", err
 	}
-	defer dst.Close()
 
-	if _, err := io.Copy(dst, src); err != nil {
+	n, err := io.Copy(dst, io.LimitReader(src, maxSizeBytes+1))
+	dst.Close()
+	if err != nil {
+		os.Remove(filePath)
+		return "", err
+	}
+	if n > maxSizeBytes {
+		os.Remove(filePath)
+		return "", fmt.Errorf(constants.ErrMsgFileTooLarge)
+	}
+
+	if err := validateStoredFile(filePath, ext, file.Filename); err != nil {
 		return "", err
 	}
 
 	return filePath, nil
 }
 
+// DownloadImage mengunduh gambar dari URL lalu menyimpannya ke MediaStorage di bawah destDir
+// seperti SaveUploadedImage
 func DownloadImage(url, destDir string, maxSizeBytes int64) (string, error) {
-	resp, err := http.Get(url)
+	stagedPath, err := StageRemoteImage(url, maxSizeBytes)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(stagedPath)
+	return storeProcessedImage(stagedPath, destDir)
+}
+
+// StageRemoteImage mengunduh dan memvalidasi gambar dari URL ke disk lokal (media/temp)
+func StageRemoteImage(url string, maxSizeBytes int64) (string, error) {
+	resp, err := fetchRemote(url)
 	if err != nil {
 		return "", err
 	}
@@ -97,23 +160,24 @@ func DownloadImage(url, destDir string, maxSizeBytes int64) (string, error) {
 		}
 	}
 	if !isValidExt {
-		return "", fmt.Errorf(constants.ErrMsgInvalidFileType + ": " + ext + " bukan tipe gambar yang diizinkan.")
+		return "", fmt.Errorf("%s: %s bukan tipe gambar yang diizinkan.", constants.ErrMsgInvalidFileType, ext)
 	}
 
-	if err := os.MkdirAll(destDir, os.ModePerm); err != nil {
+	if err := os.MkdirAll(constants.StagingDir, os.ModePerm); err != nil {
 		return "", err
 	}
 
 	filename := GenerateRandomFilename() + ext
-	filePath := filepath.Join(destDir, filename)
+	filePath := filepath.Join(constants.StagingDir, filename)
 	out, err := os.Create(filePath)
 	if err != nil {
 		return "", err
 	}
-	defer out.Close()
 
 	n, err := io.Copy(out, io.LimitReader(resp.Body, maxSizeBytes+1))
+	out.Close()
 	if err != nil {
+		os.Remove(filePath)
 		return "", err
 	}
 	if n > maxSizeBytes {
@@ -121,15 +185,23 @@ func DownloadImage(url, destDir string, maxSizeBytes int64) (string, error) {
 		return "", fmt.Errorf(constants.ErrMsgFileTooLarge)
 	}
 
+	if err := validateStoredFile(filePath, ext, url); err != nil {
+		return "", err
+	}
+
 	return filePath, nil
 }
 
-var GetMaxImageSizeMB = func() int {
-	return constants.MaxImageSizeMB
+func GetMaxImageSizeMB() int {
+	return configs.GetEnvInt("MAX_IMAGE_SIZE_MB", constants.MaxImageSizeMB)
+}
+
+func GetMaxDocumentSizeMB() int {
+	return configs.GetEnvInt("MAX_DOCUMENT_SIZE_MB", constants.MaxDocumentSizeMB)
 }
 
-var GetMaxDocumentSizeMB = func() int {
-	return constants.MaxDocumentSizeMB
+func GetMaxImportSizeMB() int {
+	return configs.GetEnvInt("MAX_IMPORT_SIZE_MB", constants.MaxImportSizeMB)
 }
 
 var AllowedImageExtensions = []string{".jpg", ".jpeg", ".png", ".gif"}
@@ -0,0 +1,111 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/scanner"
+)
+
+// UploadScanner memindai setiap file yang diunggah, diatur dari main sesuai SCANNER_DRIVER
+var UploadScanner scanner.Scanner = scanner.NoopScanner{}
+
+// UploadScanTimeout membatasi lama pemindaian satu file
+var UploadScanTimeout = constants.DefaultScannerTimeout
+
+// allowedMIMETypes memetakan ekstensi ke tipe konten yang sah berdasarkan magic bytes
+var allowedMIMETypes = map[string][]string{
+	".jpg":  {"image/jpeg"},
+	".jpeg": {"image/jpeg"},
+	".png":  {"image/png"},
+	".gif":  {"image/gif"},
+	".pdf":  {"application/pdf"},
+	".doc":  {"application/msword", "application/x-ole-storage"},
+	".xls":  {"application/vnd.ms-excel", "application/x-ole-storage"},
+	".ppt":  {"application/vnd.ms-powerpoint", "application/x-ole-storage"},
+	".docx": {"application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+	".xlsx": {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+	".pptx": {"application/vnd.openxmlformats-officedocument.presentationml.presentation"},
+	".txt":  {"text/plain"},
+	".csv":  {"text/csv", "text/plain"},
+}
+
+// DetectContentType membaca magic bytes file dan memastikan isinya sesuai ekstensi
+func DetectContentType(filePath, ext string) (string, error) {
+	detected, err := mimetype.DetectFile(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	allowed, ok := allowedMIMETypes[ext]
+	if !ok {
+		return detected.String(), fmt.Errorf("%s: %s tidak diizinkan.", constants.ErrMsgInvalidFileType, ext)
+	}
+	// Turunan tipe juga diterima, misalnya text/csv untuk file .txt.
+	for m := detected; m != nil; m = m.Parent() {
+		for _, expected := range allowed {
+			if m.Is(expected) {
+				return detected.String(), nil
+			}
+		}
+	}
+	return detected.String(), fmt.Errorf("%s (terdeteksi %s)", constants.ErrMsgFileContentMismatch, detected.String())
+}
+
+// validateStoredFile memeriksa tipe konten dan memindai file yang baru ditulis.
+// File yang ditolak dipindahkan ke karantina bersama originalName, yaitu nama file dari
+// klien atau URL sumbernya; file yang gagal dipindai dihapus.
+func validateStoredFile(filePath, ext, originalName string) error {
+	if _, err := DetectContentType(filePath, ext); err != nil {
+		QuarantineFile(filePath, originalName, err.Error())
+		return err
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), UploadScanTimeout)
+	result, err := UploadScanner.Scan(ctx, f)
+	cancel()
+	f.Close()
+
+	if err != nil {
+		log.Printf("❌ Gagal memindai file %s dengan %s: %v", filePath, UploadScanner.Name(), err)
+		os.Remove(filePath)
+		return fmt.Errorf(constants.ErrMsgFileScanFailed)
+	}
+	if !result.Clean {
+		QuarantineFile(filePath, originalName, "terdeteksi "+result.Signature)
+		return fmt.Errorf(constants.ErrMsgFileInfected)
+	}
+	return nil
+}
+
+// QuarantineFile memindahkan file yang ditolak ke media/quarantine di MediaStorage beserta
+// catatan nama asli dari klien dan alasannya, sehingga admin dapat menelusuri unggahan
+// tersebut tanpa file bisa diakses publik.
+func QuarantineFile(filePath, originalName, reason string) {
+	defer os.Remove(filePath)
+
+	key := path.Join(constants.QuarantineDir, time.Now().Format("20060102T150405")+"_"+GenerateRandomFilename()+".quarantined")
+	if err := PutLocalFile(key, filePath); err != nil {
+		log.Printf("❌ Gagal memindahkan file %s ke karantina: %v", filePath, err)
+		return
+	}
+
+	note := fmt.Sprintf("original: %q\nstaged: %s\nreason: %s\nscanner: %s\n", originalName, filepath.Base(filePath), reason, UploadScanner.Name())
+	if err := MediaStorage.Put(context.Background(), key+".txt", strings.NewReader(note), int64(len(note)), "text/plain"); err != nil {
+		log.Printf("❌ Gagal menulis catatan karantina %s: %v", key, err)
+	}
+	log.Printf("⚠️ File %q (%s) dikarantina: %s", originalName, filePath, strings.TrimSpace(reason))
+}
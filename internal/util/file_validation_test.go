@@ -0,0 +1,182 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/scanner"
+	"portolio-backend/internal/storage"
+)
+
+var (
+	pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00\x1f\x15\xc4\x89")
+	pdfHeader = []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\n")
+	exeHeader = append([]byte("MZ\x90\x00\x03\x00\x00\x00\x04\x00\x00\x00\xff\xff\x00\x00"), make([]byte, 64)...)
+)
+
+// fakeScanner mengembalikan hasil tetap dan mencatat isi yang dipindai
+type fakeScanner struct {
+	result  scanner.Result
+	err     error
+	scanned []byte
+}
+
+func (s *fakeScanner) Name() string { return "fake" }
+
+func (s *fakeScanner) Scan(ctx context.Context, r io.Reader) (scanner.Result, error) {
+	s.scanned, _ = io.ReadAll(r)
+	return s.result, s.err
+}
+
+// useTestMedia mengganti MediaStorage dan UploadScanner selama satu test lalu memulihkannya
+func useTestMedia(t *testing.T, s scanner.Scanner) string {
+	t.Helper()
+	root := t.TempDir()
+	oldStorage, oldScanner := MediaStorage, UploadScanner
+	MediaStorage, UploadScanner = storage.NewLocal(root), s
+	t.Cleanup(func() { MediaStorage, UploadScanner = oldStorage, oldScanner })
+	return root
+}
+
+func writeStagedFile(t *testing.T, name string, content []byte) string {
+	t.Helper()
+	filePath := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(filePath, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return filePath
+}
+
+func quarantined(t *testing.T, root string) (files, notes []string) {
+	t.Helper()
+	files, _ = filepath.Glob(filepath.Join(root, constants.QuarantineDir, "*.quarantined"))
+	notes, _ = filepath.Glob(filepath.Join(root, constants.QuarantineDir, "*.quarantined.txt"))
+	return files, notes
+}
+
+func TestDetectContentType(t *testing.T) {
+	tests := []struct {
+		name    string
+		ext     string
+		content []byte
+		want    string
+		wantErr string
+	}{
+		{name: "png asli", ext: ".png", content: pngHeader, want: "image/png"},
+		{name: "pdf asli", ext: ".pdf", content: pdfHeader, want: "application/pdf"},
+		{name: "teks", ext: ".txt", content: []byte("catatan pesanan\n"), want: "text/plain; charset=utf-8"},
+		{name: "csv", ext: ".csv", content: []byte("nama,harga\nkaos,50000\n"), want: "text/csv"},
+		{name: "png berekstensi jpg", ext: ".jpg", content: pngHeader, wantErr: constants.ErrMsgFileContentMismatch},
+		{name: "exe berekstensi png", ext: ".png", content: exeHeader, wantErr: constants.ErrMsgFileContentMismatch},
+		{name: "teks berekstensi pdf", ext: ".pdf", content: []byte("bukan pdf"), wantErr: constants.ErrMsgFileContentMismatch},
+		{name: "ekstensi tidak dikenal", ext: ".exe", content: exeHeader, wantErr: constants.ErrMsgInvalidFileType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectContentType(writeStagedFile(t, "upload"+tt.ext, tt.content), tt.ext)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("DetectContentType = %q, %v; ingin error %q", got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("DetectContentType = %q, %v; ingin %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateStoredFileQuarantinesInfectedFile(t *testing.T) {
+	s := &fakeScanner{result: scanner.Result{Signature: "Eicar-Test-Signature"}}
+	root := useTestMedia(t, s)
+	filePath := writeStagedFile(t, "staged.pdf", pdfHeader)
+
+	err := validateStoredFile(filePath, ".pdf", "invoice.pdf")
+	if err == nil || err.Error() != constants.ErrMsgFileInfected {
+		t.Fatalf("err = %v, ingin %q", err, constants.ErrMsgFileInfected)
+	}
+	if string(s.scanned) != string(pdfHeader) {
+		t.Errorf("scanner menerima %q", s.scanned)
+	}
+	if _, err := os.Stat(filePath); !os.IsNotExist(err) {
+		t.Errorf("file staging masih ada: %v", err)
+	}
+
+	files, notes := quarantined(t, root)
+	if len(files) != 1 || len(notes) != 1 {
+		t.Fatalf("karantina berisi %v dan catatan %v", files, notes)
+	}
+	content, _ := os.ReadFile(files[0])
+	if string(content) != string(pdfHeader) {
+		t.Errorf("isi karantina = %q", content)
+	}
+	note, _ := os.ReadFile(notes[0])
+	for _, want := range []string{`original: "invoice.pdf"`, "staged: staged.pdf", "reason: terdeteksi Eicar-Test-Signature", "scanner: fake"} {
+		if !strings.Contains(string(note), want) {
+			t.Errorf("catatan karantina tidak memuat %q:\n%s", want, note)
+		}
+	}
+}
+
+func TestValidateStoredFileQuarantinesContentMismatch(t *testing.T) {
+	s := &fakeScanner{result: scanner.Result{Clean: true}}
+	root := useTestMedia(t, s)
+	filePath := writeStagedFile(t, "staged.png", exeHeader)
+
+	err := validateStoredFile(filePath, ".png", "foto.png")
+	if err == nil || !strings.HasPrefix(err.Error(), constants.ErrMsgFileContentMismatch) {
+		t.Fatalf("err = %v, ingin %q", err, constants.ErrMsgFileContentMismatch)
+	}
+	if s.scanned != nil {
+		t.Error("file yang isinya tidak sesuai tetap dipindai")
+	}
+	if _, err := os.Stat(filePath); !os.IsNotExist(err) {
+		t.Errorf("file staging masih ada: %v", err)
+	}
+	files, notes := quarantined(t, root)
+	if len(files) != 1 || len(notes) != 1 {
+		t.Fatalf("karantina berisi %v dan catatan %v", files, notes)
+	}
+	note, _ := os.ReadFile(notes[0])
+	if !strings.Contains(string(note), `original: "foto.png"`) {
+		t.Errorf("catatan karantina tidak memuat nama asli:\n%s", note)
+	}
+}
+
+func TestValidateStoredFileRemovesFileWhenScanFails(t *testing.T) {
+	root := useTestMedia(t, &fakeScanner{err: errors.New("clamd tidak dapat dihubungi")})
+	filePath := writeStagedFile(t, "staged.pdf", pdfHeader)
+
+	err := validateStoredFile(filePath, ".pdf", "invoice.pdf")
+	if err == nil || err.Error() != constants.ErrMsgFileScanFailed {
+		t.Fatalf("err = %v, ingin %q", err, constants.ErrMsgFileScanFailed)
+	}
+	if _, err := os.Stat(filePath); !os.IsNotExist(err) {
+		t.Errorf("file yang gagal dipindai masih ada: %v", err)
+	}
+	if files, notes := quarantined(t, root); len(files) != 0 || len(notes) != 0 {
+		t.Errorf("file yang gagal dipindai ikut dikarantina: %v %v", files, notes)
+	}
+}
+
+func TestValidateStoredFileAcceptsCleanFile(t *testing.T) {
+	root := useTestMedia(t, &fakeScanner{result: scanner.Result{Clean: true}})
+	filePath := writeStagedFile(t, "staged.png", pngHeader)
+
+	if err := validateStoredFile(filePath, ".png", "foto.png"); err != nil {
+		t.Fatalf("validateStoredFile: %v", err)
+	}
+	if _, err := os.Stat(filePath); err != nil {
+		t.Errorf("file bersih terhapus: %v", err)
+	}
+	if files, _ := quarantined(t, root); len(files) != 0 {
+		t.Errorf("file bersih dikarantina: %v", files)
+	}
+}
@@ -0,0 +1,252 @@
+package util
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	_ "image/gif"
+
+	"portolio-backend/configs/constants"
+)
+
+// ImageVariant adalah satu ukuran gambar hasil pemrosesan
+type ImageVariant struct {
+	Name    string
+	MaxSide int
+}
+
+// ProductImageVariants adalah ukuran yang dibuat untuk setiap gambar produk
+var ProductImageVariants = []ImageVariant{
+	{Name: "thumb", MaxSide: constants.ImageThumbnailMaxSide},
+	{Name: "medium", MaxSide: constants.ImageMediumMaxSide},
+	{Name: "full", MaxSide: constants.ImageFullMaxSide},
+}
+
+// ProcessImage mendekode file gambar, memutar sesuai orientasi EXIF, lalu menyimpan
+// setiap varian ukuran ke destDir. Karena gambar di-encode ulang, seluruh metadata
+// (EXIF, GPS, komentar) tidak ikut tersimpan. Hasilnya map nama varian ke path file.
+func ProcessImage(srcPath, destDir string, variants []ImageVariant) (map[string]string, error) {
+	data, err := os.ReadFile(srcPath)
+	if err != nil {
+		return nil, err
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf(constants.ErrMsgInvalidImage)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > constants.MaxImagePixels {
+		return nil, fmt.Errorf(constants.ErrMsgImageDimensionsTooLarge)
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf(constants.ErrMsgInvalidImage)
+	}
+
+	img := toRGBA(decoded)
+	if format == "jpeg" {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+
+	// JPEG tetap JPEG, sedangkan PNG dan GIF disimpan sebagai PNG agar transparansi terjaga.
+	ext := ".png"
+	if format == "jpeg" {
+		ext = ".jpg"
+	}
+
+	if err := os.MkdirAll(destDir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	base := GenerateRandomFilename()
+	paths := make(map[string]string, len(variants))
+	for _, variant := range variants {
+		path := filepath.Join(destDir, base+"_"+variant.Name+ext)
+		if err := writeImage(path, resizeToFit(img, variant.MaxSide), ext); err != nil {
+			for _, written := range paths {
+				os.Remove(written)
+			}
+			return nil, err
+		}
+		paths[variant.Name] = path
+	}
+	return paths, nil
+}
+
+func writeImage(path string, img image.Image, ext string) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if ext == ".jpg" {
+		return jpeg.Encode(out, img, &jpeg.Options{Quality: constants.ImageJPEGQuality})
+	}
+	return png.Encode(out, img)
+}
+
+func toRGBA(src image.Image) *image.RGBA {
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, bounds.Min, draw.Src)
+	return dst
+}
+
+// resizeToFit mengecilkan gambar dengan box filter sampai sisi terpanjangnya maxSide.
+// Gambar yang sudah lebih kecil tidak diperbesar.
+func resizeToFit(src *image.RGBA, maxSide int) *image.RGBA {
+	srcW, srcH := src.Bounds().Dx(), src.Bounds().Dy()
+	if srcW <= maxSide && srcH <= maxSide {
+		return src
+	}
+
+	dstW, dstH := maxSide, maxSide
+	if srcW >= srcH {
+		dstH = max(1, srcH*maxSide/srcW)
+	} else {
+		dstW = max(1, srcW*maxSide/srcH)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0 := y * srcH / dstH
+		y1 := max(y0+1, (y+1)*srcH/dstH)
+		for x := 0; x < dstW; x++ {
+			x0 := x * srcW / dstW
+			x1 := max(x0+1, (x+1)*srcW/dstW)
+
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				offset := sy*src.Stride + x0*4
+				for sx := x0; sx < x1; sx++ {
+					r += uint32(src.Pix[offset])
+					g += uint32(src.Pix[offset+1])
+					b += uint32(src.Pix[offset+2])
+					a += uint32(src.Pix[offset+3])
+					offset += 4
+					n++
+				}
+			}
+
+			i := y*dst.Stride + x*4
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(b / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+// applyOrientation memutar/membalik gambar sesuai tag Orientation EXIF (1-8)
+// sehingga foto dari kamera tetap tampil tegak setelah metadata dibuang.
+func applyOrientation(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dstW, dstH := w, h
+	if orientation >= 5 {
+		dstW, dstH = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		for x := 0; x < dstW; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[y*dst.Stride+x*4:y*dst.Stride+x*4+4], src.Pix[sy*src.Stride+sx*4:sy*src.Stride+sx*4+4])
+		}
+	}
+	return dst
+}
+
+// jpegOrientation membaca tag Orientation dari segmen APP1 Exif. Mengembalikan 1
+// (normal) jika tag tidak ada atau data EXIF tidak valid.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return 1
+		}
+		marker := data[pos+1]
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		if length < 2 || pos+2+length > len(data) {
+			return 1
+		}
+		segment := data[pos+4 : pos+2+length]
+		if marker == 0xE1 && len(segment) >= 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return exifOrientation(segment[6:])
+		}
+		pos += 2 + length
+	}
+	return 1
+}
+
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd : ifd+2]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:entry+2]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8 : entry+10]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+			return orientation
+		}
+	}
+	return 1
+}
@@ -0,0 +1,73 @@
+package util
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"portolio-backend/internal/scanner"
+)
+
+// jpegWithGPS membuat JPEG yang membawa segmen APP1 EXIF berisi penanda GPS
+func jpegWithGPS(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 8, 4))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	img.Set(0, 0, color.RGBA{R: 0xff, A: 0xff})
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	exif := []byte("Exif\x00\x00MM\x00\x2aGPSLatitude=-6.2088;GPSLongitude=106.8456")
+	segment := append([]byte{0xff, 0xe1, byte((len(exif) + 2) >> 8), byte(len(exif) + 2)}, exif...)
+	data := buf.Bytes()
+	return append(append(append([]byte{}, data[:2]...), segment...), data[2:]...)
+}
+
+func TestStoreProcessedImageStripsMetadata(t *testing.T) {
+	t.Chdir(t.TempDir())
+	root := useTestMedia(t, scanner.NoopScanner{})
+	stagedPath := writeStagedFile(t, "staged.jpg", jpegWithGPS(t))
+
+	key, err := storeProcessedImage(stagedPath, "media/products")
+	if err != nil {
+		t.Fatalf("storeProcessedImage: %v", err)
+	}
+	if !strings.HasPrefix(key, "media/products/") || !strings.HasSuffix(key, ".jpg") {
+		t.Errorf("key = %s, ingin JPEG di media/products", key)
+	}
+
+	stored, err := os.ReadFile(filepath.Join(root, key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(stored, []byte("Exif")) || bytes.Contains(stored, []byte("GPS")) {
+		t.Error("gambar tersimpan masih memuat metadata EXIF/GPS")
+	}
+	if cfg, err := jpeg.DecodeConfig(bytes.NewReader(stored)); err != nil || cfg.Width != 8 || cfg.Height != 4 {
+		t.Errorf("gambar tersimpan = %+v, %v; ingin JPEG 8x4", cfg, err)
+	}
+	if leftovers, _ := filepath.Glob("media/temp/*"); len(leftovers) != 0 {
+		t.Errorf("file staging hasil proses tidak dihapus: %v", leftovers)
+	}
+}
+
+func TestStoreProcessedImageRejectsInvalidImage(t *testing.T) {
+	t.Chdir(t.TempDir())
+	root := useTestMedia(t, scanner.NoopScanner{})
+	stagedPath := writeStagedFile(t, "staged.png", pngHeader)
+
+	if _, err := storeProcessedImage(stagedPath, "media/products"); err == nil {
+		t.Fatal("PNG terpotong diterima")
+	}
+	if stored, _ := filepath.Glob(filepath.Join(root, "media/products/*")); len(stored) != 0 {
+		t.Errorf("gambar tidak valid tetap tersimpan: %v", stored)
+	}
+}
@@ -0,0 +1,60 @@
+package util
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/gabriel-vasile/mimetype"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/storage"
+)
+
+// MediaStorage adalah tempat penyimpanan seluruh file media, diatur dari main sesuai STORAGE_DRIVER
+var MediaStorage storage.Storage = storage.NewLocal(".")
+
+// PutLocalFile mengunggah file lokal ke MediaStorage dengan key yang diberikan
+func PutLocalFile(key, localPath string) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	contentType := "application/octet-stream"
+	if detected, err := mimetype.DetectFile(localPath); err == nil {
+		contentType = detected.String()
+	}
+	return MediaStorage.Put(context.Background(), key, f, info.Size(), contentType)
+}
+
+// DeleteMedia menghapus file dari MediaStorage berdasarkan key atau URL relatif ("/media/...")
+func DeleteMedia(key string) error {
+	return MediaStorage.Delete(context.Background(), key)
+}
+
+func storeStagedFile(stagedPath, destDir string) (string, error) {
+	key := path.Join(filepath.ToSlash(destDir), filepath.Base(stagedPath))
+	if err := PutLocalFile(key, stagedPath); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// storeProcessedImage meng-encode ulang gambar staging dalam ukuran penuh dengan
+// ProcessImage lalu mengunggah hasilnya ke MediaStorage di bawah destDir
+func storeProcessedImage(stagedPath, destDir string) (string, error) {
+	paths, err := ProcessImage(stagedPath, constants.StagingDir, []ImageVariant{{Name: "full", MaxSide: constants.ImageFullMaxSide}})
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(paths["full"])
+	return storeStagedFile(paths["full"], destDir)
+}
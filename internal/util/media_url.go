@@ -0,0 +1,86 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"portolio-backend/configs"
+	"portolio-backend/configs/constants"
+)
+
+// protectedMediaPrefixes adalah path media yang hanya boleh diakses pihak terkait
+var protectedMediaPrefixes = []string{"/media/chat/", "/media/support/"}
+
+func mediaURLSecret() []byte {
+	return []byte(configs.GetEnv("MEDIA_URL_SECRET", configs.GetTokenJWTConfig().JWT))
+}
+
+// SignMediaURL menambahkan uid, exp, dan sig (HMAC-SHA256) ke URL media chat/support
+// sehingga dapat dipakai langsung di tag <img> tanpa header Authorization.
+// URL lain dikembalikan apa adanya.
+func SignMediaURL(fileURL string, userID uint) string {
+	if fileURL == "" {
+		return fileURL
+	}
+	u, err := url.Parse(fileURL)
+	if err != nil || !isProtectedMediaPath(u.Path) {
+		return fileURL
+	}
+
+	expires := time.Now().Add(configs.GetEnvDuration("MEDIA_URL_TTL", constants.DefaultMediaURLTTL)).Unix()
+	query := u.Query()
+	query.Set("uid", strconv.FormatUint(uint64(userID), 10))
+	query.Set("exp", strconv.FormatInt(expires, 10))
+	query.Set("sig", mediaSignature(u.Path, userID, expires))
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// VerifyMediaSignature memeriksa tanda tangan URL media tanpa akses database.
+// Mengembalikan ID pengguna yang terikat pada URL dan waktu kedaluwarsanya.
+func VerifyMediaSignature(path, uid, exp, sig string, now time.Time) (uint, time.Time, bool) {
+	userID, err := strconv.ParseUint(uid, 10, 64)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	expires, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || now.Unix() > expires {
+		return 0, time.Time{}, false
+	}
+	expected := mediaSignature(path, uint(userID), expires)
+	if !hmac.Equal([]byte(expected), []byte(sig)) {
+		return 0, time.Time{}, false
+	}
+	return uint(userID), time.Unix(expires, 0), true
+}
+
+// NormalizeMediaURL mengubah URL media absolut atau bertanda tangan menjadi path
+// relatif seperti "/media/chat/abc.png" sebagaimana dicatat di tabel Upload.
+func NormalizeMediaURL(fileURL string) string {
+	u, err := url.Parse(strings.TrimSpace(fileURL))
+	if err != nil || u.Path == "" {
+		return fileURL
+	}
+	return path.Clean("/" + u.Path)
+}
+
+func mediaSignature(path string, userID uint, expires int64) string {
+	mac := hmac.New(sha256.New, mediaURLSecret())
+	mac.Write([]byte(path + "\n" + strconv.FormatUint(uint64(userID), 10) + "\n" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func isProtectedMediaPath(path string) bool {
+	for _, prefix := range protectedMediaPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/go-playground/validator/v10"
 
 	"portolio-backend/configs/constants"
+	"portolio-backend/internal/i18n"
 	"portolio-backend/internal/model/dto"
 )
 
@@ -64,6 +65,7 @@ var modelExamples = map[string]interface{}{
 		Email:       "jane.doe@example.com",
 		OldPassword: "Password123!",
 		NewPassword: "NewPassword123!",
+		Language:    "en",
 	},
 	"RequestPostProduct": dto.RequestPostProduct{
 		Title:      "Gaming Keyboard RGB",
@@ -87,10 +89,36 @@ var modelExamples = map[string]interface{}{
 		},
 		IsActive: func(b bool) *bool { return &b }(true),
 	},
+	"RequestCreatePriceSchedule": dto.RequestCreatePriceSchedule{
+		Price:   650000,
+		StartAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndAt:   func(t time.Time) *time.Time { return &t }(time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC)),
+	},
 	"RequestPurchaseItem": []dto.RequestPurchaseItem{ // Contoh untuk slice
-		{ProductID: 1, Quantity: 1},
+		{ProductID: 1, Quantity: 1, CouponCode: "HEMAT10"},
 		{ProductID: 2, Quantity: 2},
 	},
+	"RequestAdjustStock": dto.RequestAdjustStock{
+		Change: -2,
+		Note:   "Barang rusak saat pengecekan gudang",
+	},
+	"RequestReserveItem": []dto.RequestReserveItem{
+		{ProductID: 1, Quantity: 1},
+	},
+	"RequestAddWishlist": dto.RequestAddWishlist{
+		ProductID: 1,
+	},
+	"RequestCreateCoupon": dto.RequestCreateCoupon{
+		Code:          "HEMAT10",
+		Description:   "Diskon 10% untuk produk elektronik",
+		DiscountType:  constants.CouponDiscountPercentage,
+		DiscountValue: 10,
+		MaxDiscount:   50000,
+		MinSpend:      100000,
+		UsageLimit:    100,
+		PerUserLimit:  1,
+		Categories:    "Electronics",
+	},
 	"RequestCreateTicket": dto.RequestCreateTicket{
 		Subject: "Problem with recent purchase",
 		Message: "My order #123 has not arrived yet.",
@@ -134,7 +162,18 @@ var modelExamples = map[string]interface{}{
 	},
 }
 
+// RequestLanguage menentukan bahasa respons: pengaturan bahasa di profil pengguna yang
+// dipasang middleware Authorize, lalu header Accept-Language, lalu bahasa bawaan
+func RequestLanguage(c *gin.Context) constants.Language {
+	preferred, _ := c.Get("LANGUAGE")
+	lang, _ := preferred.(constants.Language)
+	return i18n.Resolve(lang, c.GetHeader("Accept-Language"))
+}
+
+// RespondJSON mengirim respons sukses atau error dengan format standar. Pesan dari
+// katalog, baik konstanta pesan maupun i18n.Message, diterjemahkan ke RequestLanguage.
 func RespondJSON(c *gin.Context, statusCode int, message any) {
+	lang := RequestLanguage(c)
 	if statusCode >= 400 {
 		errorResponse := ErrorResponse{
 			Status:    "error",
@@ -161,7 +200,7 @@ func RespondJSON(c *gin.Context, statusCode int, message any) {
 				fieldName := e.Field()
 				errorResponse.Fields = append(errorResponse.Fields, FieldErrorResponse{
 					Field:  fieldName,
-					Reason: validationMessage(e),
+					Reason: validationMessage(lang, e),
 				})
 			}
 			// Tambahkan example_request jika ada
@@ -173,7 +212,7 @@ func RespondJSON(c *gin.Context, statusCode int, message any) {
 			errorResponse.Message = constants.ErrMsgJSONTypeMismatch
 			errorResponse.Fields = append(errorResponse.Fields, FieldErrorResponse{
 				Field:  err.Field,
-				Reason: fmt.Sprintf("Expected type %s but got %s for field '%s'", err.Type.String(), err.Value, err.Field),
+				Reason: i18n.T(lang, "error.json_type_expected", i18n.Params{"expected": err.Type.String(), "received": err.Value, "field": err.Field}),
 			})
 			// Try to generate example based on the expected type
 			if err.Type.Kind() == reflect.Struct {
@@ -194,6 +233,13 @@ func RespondJSON(c *gin.Context, statusCode int, message any) {
 				errorResponse.ExampleRequest = map[string]any{} // Empty JSON for EOF
 			}
 
+		case i18n.Message:
+			errorResponse.Message = err.Render(lang)
+			errorResponse.Fields = append(errorResponse.Fields, FieldErrorResponse{
+				Field:  "General",
+				Reason: errorResponse.Message,
+			})
+
 		case error:
 			// Generic error, try to extract more specific message
 			errMsg := err.Error()
@@ -207,18 +253,18 @@ func RespondJSON(c *gin.Context, statusCode int, message any) {
 				// Attempt to provide a generic example if possible
 				errorResponse.ExampleRequest = map[string]any{} // Cannot infer specific field
 			} else {
-				errorResponse.Message = errMsg // Use the error's message directly
+				errorResponse.Message = i18n.Translate(lang, errMsg) // Use the error's message directly
 				errorResponse.Fields = append(errorResponse.Fields, FieldErrorResponse{
 					Field:  "General",
-					Reason: errMsg,
+					Reason: errorResponse.Message,
 				})
 			}
 
 		case string: // Jika pesan error adalah string biasa
-			errorResponse.Message = message.(string)
+			errorResponse.Message = i18n.Translate(lang, err)
 			errorResponse.Fields = append(errorResponse.Fields, FieldErrorResponse{
 				Field:  "General",
-				Reason: message.(string),
+				Reason: errorResponse.Message,
 			})
 
 		case map[string]interface{}: // Jika pesan error adalah map (misal dari custom validation)
@@ -231,12 +277,13 @@ func RespondJSON(c *gin.Context, statusCode int, message any) {
 			}
 
 		default:
-			errorResponse.Message = fmt.Sprintf("An unexpected error occurred: %v", message)
+			errorResponse.Message = i18n.T(lang, "error.unexpected", i18n.Params{"error": fmt.Sprintf("%v", message)})
 			errorResponse.Fields = append(errorResponse.Fields, FieldErrorResponse{
 				Field:  "Unknown",
 				Reason: fmt.Sprintf("%v", message),
 			})
 		}
+		errorResponse.Message = i18n.Translate(lang, errorResponse.Message)
 		c.JSON(statusCode, errorResponse)
 		c.Abort()
 		return
@@ -246,17 +293,23 @@ func RespondJSON(c *gin.Context, statusCode int, message any) {
 	successResponse := SuccessResponse{
 		Status:    "success",
 		Code:      statusCode,
-		Message:   "Operasi berhasil.", // Default message, akan di-override
+		Message:   i18n.T(lang, "success.default", nil), // Default message, akan di-override
 		Timestamp: time.Now().Format(time.RFC3339),
 	}
 
 	switch msg := message.(type) {
 	case string:
 		successResponse.Message = msg
+	case i18n.Message:
+		successResponse.Message = msg.Render(lang)
 	case gin.H: // Jika handler mengirim gin.H, asumsikan itu data dan mungkin ada "message" di dalamnya
-		if m, ok := msg["message"].(string); ok {
+		switch m := msg["message"].(type) {
+		case string:
 			successResponse.Message = m
 			delete(msg, "message") // Hapus message dari data agar tidak duplikat
+		case i18n.Message:
+			successResponse.Message = m.Render(lang)
+			delete(msg, "message")
 		}
 		successResponse.Data = msg
 	default: // Jika struct atau tipe data lain
@@ -270,48 +323,37 @@ func RespondJSON(c *gin.Context, statusCode int, message any) {
 		}
 	}
 
+	successResponse.Message = i18n.Translate(lang, successResponse.Message)
 	c.IndentedJSON(statusCode, successResponse)
 }
 
-func validationMessage(fe validator.FieldError) string {
-	switch fe.Tag() {
-	case "required":
-		return fe.Field() + " wajib diisi."
-	case "email":
-		return fe.Field() + " harus berupa alamat email yang valid."
-	case "min":
-		return fe.Field() + " minimal " + fe.Param() + " karakter."
-	case "max":
-		return fe.Field() + " maksimal " + fe.Param() + " karakter."
-	case "gte":
-		return fe.Field() + " harus lebih besar atau sama dengan " + fe.Param() + "."
-	case "lte":
-		return fe.Field() + " harus lebih kecil atau sama dengan " + fe.Param() + "."
-	case "gt":
-		return fe.Field() + " harus lebih besar dari " + fe.Param() + "."
-	case "url":
-		return fe.Field() + " harus berupa URL yang valid."
-	case "alpha":
-		return fe.Field() + " hanya boleh mengandung huruf."
-	case "alphanum":
-		return fe.Field() + " hanya boleh mengandung huruf dan angka."
-	case "numeric":
-		return fe.Field() + " hanya boleh mengandung angka."
-	case "oneof":
-		return fe.Field() + " harus salah satu dari: " + strings.ReplaceAll(fe.Param(), " ", ", ") + "."
-	case "uuid":
-		return fe.Field() + " harus berupa UUID yang valid."
-	case "len":
-		return fe.Field() + " harus memiliki panjang " + fe.Param() + " karakter."
-	case "eqfield":
-		return fe.Field() + " harus sama dengan " + fe.Param() + "."
-	case "required_if":
-		return fe.Field() + " wajib diisi jika " + fe.Param() + "."
-	case "required_without":
-		return fe.Field() + " wajib diisi jika " + fe.Param() + " tidak ada."
-	default:
-		return fe.Field() + " tidak valid."
+// ValidationFieldErrors mengubah error validasi menjadi daftar field dan alasannya
+func ValidationFieldErrors(err error) []FieldErrorResponse {
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return []FieldErrorResponse{{Field: "General", Reason: err.Error()}}
+	}
+
+	fields := make([]FieldErrorResponse, 0, len(validationErrors))
+	for _, e := range validationErrors {
+		fields = append(fields, FieldErrorResponse{
+			Field:  e.Field(),
+			Reason: validationMessage(constants.DefaultLanguage, e),
+		})
+	}
+	return fields
+}
+
+func validationMessage(lang constants.Language, fe validator.FieldError) string {
+	key := "validation." + fe.Tag()
+	if !i18n.Has(key) {
+		key = "validation.invalid"
+	}
+	param := fe.Param()
+	if fe.Tag() == "oneof" {
+		param = strings.ReplaceAll(param, " ", ", ")
 	}
+	return i18n.T(lang, key, i18n.Params{"field": fe.Field(), "param": param})
 }
 
 func parseUnmarshalError(errMsg string) (expected string, received string) {
@@ -99,6 +99,8 @@ func (h *Hub) Run() {
 	}
 }
 
+// SendNotificationToUser mengirim notifikasi ke koneksi WebSocket pengguna. Handler tidak
+// memanggilnya langsung, melainkan melalui notify.Send agar push menunggu commit.
 func SendNotificationToUser(userID uint, notification dto.NotificationResponse) {
 	jsonMsg, err := json.Marshal(map[string]interface{}{
 		"type":    "notification",
@@ -112,6 +114,17 @@ func SendNotificationToUser(userID uint, notification dto.NotificationResponse)
 	SendToUser(userID, jsonMsg)
 }
 
+// IsUserOnline memeriksa apakah pengguna memiliki koneksi WebSocket aktif di instance ini
+func IsUserOnline(userID uint) bool {
+	if WebsocketHub == nil {
+		return false
+	}
+
+	WebsocketHub.mu.RLock()
+	defer WebsocketHub.mu.RUnlock()
+	return len(WebsocketHub.clients[userID]) > 0
+}
+
 func SendToUser(userID uint, message []byte) {
 	if WebsocketHub == nil {
 		log.Println("WebsocketHub is not initialized.")
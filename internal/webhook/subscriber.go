@@ -0,0 +1,73 @@
+package webhook
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/events"
+	"portolio-backend/internal/model/db"
+)
+
+// SubscriberName adalah nama subscriber webhook di outbox event
+const SubscriberName = "webhook"
+
+// Subscribe mendaftarkan subscriber yang mengantrekan WebhookDelivery untuk setiap
+// webhook aktif milik penjual yang melanggan event tersebut. Pengiriman HTTP dilakukan
+// oleh jobs.RunWebhookDispatcher, sehingga penerima yang lambat tidak menahan relay event.
+func Subscribe(dbConn *gorm.DB) {
+	events.Subscribe(SubscriberName, func(ctx context.Context, event events.Event) error {
+		return Enqueue(dbConn.WithContext(ctx), event)
+	}, constants.WebhookEventTypes...)
+}
+
+// Enqueue membuat WebhookDelivery untuk event. Aman dijalankan ulang karena satu event
+// hanya dicatat sekali per webhook.
+func Enqueue(tx *gorm.DB, event events.Event) error {
+	sellerID := events.PayloadUint(event.Payload, "seller_id")
+	if sellerID == 0 {
+		return nil
+	}
+
+	var hooks []db.Webhook
+	if err := tx.Where("user_id = ? AND is_active = ?", sellerID, true).Find(&hooks).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var deliveries []db.WebhookDelivery
+	for _, hook := range hooks {
+		if !slices.Contains(EventTypes(hook), event.Type) {
+			continue
+		}
+		eventID := event.ID
+		deliveries = append(deliveries, db.WebhookDelivery{
+			WebhookID:     hook.ID,
+			EventID:       &eventID,
+			EventType:     event.Type,
+			Payload:       event.Payload,
+			Status:        constants.WebhookDeliveryPending,
+			NextAttemptAt: now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error
+}
+
+// EventTypes mengurai tipe event yang dilanggan webhook
+func EventTypes(hook db.Webhook) []constants.EventType {
+	var eventTypes []constants.EventType
+	for _, eventType := range strings.Split(hook.EventTypes, ",") {
+		if eventType = strings.TrimSpace(eventType); eventType != "" {
+			eventTypes = append(eventTypes, constants.EventType(eventType))
+		}
+	}
+	return eventTypes
+}
@@ -0,0 +1,234 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+	"portolio-backend/internal/util"
+)
+
+// Header yang dikirim bersama setiap payload webhook
+const (
+	HeaderEvent     = "X-Portolio-Event"
+	HeaderDelivery  = "X-Portolio-Delivery"
+	HeaderTimestamp = "X-Portolio-Timestamp"
+	HeaderSignature = "X-Portolio-Signature"
+)
+
+// maxResponseBody membatasi isi respons penerima yang disimpan di riwayat pengiriman
+const maxResponseBody = 2048
+
+var errBlockedAddress = errors.New(constants.ErrMsgInvalidWebhookURL)
+
+// Config berisi pengaturan pengiriman webhook, dibaca dari env di main
+type Config struct {
+	Timeout time.Duration
+	// AllowPrivate mengizinkan URL ke loopback dan jaringan privat, hanya untuk
+	// pengembangan dan pengujian dengan penerima lokal
+	AllowPrivate bool
+}
+
+// Client mengirim payload webhook ke URL milik pengguna
+type Client struct {
+	http         *http.Client
+	allowPrivate bool
+}
+
+// Result adalah hasil satu percobaan pengiriman
+type Result struct {
+	StatusCode int
+	Body       string
+	Duration   time.Duration
+}
+
+// DefaultClient dipakai job pengiriman dan uji coba webhook. Diganti dari main sesuai env.
+var DefaultClient = NewClient(Config{})
+
+// NewClient membuat Client. Alamat IP diperiksa saat koneksi dibuat sehingga nama host
+// yang mengarah ke jaringan internal tetap ditolak, dan redirect tidak diikuti.
+func NewClient(cfg Config) *Client {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = constants.DefaultWebhookTimeout
+	}
+
+	return &Client{
+		allowPrivate: cfg.AllowPrivate,
+		http: &http.Client{
+			Timeout: cfg.Timeout,
+			Transport: &http.Transport{
+				Proxy:                 nil,
+				DialContext:           util.SafeDialContext(errBlockedAddress, cfg.AllowPrivate),
+				TLSHandshakeTimeout:   5 * time.Second,
+				ResponseHeaderTimeout: cfg.Timeout,
+				MaxIdleConns:          10,
+				IdleConnTimeout:       30 * time.Second,
+			},
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// ValidateURL memeriksa URL webhook sebelum disimpan: harus http/https tanpa kredensial
+// dan tidak mengarah ke jaringan internal
+func (c *Client) ValidateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.User != nil {
+		return fmt.Errorf(constants.ErrMsgInvalidWebhookURL)
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "" {
+		return fmt.Errorf(constants.ErrMsgInvalidWebhookURL)
+	}
+	if c.allowPrivate {
+		return nil
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf(constants.ErrMsgInvalidWebhookURL)
+	}
+	if ip, err := netip.ParseAddr(host); err == nil && util.IsBlockedIP(ip) {
+		return fmt.Errorf(constants.ErrMsgInvalidWebhookURL)
+	}
+	return nil
+}
+
+// Send mengirim delivery ke hook. Status selain 2xx dikembalikan sebagai error beserta
+// Result agar kode dan isi respons tetap dapat dicatat.
+func (c *Client) Send(ctx context.Context, hook db.Webhook, delivery db.WebhookDelivery) (Result, error) {
+	var result Result
+	body, err := Body(delivery)
+	if err != nil {
+		return result, err
+	}
+	if err := c.ValidateURL(hook.URL); err != nil {
+		return result, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return result, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Portolio-Webhook/1.0")
+	req.Header.Set(HeaderEvent, string(delivery.EventType))
+	req.Header.Set(HeaderDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, "sha256="+Sign(hook.Secret, timestamp, body))
+
+	start := time.Now()
+	resp, err := c.http.Do(req)
+	result.Duration = time.Since(start)
+	if err != nil {
+		return result, err
+	}
+	defer resp.Body.Close()
+
+	responseBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	result.StatusCode = resp.StatusCode
+	result.Body = string(responseBody)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return result, fmt.Errorf("penerima membalas HTTP %d", resp.StatusCode)
+	}
+	return result, nil
+}
+
+// Body menyusun payload JSON yang dikirim ke penerima
+func Body(delivery db.WebhookDelivery) ([]byte, error) {
+	payload := map[string]interface{}{
+		"id":         delivery.ID,
+		"event":      delivery.EventType,
+		"created_at": delivery.CreatedAt.UTC().Format(time.RFC3339),
+		"data":       delivery.Payload,
+	}
+	if delivery.EventID != nil {
+		payload["event_id"] = *delivery.EventID
+	}
+	return json.Marshal(payload)
+}
+
+// Sign menghitung tanda tangan HMAC-SHA256 (hex) atas "<timestamp>.<body>". Penerima
+// memverifikasi header X-Portolio-Signature dengan perhitungan yang sama dan menolak
+// timestamp yang terlalu lama untuk mencegah replay.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify memeriksa tanda tangan dari header X-Portolio-Signature
+func Verify(secret, timestamp string, body []byte, signature string) bool {
+	expected := "sha256=" + Sign(secret, timestamp, body)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// GenerateSecret membuat secret acak untuk webhook baru
+func GenerateSecret() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
+
+// Attempt mengirim delivery satu kali lalu mencatat kode respons, isi respons, durasi,
+// dan status berikutnya. Bila gagal, delivery dijadwalkan ulang setelah retryDelay kecuali
+// final bernilai true. delivery.Attempts harus sudah memuat percobaan ini.
+func Attempt(ctx context.Context, dbConn *gorm.DB, client *Client, hook db.Webhook, delivery db.WebhookDelivery, retryDelay time.Duration, final bool) (db.WebhookDelivery, error) {
+	result, err := client.Send(ctx, hook, delivery)
+
+	delivery.ResponseCode = result.StatusCode
+	delivery.ResponseBody = result.Body
+	delivery.DurationMs = result.Duration.Milliseconds()
+	switch {
+	case err == nil:
+		deliveredAt := time.Now()
+		delivery.Status = constants.WebhookDeliverySuccess
+		delivery.DeliveredAt = &deliveredAt
+		delivery.LastError = ""
+	case final:
+		delivery.Status = constants.WebhookDeliveryFailed
+		delivery.LastError = err.Error()
+		log.Printf("❌ Webhook #%d (%s) gagal dikirim ke %s setelah %d percobaan: %v", delivery.ID, delivery.EventType, hook.URL, delivery.Attempts, err)
+	default:
+		delivery.Status = constants.WebhookDeliveryPending
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = time.Now().Add(retryDelay)
+	}
+
+	if updateErr := dbConn.Model(&db.WebhookDelivery{}).Where("id = ?", delivery.ID).Updates(map[string]interface{}{
+		"status":          delivery.Status,
+		"attempts":        delivery.Attempts,
+		"next_attempt_at": delivery.NextAttemptAt,
+		"response_code":   delivery.ResponseCode,
+		"response_body":   delivery.ResponseBody,
+		"duration_ms":     delivery.DurationMs,
+		"last_error":      delivery.LastError,
+		"delivered_at":    delivery.DeliveredAt,
+	}).Error; updateErr != nil {
+		log.Printf("❌ Gagal memperbarui status pengiriman webhook #%d: %v", delivery.ID, updateErr)
+	}
+	return delivery, err
+}
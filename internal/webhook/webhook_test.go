@@ -0,0 +1,129 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/model/db"
+)
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+func newReceiver(t *testing.T, status int, received chan<- receivedRequest) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- receivedRequest{header: r.Header.Clone(), body: body}
+		w.WriteHeader(status)
+		io.WriteString(w, `{"ok":`+strconv.FormatBool(status < 300)+`}`)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func testDelivery() db.WebhookDelivery {
+	eventID := uint(120)
+	delivery := db.WebhookDelivery{
+		WebhookID: 3,
+		EventID:   &eventID,
+		EventType: constants.EventPurchaseCreated,
+		Payload:   db.JSONB{"transaction_id": 123, "seller_id": 2},
+	}
+	delivery.ID = 15
+	delivery.CreatedAt = time.Date(2023, 10, 27, 10, 0, 0, 0, time.UTC)
+	return delivery
+}
+
+func TestSendSignsTimestampAndBody(t *testing.T) {
+	received := make(chan receivedRequest, 1)
+	server := newReceiver(t, http.StatusOK, received)
+	client := NewClient(Config{AllowPrivate: true})
+	hook := db.Webhook{URL: server.URL + "/hooks", Secret: "whsec_test"}
+
+	result, err := client.Send(context.Background(), hook, testDelivery())
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if result.StatusCode != http.StatusOK || result.Body != `{"ok":true}` {
+		t.Fatalf("Result = %+v", result)
+	}
+
+	req := <-received
+	timestamp := req.header.Get(HeaderTimestamp)
+	if ts, err := strconv.ParseInt(timestamp, 10, 64); err != nil || time.Since(time.Unix(ts, 0)) > time.Minute {
+		t.Fatalf("%s = %q", HeaderTimestamp, timestamp)
+	}
+
+	// Tanda tangan dihitung ulang seperti yang dilakukan penerima
+	mac := hmac.New(sha256.New, []byte("whsec_test"))
+	mac.Write([]byte(timestamp + "." + string(req.body)))
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := req.header.Get(HeaderSignature); got != want {
+		t.Fatalf("%s = %q, ingin %q", HeaderSignature, got, want)
+	}
+	if !Verify("whsec_test", timestamp, req.body, want) || Verify("whsec_lain", timestamp, req.body, want) {
+		t.Fatal("Verify tidak sesuai dengan tanda tangan yang dikirim")
+	}
+
+	if req.header.Get(HeaderEvent) != string(constants.EventPurchaseCreated) || req.header.Get(HeaderDelivery) != "15" {
+		t.Fatalf("header event/delivery = %q/%q", req.header.Get(HeaderEvent), req.header.Get(HeaderDelivery))
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload["id"] != float64(15) || payload["event_id"] != float64(120) || payload["created_at"] != "2023-10-27T10:00:00Z" {
+		t.Fatalf("payload = %v", payload)
+	}
+}
+
+func TestSendReportsNon2xxAndTimeout(t *testing.T) {
+	received := make(chan receivedRequest, 1)
+	server := newReceiver(t, http.StatusServiceUnavailable, received)
+	client := NewClient(Config{AllowPrivate: true})
+
+	result, err := client.Send(context.Background(), db.Webhook{URL: server.URL, Secret: "s"}, testDelivery())
+	if err == nil || result.StatusCode != http.StatusServiceUnavailable || result.Body != `{"ok":false}` {
+		t.Fatalf("Send 503: result = %+v, err = %v", result, err)
+	}
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer slow.Close()
+	client = NewClient(Config{AllowPrivate: true, Timeout: 50 * time.Millisecond})
+	result, err = client.Send(context.Background(), db.Webhook{URL: slow.URL, Secret: "s"}, testDelivery())
+	if err == nil || result.StatusCode != 0 {
+		t.Fatalf("Send lambat: result = %+v, err = %v", result, err)
+	}
+}
+
+func TestSendRejectsPrivateAddress(t *testing.T) {
+	received := make(chan receivedRequest, 1)
+	server := newReceiver(t, http.StatusOK, received)
+	client := NewClient(Config{})
+
+	if _, err := client.Send(context.Background(), db.Webhook{URL: server.URL, Secret: "s"}, testDelivery()); err == nil || !strings.Contains(err.Error(), constants.ErrMsgInvalidWebhookURL) {
+		t.Fatalf("err = %v, ingin URL ditolak", err)
+	}
+	if len(received) != 0 {
+		t.Fatal("penerima di loopback tetap dihubungi")
+	}
+}
@@ -0,0 +1,328 @@
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"portolio-backend/configs/constants"
+	"portolio-backend/internal/util"
+)
+
+// recordSize adalah ukuran record aes128gcm; payload notifikasi selalu muat dalam satu record
+const recordSize = 4096
+
+// MaxPayloadSize adalah batas payload sebelum dienkripsi agar hasilnya tetap satu record
+const MaxPayloadSize = recordSize - 16 - 1 - 86
+
+// ErrSubscriptionGone dikembalikan bila layanan push membalas 404/410, artinya langganan
+// sudah kedaluwarsa atau dicabut dan harus dihapus
+var ErrSubscriptionGone = errors.New("langganan web push sudah tidak berlaku")
+
+var errBlockedAddress = errors.New(constants.ErrMsgInvalidPushSubscription)
+
+// Subscription adalah PushSubscription dari browser (endpoint beserta kunci p256dh dan
+// auth dalam base64url)
+type Subscription struct {
+	Endpoint string
+	P256dh   string
+	Auth     string
+}
+
+// Config berisi pengaturan Web Push, dibaca dari env di main
+type Config struct {
+	// VAPIDPublicKey adalah titik P-256 tanpa kompresi (65 byte) dan VAPIDPrivateKey
+	// adalah skalar 32 byte, keduanya base64url tanpa padding
+	VAPIDPublicKey  string
+	VAPIDPrivateKey string
+	// Subject adalah kontak pengirim untuk layanan push, biasanya mailto:
+	Subject string
+	TTL     time.Duration
+	Timeout time.Duration
+	// AllowPrivate mengizinkan endpoint di loopback dan jaringan privat, hanya untuk
+	// pengembangan dan pengujian dengan layanan push lokal
+	AllowPrivate bool
+}
+
+// Sender mengirim pesan Web Push yang ditandatangani VAPID
+type Sender struct {
+	publicKey    string
+	privateKey   *ecdsa.PrivateKey
+	subject      string
+	ttl          time.Duration
+	allowPrivate bool
+	http         *http.Client
+}
+
+// DefaultSender diisi dari main bila kunci VAPID dikonfigurasi; nil berarti Web Push nonaktif
+var DefaultSender *Sender
+
+// NewSender membuat Sender dari cfg. Mengembalikan error bila kunci VAPID tidak valid.
+func NewSender(cfg Config) (*Sender, error) {
+	privateKey, err := parsePrivateKey(cfg.VAPIDPrivateKey, cfg.VAPIDPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = constants.DefaultWebPushTTL
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = constants.DefaultWebPushTimeout
+	}
+
+	return &Sender{
+		publicKey:    cfg.VAPIDPublicKey,
+		privateKey:   privateKey,
+		subject:      cfg.Subject,
+		ttl:          cfg.TTL,
+		allowPrivate: cfg.AllowPrivate,
+		http: &http.Client{
+			Timeout: cfg.Timeout,
+			Transport: &http.Transport{
+				Proxy:               nil,
+				DialContext:         util.SafeDialContext(errBlockedAddress, cfg.AllowPrivate),
+				TLSHandshakeTimeout: 5 * time.Second,
+				MaxIdleConns:        20,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}, nil
+}
+
+// PublicKey mengembalikan kunci publik VAPID untuk applicationServerKey di browser
+func (s *Sender) PublicKey() string {
+	return s.publicKey
+}
+
+// Validate memeriksa langganan sebelum disimpan: endpoint https (http hanya bila
+// AllowPrivate), tidak mengarah ke jaringan internal, dan kunci berukuran benar
+func (s *Sender) Validate(sub Subscription) error {
+	u, err := url.Parse(sub.Endpoint)
+	if err != nil || u.User != nil || u.Hostname() == "" {
+		return fmt.Errorf(constants.ErrMsgInvalidPushSubscription)
+	}
+	if u.Scheme != "https" && !(s.allowPrivate && u.Scheme == "http") {
+		return fmt.Errorf(constants.ErrMsgInvalidPushSubscription)
+	}
+	if !s.allowPrivate {
+		host := strings.ToLower(u.Hostname())
+		if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+			return fmt.Errorf(constants.ErrMsgInvalidPushSubscription)
+		}
+		if ip, err := netip.ParseAddr(host); err == nil && util.IsBlockedIP(ip) {
+			return fmt.Errorf(constants.ErrMsgInvalidPushSubscription)
+		}
+	}
+	if key, err := decodeKey(sub.P256dh); err != nil || len(key) != 65 {
+		return fmt.Errorf(constants.ErrMsgInvalidPushSubscription)
+	}
+	if auth, err := decodeKey(sub.Auth); err != nil || len(auth) != 16 {
+		return fmt.Errorf(constants.ErrMsgInvalidPushSubscription)
+	}
+	return nil
+}
+
+// Send mengenkripsi payload untuk langganan dan mengirimkannya ke layanan push. Status
+// 404/410 dikembalikan sebagai ErrSubscriptionGone.
+func (s *Sender) Send(ctx context.Context, sub Subscription, payload []byte) (int, error) {
+	if err := s.Validate(sub); err != nil {
+		return 0, err
+	}
+	if len(payload) > MaxPayloadSize {
+		return 0, fmt.Errorf("payload web push terlalu besar (%d byte)", len(payload))
+	}
+
+	body, err := Encrypt(payload, sub.P256dh, sub.Auth)
+	if err != nil {
+		return 0, err
+	}
+	authorization, err := s.vapidAuthorization(sub.Endpoint)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", strconv.Itoa(int(s.ttl.Seconds())))
+	req.Header.Set("Urgency", "normal")
+	req.Header.Set("Authorization", authorization)
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return resp.StatusCode, ErrSubscriptionGone
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return resp.StatusCode, fmt.Errorf("layanan push membalas HTTP %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// vapidAuthorization membuat header Authorization VAPID (RFC 8292) berisi JWT ES256 untuk
+// origin endpoint
+func (s *Sender) vapidAuthorization(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	header, _ := json.Marshal(map[string]string{"typ": "JWT", "alg": "ES256"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": time.Now().Add(12 * time.Hour).Unix(),
+		"sub": s.subject,
+	})
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(unsigned))
+	r, sig, err := ecdsa.Sign(rand.Reader, s.privateKey, digest[:])
+	if err != nil {
+		return "", err
+	}
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	sig.FillBytes(signature[32:])
+
+	token := unsigned + "." + base64.RawURLEncoding.EncodeToString(signature)
+	return "vapid t=" + token + ", k=" + s.publicKey, nil
+}
+
+// Encrypt mengenkripsi payload dengan skema aes128gcm (RFC 8291) untuk kunci p256dh dan
+// secret auth milik browser
+func Encrypt(payload []byte, p256dh, auth string) ([]byte, error) {
+	uaPublicBytes, err := decodeKey(p256dh)
+	if err != nil {
+		return nil, err
+	}
+	authSecret, err := decodeKey(auth)
+	if err != nil {
+		return nil, err
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(uaPublicBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+	sharedSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	keyInfo := append(append([]byte("WebPush: info\x00"), uaPublicBytes...), asPublic...)
+	ikm, err := hkdf.Key(sha256.New, sharedSecret, authSecret, string(keyInfo), 32)
+	if err != nil {
+		return nil, err
+	}
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// 0x02 menandai record terakhir tanpa padding tambahan
+	plaintext := append(append([]byte{}, payload...), 0x02)
+
+	header := make([]byte, 0, 16+4+1+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+// GenerateVAPIDKeys membuat pasangan kunci VAPID baru dalam format base64url
+func GenerateVAPIDKeys() (publicKey, privateKey string, err error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()), base64.RawURLEncoding.EncodeToString(key.Bytes()), nil
+}
+
+// parsePrivateKey membaca kunci privat VAPID dan memastikan cocok dengan kunci publiknya
+func parsePrivateKey(privateKey, publicKey string) (*ecdsa.PrivateKey, error) {
+	raw, err := decodeKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("kunci privat VAPID tidak valid: %w", err)
+	}
+	key, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("kunci privat VAPID tidak valid: %w", err)
+	}
+	public := key.PublicKey().Bytes()
+	if base64.RawURLEncoding.EncodeToString(public) != strings.TrimRight(publicKey, "=") {
+		return nil, errors.New("kunci publik VAPID tidak cocok dengan kunci privat")
+	}
+
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(public[1:33]),
+			Y:     new(big.Int).SetBytes(public[33:]),
+		},
+		D: new(big.Int).SetBytes(raw),
+	}, nil
+}
+
+// decodeKey membaca kunci base64url dengan atau tanpa padding, termasuk base64 standar
+func decodeKey(value string) ([]byte, error) {
+	value = strings.TrimRight(value, "=")
+	value = strings.NewReplacer("+", "-", "/", "_").Replace(value)
+	return base64.RawURLEncoding.DecodeString(value)
+}
@@ -0,0 +1,283 @@
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// browser adalah sisi user agent: kunci privat p256dh dan secret auth yang dipakai untuk
+// membuka pesan
+type browser struct {
+	key  *ecdh.PrivateKey
+	auth []byte
+}
+
+func newBrowser(t *testing.T, endpoint string) (browser, Subscription) {
+	t.Helper()
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := make([]byte, 16)
+	rand.Read(auth)
+	return browser{key: key, auth: auth}, Subscription{
+		Endpoint: endpoint,
+		P256dh:   base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		Auth:     base64.RawURLEncoding.EncodeToString(auth),
+	}
+}
+
+// decrypt membuka body aes128gcm seperti yang dilakukan browser (RFC 8188 dan RFC 8291)
+func (b browser) decrypt(body []byte) ([]byte, error) {
+	if len(body) < 21 {
+		return nil, errors.New("header aes128gcm terpotong")
+	}
+	salt, rs, idLen := body[:16], binary.BigEndian.Uint32(body[16:20]), int(body[20])
+	if rs != recordSize || idLen != 65 || len(body) < 21+idLen {
+		return nil, errors.New("header aes128gcm tidak valid")
+	}
+	asPublicBytes, ciphertext := body[21:21+idLen], body[21+idLen:]
+
+	asPublic, err := ecdh.P256().NewPublicKey(asPublicBytes)
+	if err != nil {
+		return nil, err
+	}
+	sharedSecret, err := b.key.ECDH(asPublic)
+	if err != nil {
+		return nil, err
+	}
+	keyInfo := "WebPush: info\x00" + string(b.key.PublicKey().Bytes()) + string(asPublicBytes)
+	ikm, err := hkdf.Key(sha256.New, sharedSecret, b.auth, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Record terakhir diakhiri delimiter 0x02 lalu padding nol
+	plaintext = bytes.TrimRight(plaintext, "\x00")
+	if len(plaintext) == 0 || plaintext[len(plaintext)-1] != 0x02 {
+		return nil, errors.New("delimiter record terakhir tidak ditemukan")
+	}
+	return plaintext[:len(plaintext)-1], nil
+}
+
+// verifyVAPID memeriksa header Authorization "vapid t=<jwt>, k=<kunci publik>" untuk
+// audience yang diharapkan dan mengembalikan klaim JWT
+func verifyVAPID(authorization, audience string) (map[string]interface{}, error) {
+	params, ok := strings.CutPrefix(authorization, "vapid ")
+	if !ok {
+		return nil, errors.New("skema Authorization bukan vapid")
+	}
+	var token, key string
+	for _, part := range strings.Split(params, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch name {
+		case "t":
+			token = value
+		case "k":
+			key = value
+		}
+	}
+
+	publicBytes, err := base64.RawURLEncoding.DecodeString(key)
+	if err != nil || len(publicBytes) != 65 {
+		return nil, errors.New("kunci publik VAPID tidak valid")
+	}
+	publicKey := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(publicBytes[1:33]),
+		Y:     new(big.Int).SetBytes(publicBytes[33:]),
+	}
+
+	segments := strings.Split(token, ".")
+	if len(segments) != 3 {
+		return nil, errors.New("JWT tidak valid")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(segments[2])
+	if err != nil || len(signature) != 64 {
+		return nil, errors.New("tanda tangan JWT bukan ES256")
+	}
+	digest := sha256.Sum256([]byte(segments[0] + "." + segments[1]))
+	if !ecdsa.Verify(publicKey, digest[:], new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])) {
+		return nil, errors.New("tanda tangan JWT tidak cocok")
+	}
+
+	var header map[string]string
+	var claims map[string]interface{}
+	headerJSON, _ := base64.RawURLEncoding.DecodeString(segments[0])
+	claimsJSON, _ := base64.RawURLEncoding.DecodeString(segments[1])
+	if json.Unmarshal(headerJSON, &header) != nil || header["alg"] != "ES256" {
+		return nil, errors.New("header JWT tidak valid")
+	}
+	if json.Unmarshal(claimsJSON, &claims) != nil || claims["aud"] != audience {
+		return nil, errors.New("aud JWT tidak sesuai origin endpoint")
+	}
+	exp, _ := claims["exp"].(float64)
+	if remaining := time.Until(time.Unix(int64(exp), 0)); remaining <= 0 || remaining > 24*time.Hour {
+		return nil, errors.New("exp JWT harus dalam 24 jam ke depan")
+	}
+	return claims, nil
+}
+
+func newTestSender(t *testing.T) *Sender {
+	t.Helper()
+	publicKey, privateKey, err := GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender, err := NewSender(Config{
+		VAPIDPublicKey:  publicKey,
+		VAPIDPrivateKey: privateKey,
+		Subject:         "mailto:admin@portolio.test",
+		TTL:             time.Hour,
+		AllowPrivate:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sender
+}
+
+func TestSendEncryptsAndSignsForPushService(t *testing.T) {
+	type pushRequest struct {
+		header http.Header
+		body   []byte
+	}
+	received := make(chan pushRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- pushRequest{header: r.Header.Clone(), body: body}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	sender := newTestSender(t)
+	ua, sub := newBrowser(t, server.URL+"/push/abc123")
+	payload := []byte(`{"id":7,"type":"sale","title":"Penjualan","body":"Produk Anda terjual."}`)
+
+	status, err := sender.Send(context.Background(), sub, payload)
+	if err != nil || status != http.StatusCreated {
+		t.Fatalf("Send = %d, %v", status, err)
+	}
+	req := <-received
+
+	for name, want := range map[string]string{
+		"Content-Encoding": "aes128gcm",
+		"Content-Type":     "application/octet-stream",
+		"TTL":              "3600",
+		"Urgency":          "normal",
+	} {
+		if got := req.header.Get(name); got != want {
+			t.Errorf("header %s = %q, ingin %q", name, got, want)
+		}
+	}
+
+	claims, err := verifyVAPID(req.header.Get("Authorization"), server.URL)
+	if err != nil {
+		t.Fatalf("Authorization %q: %v", req.header.Get("Authorization"), err)
+	}
+	if claims["sub"] != "mailto:admin@portolio.test" {
+		t.Errorf("sub JWT = %v", claims["sub"])
+	}
+	if !strings.HasSuffix(req.header.Get("Authorization"), ", k="+sender.PublicKey()) {
+		t.Errorf("Authorization tidak memuat kunci publik VAPID")
+	}
+
+	plaintext, err := ua.decrypt(req.body)
+	if err != nil {
+		t.Fatalf("membuka body: %v", err)
+	}
+	if !bytes.Equal(plaintext, payload) {
+		t.Fatalf("payload = %s, ingin %s", plaintext, payload)
+	}
+
+	// Browser lain tidak dapat membuka pesan yang sama
+	other, _ := newBrowser(t, sub.Endpoint)
+	if _, err := other.decrypt(req.body); err == nil {
+		t.Fatal("pesan terbuka dengan kunci browser lain")
+	}
+}
+
+func TestSendReportsGoneSubscriptions(t *testing.T) {
+	tests := []struct {
+		status   int
+		wantGone bool
+	}{
+		{http.StatusNotFound, true},
+		{http.StatusGone, true},
+		{http.StatusTooManyRequests, false},
+		{http.StatusInternalServerError, false},
+	}
+	sender := newTestSender(t)
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+		}))
+		_, sub := newBrowser(t, server.URL+"/push/abc123")
+
+		status, err := sender.Send(context.Background(), sub, []byte(`{}`))
+		server.Close()
+		if status != tt.status || err == nil || errors.Is(err, ErrSubscriptionGone) != tt.wantGone {
+			t.Errorf("HTTP %d: Send = %d, %v; ingin ErrSubscriptionGone = %v", tt.status, status, err, tt.wantGone)
+		}
+	}
+}
+
+func TestSendRejectsPrivateEndpoint(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	publicKey, privateKey, err := GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender, err := NewSender(Config{VAPIDPublicKey: publicKey, VAPIDPrivateKey: privateKey, Subject: "mailto:admin@portolio.test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, sub := newBrowser(t, server.URL+"/push/abc123")
+	if _, err := sender.Send(context.Background(), sub, []byte(`{}`)); err == nil || called {
+		t.Fatalf("endpoint loopback diterima: err = %v, dipanggil = %v", err, called)
+	}
+}